@@ -0,0 +1,30 @@
+// Command servicetoken prints a token that authenticates another backend
+// service to the gRPC API. It signs with JWT_SECRET, which must be set in the
+// environment (make service-token loads it from .env).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/middleware"
+)
+
+func main() {
+	service := flag.String("service", "", "name of the service the token is for, e.g. recommendations")
+	ttl := flag.Duration("ttl", 90*24*time.Hour, "how long the token is valid")
+	flag.Parse()
+
+	if os.Getenv("JWT_SECRET") == "" {
+		log.Fatal("JWT_SECRET is not set")
+	}
+
+	token, err := middleware.NewServiceToken(*service, *ttl)
+	if err != nil {
+		log.Fatalf("Failed to create token: %v", err)
+	}
+	fmt.Println(token)
+}
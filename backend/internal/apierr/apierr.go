@@ -0,0 +1,143 @@
+// Package apierr writes every API error in one envelope:
+//
+//	{"error": "Post not found", "code": "not_found", "request_id": "5f0c…"}
+//
+// error stays a human-readable message so existing clients keep working,
+// code is stable and meant for clients to branch on, and validation failures
+// add details with one entry per invalid field.
+package apierr
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDKey is the context key the request ID middleware stores the
+// request's ID under
+const RequestIDKey = "request_id"
+
+// Code is a stable, machine-readable error code
+type Code string
+
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidation       Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeBanned           Code = "banned"
+	CodeNotFound         Code = "not_found"
+	CodeConflict         Code = "conflict"
+	CodeRuleViolation    Code = "rule_violation"
+	CodePayloadTooLarge  Code = "payload_too_large"
+	CodeUnsupportedMedia Code = "unsupported_media_type"
+	CodeUpgradeRequired  Code = "upgrade_required"
+	CodeInternal         Code = "internal_error"
+)
+
+// FieldError describes one invalid request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Body is the shape of every error response
+type Body struct {
+	Error     string       `json:"error"`
+	Code      Code         `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Error is an API error with its HTTP status
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Details []FieldError
+	// Extra holds endpoint-specific context, such as the ban that blocked a
+	// request
+	Extra map[string]any
+}
+
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// WithDetails adds field-level details
+func (e *Error) WithDetails(details ...FieldError) *Error {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+// With adds an endpoint-specific key to the response
+func (e *Error) With(key string, value any) *Error {
+	if e.Extra == nil {
+		e.Extra = make(map[string]any)
+	}
+	e.Extra[key] = value
+	return e
+}
+
+// Write sends the error and stops the handler chain
+func Write(c *gin.Context, e *Error) {
+	body := gin.H{"error": e.Message, "code": e.Code}
+	if len(e.Details) > 0 {
+		body["details"] = e.Details
+	}
+	if id := c.GetString(RequestIDKey); id != "" {
+		body["request_id"] = id
+	}
+	for k, v := range e.Extra {
+		body[k] = v
+	}
+	c.AbortWithStatusJSON(e.Status, body)
+}
+
+// BadRequest reports a malformed or unacceptable request
+func BadRequest(c *gin.Context, message string) {
+	Write(c, New(http.StatusBadRequest, CodeBadRequest, message))
+}
+
+// Invalid reports a single invalid field
+func Invalid(c *gin.Context, field, message string) {
+	Write(c, New(http.StatusBadRequest, CodeValidation, message).WithDetails(FieldError{Field: field, Message: message}))
+}
+
+// Unauthorized reports a missing or invalid login
+func Unauthorized(c *gin.Context, message string) {
+	Write(c, New(http.StatusUnauthorized, CodeUnauthorized, message))
+}
+
+// Forbidden reports that the caller may not do this
+func Forbidden(c *gin.Context, message string) {
+	Write(c, New(http.StatusForbidden, CodeForbidden, message))
+}
+
+// NotFound reports a missing resource
+func NotFound(c *gin.Context, message string) {
+	Write(c, New(http.StatusNotFound, CodeNotFound, message))
+}
+
+// Conflict reports a request that clashes with existing state
+func Conflict(c *gin.Context, message string) {
+	Write(c, New(http.StatusConflict, CodeConflict, message))
+}
+
+// TooLarge reports a request body over the size limit
+func TooLarge(c *gin.Context, message string) {
+	Write(c, New(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, message))
+}
+
+// UnsupportedMedia reports an upload of a type the API doesn't accept
+func UnsupportedMedia(c *gin.Context, message string) {
+	Write(c, New(http.StatusUnsupportedMediaType, CodeUnsupportedMedia, message))
+}
+
+// Internal reports a server-side failure. The message should say what failed
+// without exposing why.
+func Internal(c *gin.Context, message string) {
+	Write(c, New(http.StatusInternalServerError, CodeInternal, message))
+}
@@ -0,0 +1,114 @@
+package apierr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Body {
+	t.Helper()
+	var body Body
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid error body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
+
+func TestWriteEnvelope(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(RequestIDKey, "req-12345678")
+
+	Write(c, New(http.StatusForbidden, CodeBanned, "You are banned").With("ban", map[string]int{"id": 3}))
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d", w.Code)
+	}
+	if !c.IsAborted() {
+		t.Error("Write should abort the handler chain")
+	}
+	body := decode(t, w)
+	if body.Error != "You are banned" || body.Code != CodeBanned || body.RequestID != "req-12345678" {
+		t.Errorf("unexpected body %+v", body)
+	}
+	if !strings.Contains(w.Body.String(), `"ban":{"id":3}`) {
+		t.Errorf("extra context missing from %s", w.Body.String())
+	}
+}
+
+type bindTarget struct {
+	Title    string   `json:"title" binding:"required"`
+	VoteType int      `json:"vote_type" binding:"required,oneof=-1 1"`
+	Tags     []string `json:"tags" binding:"max=2"`
+}
+
+func bind(t *testing.T, payload string) Body {
+	t.Helper()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var input bindTarget
+	err := c.ShouldBindJSON(&input)
+	if err == nil {
+		t.Fatalf("expected %s to fail binding", payload)
+	}
+	Bind(c, err)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d", w.Code)
+	}
+	body := decode(t, w)
+	if body.Code != CodeValidation {
+		t.Errorf("code = %q", body.Code)
+	}
+	return body
+}
+
+func TestBindValidationDetails(t *testing.T) {
+	body := bind(t, `{"vote_type": 3, "tags": ["a", "b", "c"]}`)
+
+	want := map[string]string{
+		"title":     "is required",
+		"vote_type": "must be one of -1, 1",
+		"tags":      "must have at most 2 items",
+	}
+	if len(body.Details) != len(want) {
+		t.Fatalf("details = %+v", body.Details)
+	}
+	for _, d := range body.Details {
+		if want[d.Field] != d.Message {
+			t.Errorf("%s: got %q, want %q", d.Field, d.Message, want[d.Field])
+		}
+	}
+	if body.Error != "title is required; vote_type must be one of -1, 1; tags must have at most 2 items" {
+		t.Errorf("message = %q", body.Error)
+	}
+}
+
+func TestBindHidesDecoderErrors(t *testing.T) {
+	cases := map[string]FieldError{
+		`{"title": 5, "vote_type": 1}`: {Field: "title", Message: "must be a string"},
+		`{"title": "x",`:               {Field: "body", Message: "must be valid JSON"},
+		``:                             {Field: "body", Message: "must be valid JSON"},
+	}
+	for payload, want := range cases {
+		body := bind(t, payload)
+		if len(body.Details) != 1 || body.Details[0] != want {
+			t.Errorf("%q: details = %+v, want %+v", payload, body.Details, want)
+		}
+		if strings.Contains(body.Error, "json:") || strings.Contains(body.Error, "Go struct") {
+			t.Errorf("%q: message leaks decoder internals: %q", payload, body.Error)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package apiversion mounts the API under versioned prefixes such as
+// /api/v1. Every version serves the same routes, registered once, except the
+// endpoints it overrides; a deprecated version announces its retirement with
+// Deprecation, Sunset and Link headers on every response.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is one version of the API
+type Version struct {
+	// Name is the path segment, e.g. "v1"
+	Name string
+	// Deprecated is when the version was deprecated; zero while it is current
+	Deprecated time.Time
+	// Sunset is when the version stops being served, if decided
+	Sunset time.Time
+	// Successor is the path clients should move to, e.g. "/api/v1"
+	Successor string
+
+	overrides map[string][]gin.HandlerFunc
+	used      map[string]bool
+}
+
+// Override makes this version serve a route with a different handler than
+// the one it is registered with. path is relative to the version root, as
+// passed to Routes, e.g. "/posts/:id". The route keeps its middleware.
+func (v *Version) Override(method, path string, handler gin.HandlerFunc) *Version {
+	if v.overrides == nil {
+		v.overrides = make(map[string][]gin.HandlerFunc)
+	}
+	v.overrides[method+" "+path] = []gin.HandlerFunc{handler}
+	return v
+}
+
+// Unused lists overrides that matched no registered route, which usually
+// means a typo in the path
+func (v *Version) Unused() []string {
+	var unused []string
+	for key := range v.overrides {
+		if !v.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// Headers announces deprecation on every response of the version: the
+// Deprecation header (RFC 9745) carries the deprecation date, Sunset
+// (RFC 8594) the retirement date, and Link the successor
+func (v *Version) Headers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !v.Deprecated.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", v.Deprecated.Unix()))
+			if v.Successor != "" {
+				c.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, v.Successor))
+			}
+		}
+		if !v.Sunset.IsZero() {
+			c.Header("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Next()
+	}
+}
+
+// Routes registers routes for one version. It mirrors the gin.RouterGroup
+// methods the server uses, substituting the version's overrides.
+type Routes struct {
+	group   *gin.RouterGroup
+	rel     string
+	version *Version
+}
+
+// Mount serves the version under prefix, e.g. "/v1", of the parent group
+func Mount(parent *gin.RouterGroup, prefix string, v *Version) *Routes {
+	if v.used == nil {
+		v.used = make(map[string]bool)
+	}
+	group := parent.Group(prefix)
+	group.Use(v.Headers())
+	return &Routes{group: group, rel: "/", version: v}
+}
+
+// Group creates a subgroup sharing the version
+func (r *Routes) Group(relativePath string) *Routes {
+	return &Routes{group: r.group.Group(relativePath), rel: path.Join(r.rel, relativePath), version: r.version}
+}
+
+// Use adds middleware to the group
+func (r *Routes) Use(middleware ...gin.HandlerFunc) {
+	r.group.Use(middleware...)
+}
+
+func (r *Routes) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodGet, relativePath, handlers)
+}
+
+func (r *Routes) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodPost, relativePath, handlers)
+}
+
+func (r *Routes) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodPut, relativePath, handlers)
+}
+
+func (r *Routes) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	r.handle(http.MethodDelete, relativePath, handlers)
+}
+
+func (r *Routes) handle(method, relativePath string, handlers []gin.HandlerFunc) {
+	key := method + " " + path.Join(r.rel, relativePath)
+	if override, ok := r.version.overrides[key]; ok {
+		// Keep route middleware such as the ban check; swap the final handler
+		handlers = append(handlers[:len(handlers)-1:len(handlers)-1], override...)
+		r.version.used[key] = true
+	}
+	r.group.Handle(method, relativePath, handlers...)
+}
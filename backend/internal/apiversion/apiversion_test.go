@@ -0,0 +1,98 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func register(api *Routes) {
+	tag := func(c *gin.Context) { c.Header("X-Middleware", "ran") }
+	api.GET("/posts", func(c *gin.Context) { c.String(http.StatusOK, "v1 posts") })
+	protected := api.Group("")
+	protected.Use(tag)
+	protected.GET("/posts/:id", tag, func(c *gin.Context) { c.String(http.StatusOK, "v1 post") })
+}
+
+func TestOverrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	v1 := &Version{Name: "v1"}
+	v2 := (&Version{Name: "v2"}).
+		Override(http.MethodGet, "/posts/:id", func(c *gin.Context) { c.String(http.StatusOK, "v2 post") })
+	for _, v := range []*Version{v1, v2} {
+		register(Mount(r.Group("/api"), "/"+v.Name, v))
+	}
+
+	tests := []struct{ path, want string }{
+		{"/api/v1/posts", "v1 posts"},
+		{"/api/v1/posts/1", "v1 post"},
+		{"/api/v2/posts", "v1 posts"},
+		{"/api/v2/posts/1", "v2 post"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Body.String() != tt.want {
+			t.Errorf("GET %s = %q, want %q", tt.path, w.Body.String(), tt.want)
+		}
+	}
+
+	// The override keeps the route's middleware
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/posts/1", nil))
+	if w.Header().Get("X-Middleware") != "ran" {
+		t.Error("override dropped the route middleware")
+	}
+
+	if unused := v2.Unused(); len(unused) != 0 {
+		t.Errorf("unused = %v", unused)
+	}
+}
+
+func TestUnusedOverride(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	v := (&Version{Name: "v2"}).Override(http.MethodGet, "/post", func(*gin.Context) {})
+	register(Mount(gin.New().Group("/api"), "/v2", v))
+
+	if unused := v.Unused(); len(unused) != 1 || unused[0] != "GET /post" {
+		t.Errorf("unused = %v", unused)
+	}
+}
+
+func TestDeprecationHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	old := &Version{
+		Name:       "v1",
+		Deprecated: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:     time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		Successor:  "/api/v2",
+	}
+	register(Mount(r.Group("/api"), "/v1", old))
+	register(Mount(r.Group("/api"), "/v2", &Version{Name: "v2"}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil))
+	want := map[string]string{
+		"Deprecation": "@1767225600",
+		"Sunset":      "Wed, 01 Jul 2026 00:00:00 GMT",
+		"Link":        `</api/v2>; rel="successor-version"`,
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/posts", nil))
+	for header := range want {
+		if got := w.Header().Get(header); got != "" {
+			t.Errorf("current version sent %s: %q", header, got)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package automod
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func author(ageDays, karma int) Content {
+	return Content{AuthorCreatedAt: now.Add(-time.Duration(ageDays) * 24 * time.Hour), AuthorKarma: karma}
+}
+
+func post(kind, title, body, url string) Content {
+	c := author(365, 1000)
+	c.Type, c.Kind, c.Title, c.Body, c.URL = TypePost, kind, title, body, url
+	return c
+}
+
+func TestDomains(t *testing.T) {
+	c := post("link", "see https://news.example.org/a", "and http://www.Foo.com/x, https://foo.com/y", "https://www.example.com/post")
+	want := []string{"example.com", "news.example.org", "foo.com"}
+	if got := c.Domains(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Domains() = %v, want %v", got, want)
+	}
+}
+
+func TestRequirements(t *testing.T) {
+	req := Requirements{
+		AllowedKinds:      []string{"text", "link"},
+		MinAccountAgeDays: 7,
+		MinKarma:          10,
+		TitlePattern:      `^\[(Question|Discussion)\]`,
+		BannedDomains:     []string{"spam.example"},
+		BannedKeywords:    []string{"crypto giveaway"},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	young := post("text", "[Question] hi", "", "")
+	young.AuthorCreatedAt = now.Add(-2 * 24 * time.Hour)
+	poor := post("text", "[Question] hi", "", "")
+	poor.AuthorKarma = 3
+	comment := author(365, 1000)
+	comment.Type, comment.Body = TypeComment, "Free CRYPTO Giveaway here"
+
+	cases := []struct {
+		name    string
+		content Content
+		rule    string
+	}{
+		{"ok", post("text", "[Question] How?", "body", ""), ""},
+		{"young account", young, "min_account_age"},
+		{"low karma", poor, "min_karma"},
+		{"kind", post("poll", "[Question] Poll", "", ""), "allowed_kinds"},
+		{"title", post("text", "How?", "", ""), "title_pattern"},
+		{"domain", post("link", "[Discussion] x", "", "https://cdn.spam.example/x"), "banned_domains"},
+		{"domain in body", post("text", "[Discussion] x", "go to http://spam.example", ""), "banned_domains"},
+		{"keyword in comment", comment, "banned_keywords"},
+	}
+	for _, tc := range cases {
+		v := req.Check(tc.content, now)
+		switch {
+		case tc.rule == "" && v != nil:
+			t.Errorf("%s: unexpected violation %q", tc.name, v.Rule)
+		case tc.rule != "" && (v == nil || v.Rule != tc.rule):
+			t.Errorf("%s: got %v, want rule %q", tc.name, v, tc.rule)
+		}
+	}
+}
+
+func TestRequirementsValidate(t *testing.T) {
+	if err := (Requirements{TitlePattern: "("}).Validate(); err == nil {
+		t.Error("invalid title pattern accepted")
+	}
+	if err := (Requirements{MinKarma: -1}).Validate(); err == nil {
+		t.Error("negative karma accepted")
+	}
+	if err := (Requirements{BannedKeywords: []string{"spam", " "}}).Validate(); err == nil {
+		t.Error("blank keyword accepted")
+	}
+}
+
+func TestBlankKeywordsMatchNothing(t *testing.T) {
+	if re := keywordPattern([]string{"", "  "}); re != nil {
+		t.Errorf("blank keywords compiled to %v", re)
+	}
+	re := keywordPattern([]string{"", "spam"})
+	if re.MatchString("a perfectly normal post") || !re.MatchString("buy SPAM now") {
+		t.Errorf("unexpected pattern %v", re)
+	}
+}
+
+const rulesYAML = `
+rules:
+  - name: new accounts posting links
+    if:
+      type: post
+      kinds: [link]
+      account_age_below_days: 3
+    action: require_approval
+    reason: Links from new accounts are reviewed first
+  - name: shorteners
+    if:
+      domains: [bit.ly]
+    action: remove
+    reason: Link shorteners are not allowed
+  - name: low karma self-promotion
+    if:
+      keywords: [subscribe, "my channel"]
+      karma_below: 50
+    action: flag
+`
+
+func TestParseYAMLAndJSON(t *testing.T) {
+	rs, err := Parse([]byte(rulesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Rules) != 3 || *rs.Rules[2].If.KarmaBelow != 50 {
+		t.Fatalf("unexpected rules: %+v", rs.Rules)
+	}
+
+	js := `{"rules": [{"name": "caps", "if": {"title_regex": "^[A-Z !]+$"}, "action": "flag"}]}`
+	if _, err := Parse([]byte(js)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	for _, src := range []string{
+		`rules: [{if: {}, action: flag}]`,
+		`rules: [{name: x, if: {}}]`,
+		`rules: [{name: x, if: {}, action: ban}]`,
+		`rules: [{name: x, if: {type: wiki}, action: flag}]`,
+		`rules: [{name: x, if: {title_regex: "("}, action: flag}]`,
+		`rules: [{name: x, if: {keywords: [spam, ""]}, action: remove}]`,
+		`rules: [{name: x, if: {keywords: ["  "]}, action: remove}]`,
+		`rules: {`,
+	} {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("Parse(%q) succeeded", src)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rs, err := Parse([]byte(rulesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newLink := post("link", "Look", "", "https://example.com")
+	newLink.AuthorCreatedAt = now.Add(-24 * time.Hour)
+
+	newShortLink := newLink
+	newShortLink.URL = "https://bit.ly/abc"
+
+	promo := author(365, 10)
+	promo.Type, promo.Body = TypeComment, "Please SUBSCRIBE to my channel"
+
+	cases := []struct {
+		name    string
+		content Content
+		action  Action
+		rules   []string
+	}{
+		{"clean", post("text", "Hello", "world", ""), ActionNone, nil},
+		{"new account link", newLink, ActionRequireApproval, []string{"new accounts posting links"}},
+		{"most severe wins", newShortLink, ActionRemove, []string{"new accounts posting links", "shorteners"}},
+		{"flag", promo, ActionFlag, []string{"low karma self-promotion"}},
+		{"karma above threshold", func() Content { c := promo; c.AuthorKarma = 50; return c }(), ActionNone, nil},
+	}
+	for _, tc := range cases {
+		d := rs.Evaluate(tc.content, now)
+		if d.Action != tc.action || !reflect.DeepEqual(d.Rules, tc.rules) {
+			t.Errorf("%s: got %+v, want %q %v", tc.name, d, tc.action, tc.rules)
+		}
+	}
+
+	if d := rs.Evaluate(newShortLink, now); d.Reason != "Link shorteners are not allowed" {
+		t.Errorf("reason = %q", d.Reason)
+	}
+	var none *RuleSet
+	if d := none.Evaluate(newLink, now); d.Action != ActionNone {
+		t.Error("nil rule set should not act")
+	}
+}
@@ -0,0 +1,97 @@
+// Package automod checks community posting requirements and evaluates
+// moderator-defined automoderation rules against new posts and comments.
+//
+// Everything here is pure: callers describe the content and its author in a
+// Content value, so rules can be tested without a database.
+package automod
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Content types
+const (
+	TypePost    = "post"
+	TypeComment = "comment"
+)
+
+// Content is a post or comment about to be created, with what rules may
+// need to know about its author
+type Content struct {
+	Type  string
+	Kind  string // post kind; empty for comments
+	Title string // empty for comments
+	Body  string
+	URL   string // link and video posts
+
+	AuthorCreatedAt time.Time
+	AuthorKarma     int
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+
+// Domains returns the lower-cased hosts of the content's URL and of links in
+// its title and body, without a leading "www."
+func (c Content) Domains() []string {
+	var domains []string
+	seen := make(map[string]bool)
+	add := func(raw string) {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		if !seen[host] {
+			seen[host] = true
+			domains = append(domains, host)
+		}
+	}
+
+	if c.URL != "" {
+		add(c.URL)
+	}
+	for _, link := range linkPattern.FindAllString(c.Title+"\n"+c.Body, -1) {
+		add(link)
+	}
+	return domains
+}
+
+// accountAgeDays is the author's account age in whole days
+func (c Content) accountAgeDays(now time.Time) int {
+	return int(now.Sub(c.AuthorCreatedAt) / (24 * time.Hour))
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains
+func matchesDomain(host, domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// keywordPattern matches any of the keywords as whole words, ignoring case.
+// Blank keywords are skipped; they would match everything.
+func keywordPattern(keywords []string) *regexp.Regexp {
+	var quoted []string
+	for _, k := range keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			quoted = append(quoted, regexp.QuoteMeta(k))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// checkKeywords rejects blank keywords
+func checkKeywords(keywords []string) error {
+	for i, k := range keywords {
+		if strings.TrimSpace(k) == "" {
+			return fmt.Errorf("keyword %d is blank", i+1)
+		}
+	}
+	return nil
+}
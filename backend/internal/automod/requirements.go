@@ -0,0 +1,79 @@
+package automod
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Requirements are a community's posting requirements. Content that fails
+// them is rejected before it is stored.
+type Requirements struct {
+	// AllowedKinds limits the post kinds; empty allows every kind
+	AllowedKinds      []string `json:"allowed_kinds,omitempty" yaml:"allowed_kinds"`
+	MinAccountAgeDays int      `json:"min_account_age_days,omitempty" yaml:"min_account_age_days"`
+	MinKarma          int      `json:"min_karma,omitempty" yaml:"min_karma"`
+	// TitlePattern is a regular expression post titles must match
+	TitlePattern   string   `json:"title_pattern,omitempty" yaml:"title_pattern"`
+	BannedDomains  []string `json:"banned_domains,omitempty" yaml:"banned_domains"`
+	BannedKeywords []string `json:"banned_keywords,omitempty" yaml:"banned_keywords"`
+}
+
+// Violation names the requirement content failed
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (v *Violation) Error() string { return v.Message }
+
+// Validate checks that the requirements are well-formed
+func (r Requirements) Validate() error {
+	if r.MinAccountAgeDays < 0 || r.MinKarma < 0 {
+		return fmt.Errorf("minimums cannot be negative")
+	}
+	if r.TitlePattern != "" {
+		if _, err := regexp.Compile(r.TitlePattern); err != nil {
+			return fmt.Errorf("title_pattern: %w", err)
+		}
+	}
+	if err := checkKeywords(r.BannedKeywords); err != nil {
+		return fmt.Errorf("banned_keywords: %w", err)
+	}
+	return nil
+}
+
+// Check returns the first requirement the content violates, or nil
+func (r Requirements) Check(c Content, now time.Time) *Violation {
+	if c.accountAgeDays(now) < r.MinAccountAgeDays {
+		return &Violation{"min_account_age", fmt.Sprintf("Your account must be at least %d days old to participate here", r.MinAccountAgeDays)}
+	}
+	if c.AuthorKarma < r.MinKarma {
+		return &Violation{"min_karma", fmt.Sprintf("You need at least %d karma to participate here", r.MinKarma)}
+	}
+
+	if c.Type == TypePost {
+		if len(r.AllowedKinds) > 0 && !slices.Contains(r.AllowedKinds, c.Kind) {
+			return &Violation{"allowed_kinds", fmt.Sprintf("%s posts are not allowed here", c.Kind)}
+		}
+		if r.TitlePattern != "" {
+			if re, err := regexp.Compile(r.TitlePattern); err == nil && !re.MatchString(c.Title) {
+				return &Violation{"title_pattern", "The title does not follow this community's format"}
+			}
+		}
+	}
+
+	for _, host := range c.Domains() {
+		for _, banned := range r.BannedDomains {
+			if matchesDomain(host, banned) {
+				return &Violation{"banned_domains", fmt.Sprintf("Links to %s are not allowed here", banned)}
+			}
+		}
+	}
+
+	if re := keywordPattern(r.BannedKeywords); re != nil && re.MatchString(c.Title+"\n"+c.Body) {
+		return &Violation{"banned_keywords", "This contains a word that is not allowed here"}
+	}
+	return nil
+}
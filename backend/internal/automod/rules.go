@@ -0,0 +1,163 @@
+package automod
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Action is what automod does with matching content
+type Action string
+
+const (
+	ActionNone            Action = ""
+	ActionFlag            Action = "flag"             // publish, but surface to moderators
+	ActionRequireApproval Action = "require_approval" // hide until a moderator approves
+	ActionRemove          Action = "remove"           // hide from everyone but the author
+)
+
+var severity = map[Action]int{
+	ActionNone:            0,
+	ActionFlag:            1,
+	ActionRequireApproval: 2,
+	ActionRemove:          3,
+}
+
+// Condition matches content when every field that is set matches
+type Condition struct {
+	// Type is "post" or "comment"; empty matches both
+	Type       string   `json:"type,omitempty" yaml:"type"`
+	Kinds      []string `json:"kinds,omitempty" yaml:"kinds"`
+	TitleRegex string   `json:"title_regex,omitempty" yaml:"title_regex"`
+	BodyRegex  string   `json:"body_regex,omitempty" yaml:"body_regex"`
+	// Keywords match whole words in the title or body, ignoring case
+	Keywords []string `json:"keywords,omitempty" yaml:"keywords"`
+	Domains  []string `json:"domains,omitempty" yaml:"domains"`
+	// AccountAgeBelowDays matches authors whose account is younger
+	AccountAgeBelowDays int  `json:"account_age_below_days,omitempty" yaml:"account_age_below_days"`
+	KarmaBelow          *int `json:"karma_below,omitempty" yaml:"karma_below"`
+
+	title, body, keywords *regexp.Regexp
+}
+
+// Rule is one automoderation rule
+type Rule struct {
+	Name   string    `json:"name" yaml:"name"`
+	If     Condition `json:"if" yaml:"if"`
+	Action Action    `json:"action" yaml:"action"`
+	// Reason is shown to moderators and to the author of removed content
+	Reason string `json:"reason,omitempty" yaml:"reason"`
+}
+
+// RuleSet is a community's automoderation configuration
+type RuleSet struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Parse reads a rule set written in YAML or JSON and validates it
+func Parse(src []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := yaml.Unmarshal(src, &rs); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	for i := range rs.Rules {
+		if err := rs.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &rs, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Action == ActionNone {
+		return fmt.Errorf("%s: action is required", r.Name)
+	}
+	if _, ok := severity[r.Action]; !ok {
+		return fmt.Errorf("%s: unknown action %q", r.Name, r.Action)
+	}
+
+	c := &r.If
+	if c.Type != "" && c.Type != TypePost && c.Type != TypeComment {
+		return fmt.Errorf("%s: type must be post or comment", r.Name)
+	}
+	var err error
+	if c.TitleRegex != "" {
+		if c.title, err = regexp.Compile(c.TitleRegex); err != nil {
+			return fmt.Errorf("%s: title_regex: %w", r.Name, err)
+		}
+	}
+	if c.BodyRegex != "" {
+		if c.body, err = regexp.Compile(c.BodyRegex); err != nil {
+			return fmt.Errorf("%s: body_regex: %w", r.Name, err)
+		}
+	}
+	if err := checkKeywords(c.Keywords); err != nil {
+		return fmt.Errorf("%s: keywords: %w", r.Name, err)
+	}
+	c.keywords = keywordPattern(c.Keywords)
+	return nil
+}
+
+func (c Condition) matches(content Content, now time.Time) bool {
+	if c.Type != "" && c.Type != content.Type {
+		return false
+	}
+	if len(c.Kinds) > 0 && !slices.Contains(c.Kinds, content.Kind) {
+		return false
+	}
+	if c.title != nil && !c.title.MatchString(content.Title) {
+		return false
+	}
+	if c.body != nil && !c.body.MatchString(content.Body) {
+		return false
+	}
+	if c.keywords != nil && !c.keywords.MatchString(content.Title+"\n"+content.Body) {
+		return false
+	}
+	if len(c.Domains) > 0 && !slices.ContainsFunc(content.Domains(), func(host string) bool {
+		return slices.ContainsFunc(c.Domains, func(d string) bool { return matchesDomain(host, d) })
+	}) {
+		return false
+	}
+	if c.AccountAgeBelowDays > 0 && content.accountAgeDays(now) >= c.AccountAgeBelowDays {
+		return false
+	}
+	if c.KarmaBelow != nil && content.AuthorKarma >= *c.KarmaBelow {
+		return false
+	}
+	return true
+}
+
+// Decision is the outcome of evaluating a rule set
+type Decision struct {
+	Action Action `json:"action"`
+	// Rules lists the names of every matching rule
+	Rules  []string `json:"rules,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// Evaluate runs every rule against the content. The most severe matching
+// action wins; its rule's reason is reported.
+func (rs *RuleSet) Evaluate(content Content, now time.Time) Decision {
+	var d Decision
+	if rs == nil {
+		return d
+	}
+	for _, r := range rs.Rules {
+		if !r.If.matches(content, now) {
+			continue
+		}
+		d.Rules = append(d.Rules, r.Name)
+		if severity[r.Action] > severity[d.Action] {
+			d.Action = r.Action
+			d.Reason = r.Reason
+		}
+	}
+	return d
+}
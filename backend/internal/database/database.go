@@ -177,13 +177,7 @@ func New() Service {
 	log.Println("✅ Database connected successfully")
 
 	// Auto migrate schemas
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Post{},
-		&models.Comment{},
-		&models.Follow{},
-		&models.Vote{},
-	)
+	err = Migrate(db)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
@@ -207,6 +201,48 @@ func New() Service {
 	return dbInstance
 }
 
+// Migrate runs the pending data migrations, then creates or updates the
+// tables of every model
+func Migrate(db *gorm.DB) error {
+	if err := runDataMigrations(db); err != nil {
+		return err
+	}
+
+	return db.AutoMigrate(
+		&models.User{},
+		&models.Post{},
+		&models.Comment{},
+		&models.Follow{},
+		&models.Vote{},
+		&models.Ban{},
+		&models.Revision{},
+		&models.PostMedia{},
+		&models.Poll{},
+		&models.PollOption{},
+		&models.PollVote{},
+		&models.Media{},
+		&models.LinkPreview{},
+		&models.Community{},
+		&models.CommunityMember{},
+		&models.CommunityModerator{},
+		&models.CommunityStats{},
+		&models.FlairTemplate{},
+		&models.UserFlair{},
+		&models.CommunityRules{},
+		&models.Mention{},
+		&models.SavedItem{},
+		&models.SavedFolder{},
+		&models.Block{},
+		&models.HiddenPost{},
+		&models.Karma{},
+		&models.SpamModel{},
+		&models.PrivacySettings{},
+		&models.Report{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+	)
+}
+
 func (s *service) GetDB() *gorm.DB {
 	return s.db
 }
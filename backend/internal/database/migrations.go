@@ -0,0 +1,139 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// dataMigration is a one-time change to existing rows, run before the schema
+// is updated, e.g. to clear out rows a new unique index would refuse
+type dataMigration struct {
+	name string
+	run  func(tx *gorm.DB) error
+}
+
+// dataMigrations run in order, each once per database
+var dataMigrations = []dataMigration{
+	{"detach-duplicate-crossposts", detachDuplicateCrossposts},
+	{"remove-duplicate-votes", removeDuplicateVotes},
+	{"remove-duplicate-reports", removeDuplicateReports},
+	{"drop-allow-messages-from", dropAllowMessagesFrom},
+	{"renumber-duplicate-revisions", renumberDuplicateRevisions},
+}
+
+// appliedMigration records a data migration that has run
+type appliedMigration struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string { return "data_migrations" }
+
+// runDataMigrations runs the data migrations this database hasn't had yet,
+// each in its own transaction
+func runDataMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&appliedMigration{}); err != nil {
+		return err
+	}
+	for _, m := range dataMigrations {
+		var count int64
+		if err := db.Model(&appliedMigration{}).Where("name = ?", m.name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.run(tx); err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{Name: m.name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("data migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+// detachDuplicateCrossposts makes later crossposts of a post into a
+// community it was already crossposted to stand alone, as if their parent
+// was deleted, so each post is crossposted to a community once
+func detachDuplicateCrossposts(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable("posts") {
+		return nil
+	}
+	return tx.Exec(`UPDATE posts SET crosspost_parent_id = NULL FROM posts earlier
+		WHERE posts.crosspost_parent_id = earlier.crosspost_parent_id
+		AND posts.community_id = earlier.community_id AND posts.id > earlier.id`).Error
+}
+
+// removeDuplicateVotes keeps each user's first vote on a post or comment.
+// Karma counted the deleted votes too, so it is rebuilt from the rest.
+func removeDuplicateVotes(tx *gorm.DB) error {
+	removed, err := removeDuplicates(tx, "votes", "user_id", "post_id", "comment_id")
+	if err != nil || removed == 0 || !tx.Migrator().HasTable(&models.Karma{}) {
+		return err
+	}
+	return karma.Rebuild(tx)
+}
+
+// removeDuplicateReports keeps each user's first report of a post or
+// comment
+func removeDuplicateReports(tx *gorm.DB) error {
+	_, err := removeDuplicates(tx, "reports", "reporter_id", "post_id", "comment_id")
+	return err
+}
+
+// removeDuplicates deletes the rows of table that repeat an earlier row's
+// columns, NULLs included, keeping the earliest. It returns how many rows it
+// deleted.
+func removeDuplicates(db *gorm.DB, table string, columns ...string) (int64, error) {
+	if !db.Migrator().HasTable(table) {
+		return 0, nil
+	}
+	conditions := make([]string, len(columns))
+	for i, column := range columns {
+		conditions[i] = fmt.Sprintf("later.%[1]s IS NOT DISTINCT FROM earlier.%[1]s", column)
+	}
+	query := fmt.Sprintf("DELETE FROM %[1]s later USING %[1]s earlier WHERE later.id > earlier.id AND %[2]s",
+		table, strings.Join(conditions, " AND "))
+	result := db.Exec(query)
+	return result.RowsAffected, result.Error
+}
+
+// dropAllowMessagesFrom removes the messaging privacy setting, which was
+// saved but never enforced as there are no direct messages
+func dropAllowMessagesFrom(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("privacy_settings", "allow_messages_from") {
+		return nil
+	}
+	return tx.Exec("ALTER TABLE privacy_settings DROP COLUMN allow_messages_from").Error
+}
+
+// renumberDuplicateRevisions gives every version of a post or comment its
+// own number, in the order they were recorded, where concurrent edits
+// recorded two versions under one number. The index it replaces goes too.
+func renumberDuplicateRevisions(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable("revisions") {
+		return nil
+	}
+	err := tx.Exec(`UPDATE revisions SET version = numbered.version FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY content_type, content_id ORDER BY version, id) AS version
+			FROM revisions
+			WHERE (content_type, content_id) IN (
+				SELECT content_type, content_id FROM revisions
+				GROUP BY content_type, content_id, version HAVING COUNT(*) > 1)
+		) AS numbered
+		WHERE revisions.id = numbered.id AND revisions.version <> numbered.version`).Error
+	if err != nil {
+		return err
+	}
+	return tx.Exec("DROP INDEX IF EXISTS idx_revision_content").Error
+}
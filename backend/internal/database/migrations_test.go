@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// openMigrated connects to the test container on its own connection, as
+// TestClose closes the shared one
+func openMigrated(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", host, username, password, database, port)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestRemoveDuplicateVotesRebuildsKarma(t *testing.T) {
+	db := openMigrated(t)
+
+	author := models.User{Username: "dup-author", Email: "dup-author@example.com", Password: "x"}
+	voter := models.User{Username: "dup-voter", Email: "dup-voter@example.com", Password: "x"}
+	db.Create(&author)
+	db.Create(&voter)
+	post := models.Post{Title: "a post", UserID: author.ID, AuthorID: author.ID}
+	db.Create(&post)
+
+	// Votes from before the unique index, each counted in karma
+	if err := db.Migrator().DropIndex(&models.Vote{}, "idx_vote_once"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		db.Create(&models.Vote{UserID: voter.ID, PostID: post.ID, VoteType: 1})
+	}
+	db.Create(&models.Karma{UserID: author.ID, PostKarma: 3})
+
+	if err := removeDuplicateVotes(db); err != nil {
+		t.Fatal(err)
+	}
+	var votes int64
+	db.Model(&models.Vote{}).Where("post_id = ?", post.ID).Count(&votes)
+	if votes != 1 {
+		t.Errorf("%d votes left, want 1", votes)
+	}
+	if got := karma.Of(db, author.ID); got != 1 {
+		t.Errorf("karma %d after removing duplicates, want 1", got)
+	}
+	if err := db.Migrator().CreateIndex(&models.Vote{}, "idx_vote_once"); err != nil {
+		t.Errorf("unique index can't be restored: %v", err)
+	}
+}
+
+func TestRenumberDuplicateRevisions(t *testing.T) {
+	db := openMigrated(t)
+
+	// Versions recorded by concurrent edits before the unique index
+	if err := db.Migrator().DropIndex(&models.Revision{}, "idx_revision_version"); err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []int{1, 2, 2, 3} {
+		db.Create(&models.Revision{ContentType: models.RevisionPost, ContentID: 42, Version: version, Body: "v"})
+	}
+
+	if err := renumberDuplicateRevisions(db); err != nil {
+		t.Fatal(err)
+	}
+	var versions []int
+	db.Model(&models.Revision{}).Where("content_type = ? AND content_id = ?", models.RevisionPost, 42).
+		Order("id").Pluck("version", &versions)
+	if fmt.Sprint(versions) != "[1 2 3 4]" {
+		t.Errorf("versions %v, want [1 2 3 4]", versions)
+	}
+	if err := db.Migrator().CreateIndex(&models.Revision{}, "idx_revision_version"); err != nil {
+		t.Errorf("unique index can't be restored: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package dataloader batches lookups made while resolving a GraphQL query.
+// Resolvers ask for one key each and get back a thunk; the executor resolves
+// a whole level of the query before calling any thunk, so by the time the
+// first one runs every key of that level is queued and fetched in one batch.
+package dataloader
+
+import "sync"
+
+// BatchFunc fetches values for keys. Keys missing from the result resolve to
+// the zero value.
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// Loader batches and caches lookups for the life of one request
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Load queues key and returns a thunk yielding its value. The first thunk
+// called fetches every key queued so far.
+func (l *Loader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	if _, done := l.values[key]; !done && !l.queued[key] && l.errs[key] == nil {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.queued[key] {
+			l.flush()
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// flush fetches the pending keys; l.mu must be held
+func (l *Loader[K, V]) flush() {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(keys)
+	for _, k := range keys {
+		delete(l.queued, k)
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.values[k] = values[k]
+	}
+}
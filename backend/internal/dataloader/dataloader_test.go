@@ -0,0 +1,58 @@
+package dataloader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBatching(t *testing.T) {
+	var batches [][]int
+	l := New(func(keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		out := make(map[int]string)
+		for _, k := range keys {
+			if k != 404 {
+				out[k] = string(rune('a' + k))
+			}
+		}
+		return out, nil
+	})
+
+	a, b, again, missing := l.Load(0), l.Load(1), l.Load(0), l.Load(404)
+	if v, _ := b(); v != "b" {
+		t.Errorf("b = %q", v)
+	}
+	if v, _ := a(); v != "a" {
+		t.Errorf("a = %q", v)
+	}
+	if v, _ := again(); v != "a" {
+		t.Errorf("again = %q", v)
+	}
+	if v, err := missing(); v != "" || err != nil {
+		t.Errorf("missing = %q, %v", v, err)
+	}
+
+	// Cached keys are not fetched again
+	if v, _ := l.Load(1)(); v != "b" {
+		t.Errorf("cached b = %q", v)
+	}
+	l.Load(2)()
+
+	want := [][]int{{0, 1, 404}, {2}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	boom := errors.New("boom")
+	l := New(func(keys []int) (map[int]int, error) { return nil, boom })
+	a, b := l.Load(1), l.Load(2)
+	if _, err := a(); err != boom {
+		t.Errorf("a err = %v", err)
+	}
+	if _, err := b(); err != boom {
+		t.Errorf("b err = %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package diff produces line-based textual diffs between two strings.
+package diff
+
+import "strings"
+
+// Op identifies the kind of change a Line represents
+type Op string
+
+const (
+	Equal  Op = " "
+	Insert Op = "+"
+	Delete Op = "-"
+)
+
+// Line is a single line of diff output
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxCells bounds the LCS table. Past it, the differing middle of the two
+// texts is reported as deleted and inserted wholesale instead of being
+// diffed line by line.
+const maxCells = 1 << 20
+
+// Lines computes a line diff between a and b using the longest common
+// subsequence, so unchanged lines are reported as Equal.
+func Lines(a, b string) []Line {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// Lines shared at the start and end need no table; most edits touch a
+	// small part of a text
+	var out []Line
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		out = append(out, Line{Op: Equal, Text: x[0]})
+		x, y = x[1:], y[1:]
+	}
+	var suffix []Line
+	for len(x) > 0 && len(y) > 0 && x[len(x)-1] == y[len(y)-1] {
+		suffix = append(suffix, Line{Op: Equal, Text: x[len(x)-1]})
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+
+	if (len(x)+1)*(len(y)+1) <= maxCells {
+		out = append(out, lcsLines(x, y)...)
+	} else {
+		for _, line := range x {
+			out = append(out, Line{Op: Delete, Text: line})
+		}
+		for _, line := range y {
+			out = append(out, Line{Op: Insert, Text: line})
+		}
+	}
+
+	for i := len(suffix) - 1; i >= 0; i-- {
+		out = append(out, suffix[i])
+	}
+	return out
+}
+
+// lcsLines diffs x and y with a full LCS table
+func lcsLines(x, y []string) []Line {
+	// lcs[i*width+j] holds the LCS length of x[i:] and y[j:]
+	width := len(y) + 1
+	lcs := make([]int32, (len(x)+1)*width)
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	var out []Line
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, Line{Op: Equal, Text: x[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			out = append(out, Line{Op: Delete, Text: x[i]})
+			i++
+		default:
+			out = append(out, Line{Op: Insert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, Line{Op: Delete, Text: x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, Line{Op: Insert, Text: y[j]})
+	}
+
+	return out
+}
+
+// Unified renders a diff as text, one line per entry prefixed by its op
+func Unified(lines []Line) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(string(l.Op))
+		sb.WriteString(l.Text)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,47 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	got := Unified(Lines("one\ntwo\nthree", "one\n2\nthree\nfour"))
+	want := " one\n-two\n+2\n three\n+four\n"
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLinesEmpty(t *testing.T) {
+	if lines := Lines("", ""); len(lines) != 0 {
+		t.Fatalf("expected no lines, got %v", lines)
+	}
+
+	got := Unified(Lines("cleared body", ""))
+	if got != "-cleared body\n" {
+		t.Fatalf("expected deletion, got %q", got)
+	}
+}
+
+func TestLinesLarge(t *testing.T) {
+	// Two texts sharing no lines would need a table far past maxCells
+	var a, b strings.Builder
+	for i := 0; i < 5000; i++ {
+		a.WriteString("a" + strconv.Itoa(i) + "\n")
+		b.WriteString("b" + strconv.Itoa(i) + "\n")
+	}
+	head, tail := "title\n", "signature\n"
+
+	lines := Lines(head+a.String()+tail, head+b.String()+tail)
+	if len(lines) != 10002 {
+		t.Fatalf("expected 10002 lines, got %d", len(lines))
+	}
+	if lines[0] != (Line{Op: Equal, Text: "title"}) || lines[len(lines)-1] != (Line{Op: Equal, Text: "signature"}) {
+		t.Errorf("expected shared lines to stay equal, got %v and %v", lines[0], lines[len(lines)-1])
+	}
+	if lines[1] != (Line{Op: Delete, Text: "a0"}) || lines[5001] != (Line{Op: Insert, Text: "b0"}) {
+		t.Errorf("expected the middle to be replaced, got %v and %v", lines[1], lines[5001])
+	}
+}
@@ -0,0 +1,87 @@
+// Package events carries notifications about new content between parts of
+// the process, such as from the REST handlers to GraphQL subscriptions. Events
+// name content by ID only; subscribers load it themselves, applying the
+// visibility rules of whoever they deliver it to.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	PostCreated    = "post.created"
+	CommentCreated = "comment.created"
+	// VoteMilestone is a post or comment (CommentID set) reaching a score
+	VoteMilestone = "vote.milestone"
+	ReportFiled   = "report.filed"
+	// UserBanned is a visible ban; shadow bans are not announced
+	UserBanned = "user.banned"
+)
+
+// Event is something that happened to a piece of content or a user. UserID
+// is whoever acted: the author, voter, reporter or banned user.
+type Event struct {
+	Type        string
+	CommunityID int
+	PostID      int
+	CommentID   int
+	UserID      int
+	ReportID    int
+	BanID       int
+	// Milestone is the score reached, for VoteMilestone
+	Milestone int
+	At        time.Time
+}
+
+// Bus fans events out to subscribers. Publishing never blocks: a subscriber
+// whose buffer is full misses the event.
+type Bus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[int]chan Event
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[int]chan Event)}
+}
+
+// Publish delivers e to every current subscriber
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving events published from now on, and a
+// function that unsubscribes and closes the channel
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
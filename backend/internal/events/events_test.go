@@ -0,0 +1,36 @@
+package events
+
+import "testing"
+
+func TestBusFanOut(t *testing.T) {
+	bus := NewBus()
+	a, unsubA := bus.Subscribe(1)
+	b, unsubB := bus.Subscribe(1)
+	defer unsubB()
+
+	bus.Publish(Event{Type: CommentCreated, CommentID: 1})
+	if e := <-a; e.CommentID != 1 || e.At.IsZero() {
+		t.Errorf("a got %+v", e)
+	}
+	if e := <-b; e.CommentID != 1 {
+		t.Errorf("b got %+v", e)
+	}
+
+	unsubA()
+	unsubA()
+	if _, open := <-a; open {
+		t.Error("channel still open after unsubscribe")
+	}
+
+	// A full buffer drops the event instead of blocking the publisher
+	bus.Publish(Event{Type: CommentCreated, CommentID: 2})
+	bus.Publish(Event{Type: CommentCreated, CommentID: 3})
+	if e := <-b; e.CommentID != 2 {
+		t.Errorf("b got %+v, want comment 2", e)
+	}
+	select {
+	case e := <-b:
+		t.Errorf("b got %+v, want nothing", e)
+	default:
+	}
+}
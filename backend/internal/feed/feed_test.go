@@ -0,0 +1,46 @@
+package feed
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Rank: 37821.123456789012, ID: 42}
+	got, err := DecodeCursor(want.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != want {
+		t.Fatalf("got %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not-base64!", "e30"} {
+		if _, err := DecodeCursor(s); err == nil {
+			t.Errorf("DecodeCursor(%q) succeeded", s)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	if s, err := ParseSort("", SortNew); err != nil || s != SortNew {
+		t.Errorf("empty sort = %q, %v", s, err)
+	}
+	if s, err := ParseSort("top", SortNew); err != nil || s != SortTop {
+		t.Errorf("top sort = %q, %v", s, err)
+	}
+	if _, err := ParseSort("best", SortNew); err == nil {
+		t.Error("unknown sort accepted")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	if d, err := ParseWindow("all"); err != nil || d != 0 {
+		t.Errorf("all = %v, %v", d, err)
+	}
+	if d, err := ParseWindow("day"); err != nil || d.Hours() != 24 {
+		t.Errorf("day = %v, %v", d, err)
+	}
+	if _, err := ParseWindow("decade"); err == nil {
+		t.Error("unknown window accepted")
+	}
+}
@@ -0,0 +1,59 @@
+package feed
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// Source decides which posts a user's feed contains
+type Source interface {
+	// Scope restricts a posts query to the feed of userID
+	Scope(userID int) func(*gorm.DB) *gorm.DB
+}
+
+// Subscriptions is the home feed: posts by followed users and posts in
+// joined communities, resolved at read time
+type Subscriptions struct{}
+
+func (Subscriptions) Scope(userID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`posts.user_id IN (SELECT following_id FROM follows WHERE follower_id = ?)
+			OR posts.community_id IN (SELECT community_id FROM community_members WHERE user_id = ?)`,
+			userID, userID)
+	}
+}
+
+// HasSubscriptions reports whether the user follows anyone or has joined
+// any community
+func HasSubscriptions(db *gorm.DB, userID int) bool {
+	var follows, memberships int64
+	db.Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&follows)
+	if follows > 0 {
+		return true
+	}
+	db.Model(&models.CommunityMember{}).Where("user_id = ?", userID).Count(&memberships)
+	return memberships > 0
+}
+
+// All is every post on the site
+type All struct{}
+
+func (All) Scope(userID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB { return db }
+}
+
+// PopularWindow is how far back the popular feed looks
+const PopularWindow = 7 * 24 * time.Hour
+
+// Popular is recent posts from across the site that haven't been voted
+// below zero. It is also what new users see before they follow anything.
+type Popular struct{}
+
+func (Popular) Scope(userID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("posts.created_at > ? AND "+scoreSQL+" >= 0", time.Now().Add(-PopularWindow))
+	}
+}
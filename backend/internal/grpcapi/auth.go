@@ -0,0 +1,127 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/middleware"
+)
+
+// healthService is left open so that orchestrators can probe the server
+const healthService = "/grpc.health.v1.Health/"
+
+type serviceKey struct{}
+
+// Service returns the name of the service that made the call: the common
+// name of its client certificate, or the service claim of its token
+func Service(ctx context.Context) string {
+	name, _ := ctx.Value(serviceKey{}).(string)
+	return name
+}
+
+// Interceptors authenticate every call, by a verified client certificate
+// or else by a service token (see middleware.NewServiceToken) sent as
+// "authorization: Bearer <token>" metadata
+func Interceptors() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if strings.HasPrefix(info.FullMethod, healthService) {
+				return handler(ctx, req)
+			}
+			ctx, err := authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if strings.HasPrefix(info.FullMethod, healthService) {
+				return handler(srv, ss)
+			}
+			ctx, err := authenticate(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+func authenticate(ctx context.Context) (context.Context, error) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+			if name := info.State.VerifiedChains[0][0].Subject.CommonName; name != "" {
+				return context.WithValue(ctx, serviceKey{}, name), nil
+			}
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "client certificate or service token required")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+	name, err := middleware.ParseServiceToken(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid service token")
+	}
+	return context.WithValue(ctx, serviceKey{}, name), nil
+}
+
+// authenticatedStream carries the caller's identity to stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// OptionsFromEnv returns the transport options set in the environment.
+// GRPC_TLS_CERT and GRPC_TLS_KEY enable TLS; GRPC_CLIENT_CA additionally
+// accepts client certificates signed by that CA in place of a token.
+// Without them the server runs in plaintext, for use inside a private
+// network.
+func OptionsFromEnv() ([]grpc.ServerOption, error) {
+	certFile, keyFile, caFile := os.Getenv("GRPC_TLS_CERT"), os.Getenv("GRPC_TLS_KEY"), os.Getenv("GRPC_CLIENT_CA")
+	if certFile == "" && keyFile == "" {
+		if caFile != "" {
+			return nil, errors.New("GRPC_CLIENT_CA requires GRPC_TLS_CERT and GRPC_TLS_KEY")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("GRPC_CLIENT_CA contains no certificates")
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(config))}, nil
+}
@@ -0,0 +1,1076 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: internal/grpcapi/contentv1/content.proto
+
+package contentv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED     EventType = 0
+	EventType_EVENT_TYPE_POST_CREATED    EventType = 1
+	EventType_EVENT_TYPE_COMMENT_CREATED EventType = 2
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_POST_CREATED",
+		2: "EVENT_TYPE_COMMENT_CREATED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED":     0,
+		"EVENT_TYPE_POST_CREATED":    1,
+		"EVENT_TYPE_COMMENT_CREATED": 2,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_grpcapi_contentv1_content_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_internal_grpcapi_contentv1_content_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{0}
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Bio           string                 `protobuf:"bytes,3,opt,name=bio,proto3" json:"bio,omitempty"`
+	Avatar        string                 `protobuf:"bytes,4,opt,name=avatar,proto3" json:"avatar,omitempty"`
+	Role          string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *User) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetBio() string {
+	if x != nil {
+		return x.Bio
+	}
+	return ""
+}
+
+func (x *User) GetAvatar() string {
+	if x != nil {
+		return x.Avatar
+	}
+	return ""
+}
+
+func (x *User) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type Post struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// text, link, gallery, video, poll or crosspost
+	Kind     string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Body     string `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	BodyHtml string `protobuf:"bytes,5,opt,name=body_html,json=bodyHtml,proto3" json:"body_html,omitempty"`
+	Excerpt  string `protobuf:"bytes,6,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	Url      string `protobuf:"bytes,7,opt,name=url,proto3" json:"url,omitempty"`
+	Domain   string `protobuf:"bytes,8,opt,name=domain,proto3" json:"domain,omitempty"`
+	Image    string `protobuf:"bytes,9,opt,name=image,proto3" json:"image,omitempty"`
+	Author   *User  `protobuf:"bytes,10,opt,name=author,proto3" json:"author,omitempty"`
+	// 0 for posts outside any community
+	CommunityId       int64                  `protobuf:"varint,11,opt,name=community_id,json=communityId,proto3" json:"community_id,omitempty"`
+	Community         string                 `protobuf:"bytes,12,opt,name=community,proto3" json:"community,omitempty"`
+	Upvotes           int64                  `protobuf:"varint,13,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes         int64                  `protobuf:"varint,14,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	CommentCount      int64                  `protobuf:"varint,15,opt,name=comment_count,json=commentCount,proto3" json:"comment_count,omitempty"`
+	CrosspostParentId *int64                 `protobuf:"varint,16,opt,name=crosspost_parent_id,json=crosspostParentId,proto3,oneof" json:"crosspost_parent_id,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Unset unless the post was edited
+	EditedAt      *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Post) Reset() {
+	*x = Post{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Post) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Post) ProtoMessage() {}
+
+func (x *Post) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Post.ProtoReflect.Descriptor instead.
+func (*Post) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Post) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Post) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Post) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Post) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Post) GetBodyHtml() string {
+	if x != nil {
+		return x.BodyHtml
+	}
+	return ""
+}
+
+func (x *Post) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Post) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Post) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Post) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Post) GetAuthor() *User {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Post) GetCommunityId() int64 {
+	if x != nil {
+		return x.CommunityId
+	}
+	return 0
+}
+
+func (x *Post) GetCommunity() string {
+	if x != nil {
+		return x.Community
+	}
+	return ""
+}
+
+func (x *Post) GetUpvotes() int64 {
+	if x != nil {
+		return x.Upvotes
+	}
+	return 0
+}
+
+func (x *Post) GetDownvotes() int64 {
+	if x != nil {
+		return x.Downvotes
+	}
+	return 0
+}
+
+func (x *Post) GetCommentCount() int64 {
+	if x != nil {
+		return x.CommentCount
+	}
+	return 0
+}
+
+func (x *Post) GetCrosspostParentId() int64 {
+	if x != nil && x.CrosspostParentId != nil {
+		return *x.CrosspostParentId
+	}
+	return 0
+}
+
+func (x *Post) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Post) GetEditedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EditedAt
+	}
+	return nil
+}
+
+type Comment struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PostId          int64                  `protobuf:"varint,2,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	ParentCommentId *int64                 `protobuf:"varint,3,opt,name=parent_comment_id,json=parentCommentId,proto3,oneof" json:"parent_comment_id,omitempty"`
+	Author          *User                  `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Body            string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"`
+	BodyHtml        string                 `protobuf:"bytes,6,opt,name=body_html,json=bodyHtml,proto3" json:"body_html,omitempty"`
+	Excerpt         string                 `protobuf:"bytes,7,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	Upvotes         int64                  `protobuf:"varint,8,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes       int64                  `protobuf:"varint,9,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	EditedAt        *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Comment) Reset() {
+	*x = Comment{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Comment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Comment) ProtoMessage() {}
+
+func (x *Comment) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Comment.ProtoReflect.Descriptor instead.
+func (*Comment) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Comment) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Comment) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *Comment) GetParentCommentId() int64 {
+	if x != nil && x.ParentCommentId != nil {
+		return *x.ParentCommentId
+	}
+	return 0
+}
+
+func (x *Comment) GetAuthor() *User {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Comment) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Comment) GetBodyHtml() string {
+	if x != nil {
+		return x.BodyHtml
+	}
+	return ""
+}
+
+func (x *Comment) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+func (x *Comment) GetUpvotes() int64 {
+	if x != nil {
+		return x.Upvotes
+	}
+	return 0
+}
+
+func (x *Comment) GetDownvotes() int64 {
+	if x != nil {
+		return x.Downvotes
+	}
+	return 0
+}
+
+func (x *Comment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Comment) GetEditedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EditedAt
+	}
+	return nil
+}
+
+type GetPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostsRequest) Reset() {
+	*x = GetPostsRequest{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostsRequest) ProtoMessage() {}
+
+func (x *GetPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostsRequest.ProtoReflect.Descriptor instead.
+func (*GetPostsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPostsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type GetPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostsResponse) Reset() {
+	*x = GetPostsResponse{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostsResponse) ProtoMessage() {}
+
+func (x *GetPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostsResponse.ProtoReflect.Descriptor instead.
+func (*GetPostsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPostsResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+type GetCommentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommentsRequest) Reset() {
+	*x = GetCommentsRequest{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommentsRequest) ProtoMessage() {}
+
+func (x *GetCommentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommentsRequest.ProtoReflect.Descriptor instead.
+func (*GetCommentsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCommentsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type GetCommentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Comments      []*Comment             `protobuf:"bytes,1,rep,name=comments,proto3" json:"comments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCommentsResponse) Reset() {
+	*x = GetCommentsResponse{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCommentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCommentsResponse) ProtoMessage() {}
+
+func (x *GetCommentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCommentsResponse.ProtoReflect.Descriptor instead.
+func (*GetCommentsResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetCommentsResponse) GetComments() []*Comment {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+type GetUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Usernames     []string               `protobuf:"bytes,2,rep,name=usernames,proto3" json:"usernames,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersRequest) Reset() {
+	*x = GetUsersRequest{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersRequest) ProtoMessage() {}
+
+func (x *GetUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersRequest.ProtoReflect.Descriptor instead.
+func (*GetUsersRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetUsersRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *GetUsersRequest) GetUsernames() []string {
+	if x != nil {
+		return x.Usernames
+	}
+	return nil
+}
+
+type GetUsersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsersResponse) Reset() {
+	*x = GetUsersResponse{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersResponse) ProtoMessage() {}
+
+func (x *GetUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersResponse.ProtoReflect.Descriptor instead.
+func (*GetUsersResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetUsersResponse) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+type StreamEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Event types to receive; all if empty
+	Types []EventType `protobuf:"varint,1,rep,packed,name=types,proto3,enum=reddit.content.v1.EventType" json:"types,omitempty"`
+	// Communities to receive events from; all if empty
+	CommunityIds []int64 `protobuf:"varint,2,rep,packed,name=community_ids,json=communityIds,proto3" json:"community_ids,omitempty"`
+	// Attach the new post or comment to each event. Events for content an
+	// anonymous visitor cannot see, such as posts held for review, are then
+	// skipped.
+	IncludeContent bool `protobuf:"varint,3,opt,name=include_content,json=includeContent,proto3" json:"include_content,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamEventsRequest) GetTypes() []EventType {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetCommunityIds() []int64 {
+	if x != nil {
+		return x.CommunityIds
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetIncludeContent() bool {
+	if x != nil {
+		return x.IncludeContent
+	}
+	return false
+}
+
+type Event struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Type        EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=reddit.content.v1.EventType" json:"type,omitempty"`
+	CommunityId int64                  `protobuf:"varint,2,opt,name=community_id,json=communityId,proto3" json:"community_id,omitempty"`
+	PostId      int64                  `protobuf:"varint,3,opt,name=post_id,json=postId,proto3" json:"post_id,omitempty"`
+	// Set for comment events
+	CommentId int64                  `protobuf:"varint,4,opt,name=comment_id,json=commentId,proto3" json:"comment_id,omitempty"`
+	UserId    int64                  `protobuf:"varint,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	At        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=at,proto3" json:"at,omitempty"`
+	// Set when include_content was requested
+	Post          *Post    `protobuf:"bytes,7,opt,name=post,proto3" json:"post,omitempty"`
+	Comment       *Comment `protobuf:"bytes,8,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_contentv1_content_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_contentv1_content_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetCommunityId() int64 {
+	if x != nil {
+		return x.CommunityId
+	}
+	return 0
+}
+
+func (x *Event) GetPostId() int64 {
+	if x != nil {
+		return x.PostId
+	}
+	return 0
+}
+
+func (x *Event) GetCommentId() int64 {
+	if x != nil {
+		return x.CommentId
+	}
+	return 0
+}
+
+func (x *Event) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Event) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *Event) GetPost() *Post {
+	if x != nil {
+		return x.Post
+	}
+	return nil
+}
+
+func (x *Event) GetComment() *Comment {
+	if x != nil {
+		return x.Comment
+	}
+	return nil
+}
+
+var File_internal_grpcapi_contentv1_content_proto protoreflect.FileDescriptor
+
+const file_internal_grpcapi_contentv1_content_proto_rawDesc = "" +
+	"\n" +
+	"(internal/grpcapi/contentv1/content.proto\x12\x11reddit.content.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xab\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x10\n" +
+	"\x03bio\x18\x03 \x01(\tR\x03bio\x12\x16\n" +
+	"\x06avatar\x18\x04 \x01(\tR\x06avatar\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xdb\x04\n" +
+	"\x04Post\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1b\n" +
+	"\tbody_html\x18\x05 \x01(\tR\bbodyHtml\x12\x18\n" +
+	"\aexcerpt\x18\x06 \x01(\tR\aexcerpt\x12\x10\n" +
+	"\x03url\x18\a \x01(\tR\x03url\x12\x16\n" +
+	"\x06domain\x18\b \x01(\tR\x06domain\x12\x14\n" +
+	"\x05image\x18\t \x01(\tR\x05image\x12/\n" +
+	"\x06author\x18\n" +
+	" \x01(\v2\x17.reddit.content.v1.UserR\x06author\x12!\n" +
+	"\fcommunity_id\x18\v \x01(\x03R\vcommunityId\x12\x1c\n" +
+	"\tcommunity\x18\f \x01(\tR\tcommunity\x12\x18\n" +
+	"\aupvotes\x18\r \x01(\x03R\aupvotes\x12\x1c\n" +
+	"\tdownvotes\x18\x0e \x01(\x03R\tdownvotes\x12#\n" +
+	"\rcomment_count\x18\x0f \x01(\x03R\fcommentCount\x123\n" +
+	"\x13crosspost_parent_id\x18\x10 \x01(\x03H\x00R\x11crosspostParentId\x88\x01\x01\x129\n" +
+	"\n" +
+	"created_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tedited_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\beditedAtB\x16\n" +
+	"\x14_crosspost_parent_id\"\xa1\x03\n" +
+	"\aComment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\apost_id\x18\x02 \x01(\x03R\x06postId\x12/\n" +
+	"\x11parent_comment_id\x18\x03 \x01(\x03H\x00R\x0fparentCommentId\x88\x01\x01\x12/\n" +
+	"\x06author\x18\x04 \x01(\v2\x17.reddit.content.v1.UserR\x06author\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12\x1b\n" +
+	"\tbody_html\x18\x06 \x01(\tR\bbodyHtml\x12\x18\n" +
+	"\aexcerpt\x18\a \x01(\tR\aexcerpt\x12\x18\n" +
+	"\aupvotes\x18\b \x01(\x03R\aupvotes\x12\x1c\n" +
+	"\tdownvotes\x18\t \x01(\x03R\tdownvotes\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tedited_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\beditedAtB\x14\n" +
+	"\x12_parent_comment_id\"#\n" +
+	"\x0fGetPostsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"A\n" +
+	"\x10GetPostsResponse\x12-\n" +
+	"\x05posts\x18\x01 \x03(\v2\x17.reddit.content.v1.PostR\x05posts\"&\n" +
+	"\x12GetCommentsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"M\n" +
+	"\x13GetCommentsResponse\x126\n" +
+	"\bcomments\x18\x01 \x03(\v2\x1a.reddit.content.v1.CommentR\bcomments\"A\n" +
+	"\x0fGetUsersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\x12\x1c\n" +
+	"\tusernames\x18\x02 \x03(\tR\tusernames\"A\n" +
+	"\x10GetUsersResponse\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.reddit.content.v1.UserR\x05users\"\x97\x01\n" +
+	"\x13StreamEventsRequest\x122\n" +
+	"\x05types\x18\x01 \x03(\x0e2\x1c.reddit.content.v1.EventTypeR\x05types\x12#\n" +
+	"\rcommunity_ids\x18\x02 \x03(\x03R\fcommunityIds\x12'\n" +
+	"\x0finclude_content\x18\x03 \x01(\bR\x0eincludeContent\"\xbc\x02\n" +
+	"\x05Event\x120\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1c.reddit.content.v1.EventTypeR\x04type\x12!\n" +
+	"\fcommunity_id\x18\x02 \x01(\x03R\vcommunityId\x12\x17\n" +
+	"\apost_id\x18\x03 \x01(\x03R\x06postId\x12\x1d\n" +
+	"\n" +
+	"comment_id\x18\x04 \x01(\x03R\tcommentId\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\x03R\x06userId\x12*\n" +
+	"\x02at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x02at\x12+\n" +
+	"\x04post\x18\a \x01(\v2\x17.reddit.content.v1.PostR\x04post\x124\n" +
+	"\acomment\x18\b \x01(\v2\x1a.reddit.content.v1.CommentR\acomment*d\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17EVENT_TYPE_POST_CREATED\x10\x01\x12\x1e\n" +
+	"\x1aEVENT_TYPE_COMMENT_CREATED\x10\x022\xec\x02\n" +
+	"\x0eContentService\x12S\n" +
+	"\bGetPosts\x12\".reddit.content.v1.GetPostsRequest\x1a#.reddit.content.v1.GetPostsResponse\x12\\\n" +
+	"\vGetComments\x12%.reddit.content.v1.GetCommentsRequest\x1a&.reddit.content.v1.GetCommentsResponse\x12S\n" +
+	"\bGetUsers\x12\".reddit.content.v1.GetUsersRequest\x1a#.reddit.content.v1.GetUsersResponse\x12R\n" +
+	"\fStreamEvents\x12&.reddit.content.v1.StreamEventsRequest\x1a\x18.reddit.content.v1.Event0\x01BWZUgithub.com/emilythestrangee/reddit-clone/backend/internal/grpcapi/contentv1;contentv1b\x06proto3"
+
+var (
+	file_internal_grpcapi_contentv1_content_proto_rawDescOnce sync.Once
+	file_internal_grpcapi_contentv1_content_proto_rawDescData []byte
+)
+
+func file_internal_grpcapi_contentv1_content_proto_rawDescGZIP() []byte {
+	file_internal_grpcapi_contentv1_content_proto_rawDescOnce.Do(func() {
+		file_internal_grpcapi_contentv1_content_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_grpcapi_contentv1_content_proto_rawDesc), len(file_internal_grpcapi_contentv1_content_proto_rawDesc)))
+	})
+	return file_internal_grpcapi_contentv1_content_proto_rawDescData
+}
+
+var file_internal_grpcapi_contentv1_content_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_grpcapi_contentv1_content_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_internal_grpcapi_contentv1_content_proto_goTypes = []any{
+	(EventType)(0),                // 0: reddit.content.v1.EventType
+	(*User)(nil),                  // 1: reddit.content.v1.User
+	(*Post)(nil),                  // 2: reddit.content.v1.Post
+	(*Comment)(nil),               // 3: reddit.content.v1.Comment
+	(*GetPostsRequest)(nil),       // 4: reddit.content.v1.GetPostsRequest
+	(*GetPostsResponse)(nil),      // 5: reddit.content.v1.GetPostsResponse
+	(*GetCommentsRequest)(nil),    // 6: reddit.content.v1.GetCommentsRequest
+	(*GetCommentsResponse)(nil),   // 7: reddit.content.v1.GetCommentsResponse
+	(*GetUsersRequest)(nil),       // 8: reddit.content.v1.GetUsersRequest
+	(*GetUsersResponse)(nil),      // 9: reddit.content.v1.GetUsersResponse
+	(*StreamEventsRequest)(nil),   // 10: reddit.content.v1.StreamEventsRequest
+	(*Event)(nil),                 // 11: reddit.content.v1.Event
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_internal_grpcapi_contentv1_content_proto_depIdxs = []int32{
+	12, // 0: reddit.content.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: reddit.content.v1.Post.author:type_name -> reddit.content.v1.User
+	12, // 2: reddit.content.v1.Post.created_at:type_name -> google.protobuf.Timestamp
+	12, // 3: reddit.content.v1.Post.edited_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: reddit.content.v1.Comment.author:type_name -> reddit.content.v1.User
+	12, // 5: reddit.content.v1.Comment.created_at:type_name -> google.protobuf.Timestamp
+	12, // 6: reddit.content.v1.Comment.edited_at:type_name -> google.protobuf.Timestamp
+	2,  // 7: reddit.content.v1.GetPostsResponse.posts:type_name -> reddit.content.v1.Post
+	3,  // 8: reddit.content.v1.GetCommentsResponse.comments:type_name -> reddit.content.v1.Comment
+	1,  // 9: reddit.content.v1.GetUsersResponse.users:type_name -> reddit.content.v1.User
+	0,  // 10: reddit.content.v1.StreamEventsRequest.types:type_name -> reddit.content.v1.EventType
+	0,  // 11: reddit.content.v1.Event.type:type_name -> reddit.content.v1.EventType
+	12, // 12: reddit.content.v1.Event.at:type_name -> google.protobuf.Timestamp
+	2,  // 13: reddit.content.v1.Event.post:type_name -> reddit.content.v1.Post
+	3,  // 14: reddit.content.v1.Event.comment:type_name -> reddit.content.v1.Comment
+	4,  // 15: reddit.content.v1.ContentService.GetPosts:input_type -> reddit.content.v1.GetPostsRequest
+	6,  // 16: reddit.content.v1.ContentService.GetComments:input_type -> reddit.content.v1.GetCommentsRequest
+	8,  // 17: reddit.content.v1.ContentService.GetUsers:input_type -> reddit.content.v1.GetUsersRequest
+	10, // 18: reddit.content.v1.ContentService.StreamEvents:input_type -> reddit.content.v1.StreamEventsRequest
+	5,  // 19: reddit.content.v1.ContentService.GetPosts:output_type -> reddit.content.v1.GetPostsResponse
+	7,  // 20: reddit.content.v1.ContentService.GetComments:output_type -> reddit.content.v1.GetCommentsResponse
+	9,  // 21: reddit.content.v1.ContentService.GetUsers:output_type -> reddit.content.v1.GetUsersResponse
+	11, // 22: reddit.content.v1.ContentService.StreamEvents:output_type -> reddit.content.v1.Event
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_internal_grpcapi_contentv1_content_proto_init() }
+func file_internal_grpcapi_contentv1_content_proto_init() {
+	if File_internal_grpcapi_contentv1_content_proto != nil {
+		return
+	}
+	file_internal_grpcapi_contentv1_content_proto_msgTypes[1].OneofWrappers = []any{}
+	file_internal_grpcapi_contentv1_content_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_grpcapi_contentv1_content_proto_rawDesc), len(file_internal_grpcapi_contentv1_content_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_grpcapi_contentv1_content_proto_goTypes,
+		DependencyIndexes: file_internal_grpcapi_contentv1_content_proto_depIdxs,
+		EnumInfos:         file_internal_grpcapi_contentv1_content_proto_enumTypes,
+		MessageInfos:      file_internal_grpcapi_contentv1_content_proto_msgTypes,
+	}.Build()
+	File_internal_grpcapi_contentv1_content_proto = out.File
+	file_internal_grpcapi_contentv1_content_proto_goTypes = nil
+	file_internal_grpcapi_contentv1_content_proto_depIdxs = nil
+}
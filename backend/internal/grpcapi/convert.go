@@ -0,0 +1,97 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/grpcapi/contentv1"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/handlers"
+)
+
+func toUser(u handlers.PublicUser) *contentv1.User {
+	return &contentv1.User{
+		Id:        int64(u.ID),
+		Username:  u.Username,
+		Bio:       u.Bio,
+		Avatar:    u.Avatar,
+		Role:      u.Role,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+	}
+}
+
+func toPost(v handlers.PostView) *contentv1.Post {
+	return &contentv1.Post{
+		Id:                int64(v.ID),
+		Title:             v.Title,
+		Kind:              v.Kind,
+		Body:              v.Body,
+		BodyHtml:          v.BodyHTML,
+		Excerpt:           v.Excerpt,
+		Url:               v.URL,
+		Domain:            v.Domain,
+		Image:             v.Image,
+		Author:            toUser(v.User),
+		CommunityId:       int64(v.CommunityID),
+		Community:         v.Community,
+		Upvotes:           int64(v.Upvotes),
+		Downvotes:         int64(v.Downvotes),
+		CommentCount:      int64(v.Comments),
+		CrosspostParentId: optionalID(v.CrosspostParentID),
+		CreatedAt:         timestamppb.New(v.CreatedAt),
+		EditedAt:          optionalTime(v.EditedAt),
+	}
+}
+
+func toComment(v handlers.CommentView) *contentv1.Comment {
+	return &contentv1.Comment{
+		Id:              int64(v.ID),
+		PostId:          int64(v.PostID),
+		ParentCommentId: optionalID(v.ParentCommentID),
+		Author:          toUser(v.User),
+		Body:            v.Body,
+		BodyHtml:        v.BodyHTML,
+		Excerpt:         v.Excerpt,
+		Upvotes:         int64(v.Upvotes),
+		Downvotes:       int64(v.Downvotes),
+		CreatedAt:       timestamppb.New(v.CreatedAt),
+		EditedAt:        optionalTime(v.EditedAt),
+	}
+}
+
+func toEvent(e events.Event) *contentv1.Event {
+	return &contentv1.Event{
+		Type:        eventType(e.Type),
+		CommunityId: int64(e.CommunityID),
+		PostId:      int64(e.PostID),
+		CommentId:   int64(e.CommentID),
+		UserId:      int64(e.UserID),
+		At:          timestamppb.New(e.At),
+	}
+}
+
+func eventType(t string) contentv1.EventType {
+	switch t {
+	case events.PostCreated:
+		return contentv1.EventType_EVENT_TYPE_POST_CREATED
+	case events.CommentCreated:
+		return contentv1.EventType_EVENT_TYPE_COMMENT_CREATED
+	}
+	return contentv1.EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func optionalID(id *int) *int64 {
+	if id == nil {
+		return nil
+	}
+	v := int64(*id)
+	return &v
+}
+
+func optionalTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,178 @@
+// Package grpcapi serves content to other backend services over gRPC. It
+// shares the service layer of the HTTP API, and returns content as an
+// anonymous visitor sees it.
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/grpcapi/contentv1"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/handlers"
+)
+
+// maxIDs is how many posts, comments or users one call may ask for
+const maxIDs = 100
+
+// eventBuffer is how many events a slow stream may fall behind by before
+// it misses some
+const eventBuffer = 64
+
+// NewServer returns a gRPC server with the content service and the standard
+// health service registered. Every call must be authenticated; see
+// Interceptors.
+func NewServer(db *gorm.DB, bus *events.Bus, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(append(opts, Interceptors()...)...)
+	contentv1.RegisterContentServiceServer(srv, &contentServer{db: db, events: bus})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+type contentServer struct {
+	contentv1.UnimplementedContentServiceServer
+
+	db     *gorm.DB
+	events *events.Bus
+}
+
+func (s *contentServer) GetPosts(ctx context.Context, req *contentv1.GetPostsRequest) (*contentv1.GetPostsResponse, error) {
+	ids, err := toIDs(req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+	views, err := handlers.LoadPosts(s.db.WithContext(ctx), ids, 0)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load posts")
+	}
+	resp := &contentv1.GetPostsResponse{Posts: make([]*contentv1.Post, len(views))}
+	for i, view := range views {
+		resp.Posts[i] = toPost(view)
+	}
+	return resp, nil
+}
+
+func (s *contentServer) GetComments(ctx context.Context, req *contentv1.GetCommentsRequest) (*contentv1.GetCommentsResponse, error) {
+	ids, err := toIDs(req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+	views, err := handlers.LoadComments(s.db.WithContext(ctx), ids, 0)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load comments")
+	}
+	resp := &contentv1.GetCommentsResponse{Comments: make([]*contentv1.Comment, len(views))}
+	for i, view := range views {
+		resp.Comments[i] = toComment(view)
+	}
+	return resp, nil
+}
+
+func (s *contentServer) GetUsers(ctx context.Context, req *contentv1.GetUsersRequest) (*contentv1.GetUsersResponse, error) {
+	if len(req.GetIds())+len(req.GetUsernames()) > maxIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d users per call", maxIDs)
+	}
+	ids, err := toIDs(req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+	users, err := handlers.LoadUsers(s.db.WithContext(ctx), ids, req.GetUsernames())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load users")
+	}
+	resp := &contentv1.GetUsersResponse{Users: make([]*contentv1.User, len(users))}
+	for i, u := range users {
+		resp.Users[i] = toUser(u)
+	}
+	return resp, nil
+}
+
+func (s *contentServer) StreamEvents(req *contentv1.StreamEventsRequest, stream grpc.ServerStreamingServer[contentv1.Event]) error {
+	feed, unsubscribe := s.events.Subscribe(eventBuffer)
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-feed:
+			if !wants(req, e) {
+				continue
+			}
+			msg := toEvent(e)
+			if req.GetIncludeContent() && !s.attachContent(ctx, msg, e) {
+				continue
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// attachContent adds the new post or comment to msg. It reports false if
+// the content cannot be shown, e.g. because it is held for review.
+func (s *contentServer) attachContent(ctx context.Context, msg *contentv1.Event, e events.Event) bool {
+	db := s.db.WithContext(ctx)
+	switch e.Type {
+	case events.PostCreated:
+		posts, err := handlers.LoadPosts(db, []int{e.PostID}, 0)
+		if err != nil || len(posts) == 0 {
+			return false
+		}
+		msg.Post = toPost(posts[0])
+	case events.CommentCreated:
+		comments, err := handlers.LoadComments(db, []int{e.CommentID}, 0)
+		if err != nil || len(comments) == 0 {
+			return false
+		}
+		msg.Comment = toComment(comments[0])
+	}
+	return true
+}
+
+// wants reports whether e is a content event matching the filters of a
+// stream request
+func wants(req *contentv1.StreamEventsRequest, e events.Event) bool {
+	if eventType(e.Type) == contentv1.EventType_EVENT_TYPE_UNSPECIFIED {
+		return false
+	}
+	if types := req.GetTypes(); len(types) > 0 {
+		found := false
+		for _, t := range types {
+			found = found || t == eventType(e.Type)
+		}
+		if !found {
+			return false
+		}
+	}
+	if communities := req.GetCommunityIds(); len(communities) > 0 {
+		found := false
+		for _, id := range communities {
+			found = found || id == int64(e.CommunityID)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// toIDs checks the size of a request and converts its IDs
+func toIDs(ids []int64) ([]int, error) {
+	if len(ids) > maxIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d IDs per call", maxIDs)
+	}
+	out := make([]int, len(ids))
+	for i, id := range ids {
+		out[i] = int(id)
+	}
+	return out, nil
+}
@@ -0,0 +1,254 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/grpcapi/contentv1"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/middleware"
+)
+
+// serve starts srv in process and returns a connection to it
+func serve(t *testing.T, srv *grpc.Server, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// tooMany is a request that passes authentication but fails before any
+// database access
+func tooMany() *contentv1.GetPostsRequest {
+	return &contentv1.GetPostsRequest{Ids: make([]int64, maxIDs+1)}
+}
+
+func TestServiceTokenAuth(t *testing.T) {
+	conn := serve(t, NewServer(nil, events.NewBus()), insecure.NewCredentials())
+	client := contentv1.NewContentServiceClient(conn)
+	ctx := context.Background()
+
+	serviceToken, err := middleware.NewServiceToken("recommendations", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(os.Getenv("JWT_SECRET")))
+	expired, _ := middleware.NewServiceToken("recommendations", -time.Minute)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"no token", ctx, codes.Unauthenticated},
+		{"user token", withToken(ctx, userToken), codes.Unauthenticated},
+		{"expired token", withToken(ctx, expired), codes.Unauthenticated},
+		{"garbage", withToken(ctx, "nope"), codes.Unauthenticated},
+		{"service token", withToken(ctx, serviceToken), codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := client.GetPosts(tt.ctx, tooMany())
+		if got := status.Code(err); got != tt.code {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.code)
+		}
+	}
+
+	// Health checks need no credentials
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("health check: %v %v", resp, err)
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	bus := events.NewBus()
+	client := contentv1.NewContentServiceClient(serve(t, NewServer(nil, bus), insecure.NewCredentials()))
+
+	token, _ := middleware.NewServiceToken("analytics", time.Hour)
+	ctx, cancel := context.WithTimeout(withToken(context.Background(), token), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamEvents(ctx, &contentv1.StreamEventsRequest{
+		Types:        []contentv1.EventType{contentv1.EventType_EVENT_TYPE_COMMENT_CREATED},
+		CommunityIds: []int64{7},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server subscribes once the stream is set up, so keep publishing
+	// until something arrives; only the matching event may get through
+	received := make(chan *contentv1.Event, 1)
+	errs := make(chan error, 1)
+	go func() {
+		e, err := stream.Recv()
+		if err != nil {
+			errs <- err
+			return
+		}
+		received <- e
+	}()
+	at := time.Now()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	var got *contentv1.Event
+	for got == nil {
+		select {
+		case got = <-received:
+		case err := <-errs:
+			t.Fatal(err)
+		case <-ticker.C:
+			bus.Publish(events.Event{Type: events.PostCreated, CommunityID: 7, PostID: 1, UserID: 2, At: at})
+			bus.Publish(events.Event{Type: events.CommentCreated, CommunityID: 8, PostID: 1, CommentID: 3, UserID: 2, At: at})
+			bus.Publish(events.Event{Type: events.CommentCreated, CommunityID: 7, PostID: 4, CommentID: 5, UserID: 6, At: at})
+		case <-ctx.Done():
+			t.Fatal("no event received")
+		}
+	}
+
+	if got.GetType() != contentv1.EventType_EVENT_TYPE_COMMENT_CREATED || got.GetCommunityId() != 7 ||
+		got.GetPostId() != 4 || got.GetCommentId() != 5 || got.GetUserId() != 6 || !got.GetAt().AsTime().Equal(at) {
+		t.Errorf("unexpected event %v", got)
+	}
+	if got.GetComment() != nil || got.GetPost() != nil {
+		t.Errorf("content attached without include_content: %v", got)
+	}
+}
+
+func TestClientCertificateAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newCert(t, nil, nil, "test CA")
+	serverCert, serverKey := newCert(t, ca, caKey, "bufnet")
+	clientCert, clientKey := newCert(t, ca, caKey, "analytics")
+	otherCA, otherKey := newCert(t, nil, nil, "other CA")
+	strangerCert, strangerKey := newCert(t, otherCA, otherKey, "stranger")
+
+	write := func(name string, block *pem.Block) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	t.Setenv("GRPC_TLS_CERT", write("server.pem", certPEM(serverCert)))
+	t.Setenv("GRPC_TLS_KEY", write("server-key.pem", keyPEM(t, serverKey)))
+	t.Setenv("GRPC_CLIENT_CA", write("ca.pem", certPEM(ca)))
+
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	dial := func(cert *x509.Certificate, key *ecdsa.PrivateKey) contentv1.ContentServiceClient {
+		config := &tls.Config{RootCAs: roots, ServerName: "bufnet"}
+		if cert != nil {
+			config.Certificates = []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key}}
+		}
+		conn := serve(t, NewServer(nil, events.NewBus(), opts...), credentials.NewTLS(config))
+		return contentv1.NewContentServiceClient(conn)
+	}
+
+	tests := []struct {
+		name   string
+		client contentv1.ContentServiceClient
+		ok     bool
+	}{
+		{"trusted certificate", dial(clientCert, clientKey), true},
+		{"no certificate", dial(nil, nil), false},
+		{"untrusted certificate", dial(strangerCert, strangerKey), false},
+	}
+	for _, tt := range tests {
+		_, err := tt.client.GetPosts(context.Background(), tooMany())
+		if got := status.Code(err) == codes.InvalidArgument; got != tt.ok {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
+
+// newCert issues a certificate for name, signed by parent, or a
+// self-signed CA if parent is nil
+func newCert(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, name string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func certPEM(cert *x509.Certificate) *pem.Block {
+	return &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}
+}
+
+func keyPEM(t *testing.T, key *ecdsa.PrivateKey) *pem.Block {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+}
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
 )
 
@@ -109,30 +110,33 @@ func verifyAppleIDToken(idToken string) (*AppleUserInfo, error) {
 	return user, nil
 }
 
+// registerInput is a new email/password account
+type registerInput struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Avatar   string `json:"avatar"`
+}
+
 // Register handles user registration
 func (h *AuthHandler) Register(c *gin.Context) {
-	var input struct {
-		Username string `json:"username" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
-		Avatar   string `json:"avatar"`
-	}
+	var input registerInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Check if username or email already exists
 	var existingUser models.User
 	if err := h.db.Where("username = ? OR email = ?", input.Username, input.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username or email already exists"})
+		apierr.Conflict(c, "Username or email already exists")
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierr.Internal(c, "Failed to hash password")
 		return
 	}
 
@@ -145,7 +149,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	if err := h.db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		apierr.Internal(c, "Failed to create user")
 		return
 	}
 
@@ -159,43 +163,41 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Internal(c, "Failed to generate token")
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
-		"token":   tokenString, // ✅ ADD TOKEN
-		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-			"avatar":   user.Avatar,
-		},
+	c.JSON(http.StatusCreated, AuthResponse{
+		Message: "User registered successfully",
+		Token:   tokenString,
+		User:    meView(user),
 	})
 }
 
+// loginInput is an email/password login
+type loginInput struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
 // Login handles user login
 func (h *AuthHandler) Login(c *gin.Context) {
-	var input struct {
-		Email    string `json:"email" binding:"required"`
-		Password string `json:"password" binding:"required"`
-	}
+	var input loginInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	var user models.User
 	if err := h.db.Where("email = ? AND auth_provider = ?", input.Email, "email").First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		apierr.Unauthorized(c, "Invalid credentials")
 		return
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		apierr.Unauthorized(c, "Invalid credentials")
 		return
 	}
 
@@ -209,41 +211,34 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Internal(c, "Failed to generate token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   tokenString,
-		"user": gin.H{
-			"id":            user.ID,
-			"username":      user.Username,
-			"email":         user.Email,
-			"bio":           user.Bio,
-			"avatar":        user.Avatar,
-			"auth_provider": user.AuthProvider,
-		},
-	})
+	c.JSON(http.StatusOK, AuthResponse{Message: "Login successful", Token: tokenString, User: meView(user)})
+}
+
+// oauthInput carries a provider ID token; username and avatar are used
+// when the account is first created
+type oauthInput struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar"`
 }
 
 // GoogleLogin handles Google OAuth login
 func (h *AuthHandler) GoogleLogin(c *gin.Context) {
-	var input struct {
-		Token    string `json:"token" binding:"required"`
-		Username string `json:"username"`
-		Avatar   string `json:"avatar"`
-	}
+	var input oauthInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Verify Google ID token
 	googleUser, err := verifyGoogleIDToken(input.Token)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Google token"})
+		apierr.Unauthorized(c, "Invalid Google token")
 		return
 	}
 
@@ -275,11 +270,11 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 		}
 
 		if err := h.db.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+			apierr.Internal(c, "Failed to create user")
 			return
 		}
 	} else if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		apierr.Internal(c, "Database error")
 		return
 	} else {
 		// Existing user - update Google ID if not set
@@ -304,40 +299,26 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Internal(c, "Failed to generate token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-		"user": gin.H{
-			"id":            user.ID,
-			"username":      user.Username,
-			"email":         user.Email,
-			"avatar":        user.Avatar,
-			"bio":           user.Bio,
-			"auth_provider": user.AuthProvider,
-		},
-	})
+	c.JSON(http.StatusOK, AuthResponse{Message: "Login successful", Token: tokenString, User: meView(user)})
 }
 
 // AppleLogin handles Apple Sign In
 func (h *AuthHandler) AppleLogin(c *gin.Context) {
-	var input struct {
-		Token    string `json:"token" binding:"required"`
-		Username string `json:"username"`
-		Avatar   string `json:"avatar"`
-	}
+	var input oauthInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Verify Apple ID token
 	appleUser, err := verifyAppleIDToken(input.Token)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Apple token"})
+		apierr.Unauthorized(c, "Invalid Apple token")
 		return
 	}
 
@@ -364,11 +345,11 @@ func (h *AuthHandler) AppleLogin(c *gin.Context) {
 		}
 
 		if err := h.db.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+			apierr.Internal(c, "Failed to create user")
 			return
 		}
 	} else if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		apierr.Internal(c, "Database error")
 		return
 	} else {
 		// Existing user - update Apple ID if not set
@@ -393,46 +374,28 @@ func (h *AuthHandler) AppleLogin(c *gin.Context) {
 
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierr.Internal(c, "Failed to generate token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-		"user": gin.H{
-			"id":            user.ID,
-			"username":      user.Username,
-			"email":         user.Email,
-			"avatar":        user.Avatar,
-			"bio":           user.Bio,
-			"auth_provider": user.AuthProvider,
-		},
-	})
+	c.JSON(http.StatusOK, AuthResponse{Message: "Login successful", Token: tokenString, User: meView(user)})
 }
 
 // GetMe returns the current authenticated user
 func (h *AuthHandler) GetMe(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierr.Unauthorized(c, "Unauthorized")
 		return
 	}
 
 	var user models.User
 	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierr.NotFound(c, "User not found")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":            user.ID,
-		"username":      user.Username,
-		"email":         user.Email,
-		"bio":           user.Bio,
-		"avatar":        user.Avatar,
-		"auth_provider": user.AuthProvider,
-		"created_at":    user.CreatedAt,
-	})
+	c.JSON(http.StatusOK, meView(user))
 }
 
 // Helper functions
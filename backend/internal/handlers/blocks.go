@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+type BlockHandler struct {
+	db *gorm.DB
+}
+
+func NewBlockHandler(db *gorm.DB) *BlockHandler {
+	return &BlockHandler{db: db}
+}
+
+// BlockUser blocks a user. Their posts drop out of the blocker's feeds,
+// their comments are collapsed, and they can no longer follow or mention
+// the blocker. An existing follow of the blocker is removed.
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	blockerID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var target models.User
+	if err := h.db.First(&target, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "User not found")
+		return
+	}
+	if target.ID == blockerID {
+		apierr.BadRequest(c, "You cannot block yourself")
+		return
+	}
+
+	if policy.IsBlocked(h.db, blockerID, target.ID) {
+		c.JSON(http.StatusOK, gin.H{"message": "User already blocked"})
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		block := models.Block{BlockerID: blockerID, BlockedID: target.ID}
+		if err := tx.Create(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("follower_id = ? AND following_id = ?", target.ID, blockerID).Delete(&models.Follow{}).Error
+	})
+	if database.IsUniqueViolation(err) {
+		// Blocked by a concurrent request, which also removed the follow
+		c.JSON(http.StatusOK, gin.H{"message": "User already blocked"})
+		return
+	}
+	if err != nil {
+		apierr.Internal(c, "Failed to block user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+// UnblockUser lifts a block
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	blockerID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, c.Param("id")).Delete(&models.Block{}).Error; err != nil {
+		apierr.Internal(c, "Failed to unblock user")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
+
+// BlockedUser is a user the caller has blocked
+type BlockedUser struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Avatar    string    `json:"avatar"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// GetBlockedUsers lists the users the caller has blocked
+func (h *BlockHandler) GetBlockedUsers(c *gin.Context) {
+	blockerID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var blocks []models.Block
+	h.db.Where("blocker_id = ?", blockerID).Preload("Blocked").Order("created_at desc").Find(&blocks)
+
+	blocked := make([]BlockedUser, 0, len(blocks))
+	for _, block := range blocks {
+		blocked = append(blocked, BlockedUser{
+			ID:        block.Blocked.ID,
+			Username:  block.Blocked.Username,
+			Avatar:    block.Blocked.Avatar,
+			BlockedAt: block.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, blocked)
+}
+
+// HidePost removes a post from the caller's feeds
+func (h *BlockHandler) HidePost(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var post models.Post
+	if err := h.db.First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	// Hiding a post twice is not an error
+	err := h.db.Create(&models.HiddenPost{UserID: userID, PostID: post.ID}).Error
+	if err != nil && !database.IsUniqueViolation(err) {
+		apierr.Internal(c, "Failed to hide post")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post hidden"})
+}
+
+// UnhidePost returns a hidden post to the caller's feeds
+func (h *BlockHandler) UnhidePost(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierr.BadRequest(c, "Invalid post ID")
+		return
+	}
+
+	if err := h.db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.HiddenPost{}).Error; err != nil {
+		apierr.Internal(c, "Failed to unhide post")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post unhidden"})
+}
+
+// GetHiddenPosts lists the posts the caller has hidden, most recently hidden first
+func (h *BlockHandler) GetHiddenPosts(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	limit, offset := pageParams(c)
+	var posts []models.Post
+	err := h.db.Preload("User").
+		Joins("JOIN hidden_posts ON hidden_posts.post_id = posts.id AND hidden_posts.user_id = ?", userID).
+		Scopes(preloadPostKinds, policy.VisiblePosts(userID)).
+		Order("hidden_posts.created_at desc").
+		Limit(limit).Offset(offset).
+		Find(&posts).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to fetch hidden posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, postViews(h.db, posts, userID))
+}
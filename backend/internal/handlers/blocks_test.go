@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+// concurrently serves the same request attempts times at once and returns
+// the response codes
+func concurrently(attempts int, serve func() int) []int {
+	codes := make([]int, attempts)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = serve()
+		}()
+	}
+	wg.Wait()
+	return codes
+}
+
+func TestBlockUser(t *testing.T) {
+	db := testdb.New(t)
+	h := NewBlockHandler(db)
+	blocker := createUser(t, db, "blocker", models.RoleUser)
+	target := createUser(t, db, "target", models.RoleUser)
+	db.Create(&models.Follow{FollowerID: target.ID, FollowingID: blocker.ID})
+
+	path := fmt.Sprintf("/users/%d/block", target.ID)
+	codes := concurrently(8, func() int {
+		return serve(t, http.MethodPost, "/users/:id/block", path, blocker.ID, nil, h.BlockUser).Code
+	})
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("concurrent block = %d, want 200", code)
+		}
+	}
+
+	var blocks, follows int64
+	db.Model(&models.Block{}).Count(&blocks)
+	db.Model(&models.Follow{}).Count(&follows)
+	if blocks != 1 {
+		t.Errorf("%d blocks stored, want 1", blocks)
+	}
+	if follows != 0 {
+		t.Errorf("the blocked user still follows the blocker")
+	}
+
+	self := fmt.Sprintf("/users/%d/block", blocker.ID)
+	if w := serve(t, http.MethodPost, "/users/:id/block", self, blocker.ID, nil, h.BlockUser); w.Code != http.StatusBadRequest {
+		t.Errorf("blocking yourself = %d, want 400", w.Code)
+	}
+}
+
+func TestHidePost(t *testing.T) {
+	db := testdb.New(t)
+	h := NewBlockHandler(db)
+	author := createUser(t, db, "author", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	path := fmt.Sprintf("/posts/%d/hide", post.ID)
+	codes := concurrently(8, func() int {
+		return serve(t, http.MethodPost, "/posts/:id/hide", path, reader.ID, nil, h.HidePost).Code
+	})
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("concurrent hide = %d, want 200", code)
+		}
+	}
+
+	var hidden int64
+	db.Model(&models.HiddenPost{}).Count(&hidden)
+	if hidden != 1 {
+		t.Errorf("%d hidden posts stored, want 1", hidden)
+	}
+}
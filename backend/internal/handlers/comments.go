@@ -2,19 +2,30 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/automod"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
 )
 
 type CommentHandler struct {
-	db *gorm.DB
+	db         *gorm.DB
+	spamFilter *spam.Filter
+	events     *events.Bus
 }
 
-func NewCommentHandler(db *gorm.DB) *CommentHandler {
-	return &CommentHandler{db: db}
+func NewCommentHandler(db *gorm.DB, spamFilter *spam.Filter, bus *events.Bus) *CommentHandler {
+	return &CommentHandler{db: db, spamFilter: spamFilter, events: bus}
 }
 
 func extractUserID(c *gin.Context) (int, bool) {
@@ -34,98 +45,152 @@ func extractUserID(c *gin.Context) (int, bool) {
 	}
 }
 
-func (h *CommentHandler) calculateCommentVotes(commentID int) (int, int) {
-	var up, down int64
-	h.db.Model(&models.Vote{}).Where("comment_id = ? AND vote_type = ?", commentID, 1).Count(&up)
-	h.db.Model(&models.Vote{}).Where("comment_id = ? AND vote_type = ?", commentID, -1).Count(&down)
-	return int(up), int(down)
+// voteComment casts an upvote or downvote on the :commentId comment
+func (h *CommentHandler) voteComment(c *gin.Context, voteType int) {
+	voterID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+
+	vote := models.Vote{UserID: voterID, CommentID: commentID, VoteType: voteType}
+	recordVoteSource(c, &vote)
+	message, e := h.vote(vote)
+	if e != nil {
+		apierr.Write(c, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// vote casts vote on its comment after the ban checks for the comment's
+// community
+func (h *CommentHandler) vote(vote models.Vote) (string, *apierr.Error) {
+	var comment models.Comment
+	if err := h.db.First(&comment, vote.CommentID).Error; err != nil {
+		return "", apierr.New(http.StatusNotFound, apierr.CodeNotFound, "Comment not found")
+	}
+
+	var post models.Post
+	if err := h.db.Select("id", "community_id").First(&post, comment.PostID).Error; err != nil {
+		return "", apierr.New(http.StatusNotFound, apierr.CodeNotFound, "Post not found")
+	}
+	if e := participationError(h.db, vote.UserID, post.CommunityID); e != nil {
+		return "", e
+	}
+
+	message, delta, err := castVote(h.db, vote, karma.Target{Kind: karma.Comment, AuthorID: comment.AuthorID, CommunityID: post.CommunityID})
+	if err != nil {
+		return "", apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to vote")
+	}
+	announceMilestone(h.db, h.events, events.Event{CommunityID: post.CommunityID, PostID: post.ID, CommentID: comment.ID, UserID: vote.UserID}, delta)
+	return message, nil
 }
 
 // GetComments returns all comments for a post with calculated votes
 func (h *CommentHandler) GetComments(c *gin.Context) {
 	postID := c.Param("id")
+	viewerID, _ := extractUserID(c)
 	var comments []models.Comment
 
-	if err := h.db.Where("post_id = ?", postID).Preload("User").Order("created_at desc").Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+	if err := h.db.Where("post_id = ?", postID).Scopes(policy.VisibleComments(viewerID)).Preload("User").Order("created_at desc").Find(&comments).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch comments")
 		return
 	}
 
-	var responses []gin.H
-	for _, comment := range comments {
-		up, down := h.calculateCommentVotes(comment.ID)
-		responses = append(responses, gin.H{
-			"id":         comment.ID,
-			"body":       comment.Body,
-			"author_id":  comment.AuthorID,
-			"post_id":    comment.PostID,
-			"user":       comment.User,
-			"upvotes":    up,
-			"downvotes":  down,
-			"created_at": comment.CreatedAt,
-			"updated_at": comment.UpdatedAt,
-		})
-	}
-
-	if responses == nil {
-		responses = []gin.H{}
-	}
+	c.JSON(http.StatusOK, commentViews(h.db, comments, viewerID))
+}
 
-	c.JSON(http.StatusOK, responses)
+// commentInput is the body of a new or edited comment
+type commentInput struct {
+	Body string `json:"body" binding:"required"`
 }
 
 // CreateComment creates a new comment on a post
 func (h *CommentHandler) CreateComment(c *gin.Context) {
-	var input struct {
-		Body string `json:"body" binding:"required"`
-	}
+	var input commentInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
-	postID := c.Param("id")
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	authorID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	// Convert userID safely
-	var authorID int
-	switch v := userID.(type) {
-	case int:
-		authorID = v
-	case uint:
-		authorID = int(v)
-	case float64:
-		authorID = int(v)
-	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+	// Verify post exists
+	var post models.Post
+	if err := h.db.First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
 		return
 	}
 
-	// Verify post exists
-	var post models.Post
-	if err := h.db.First(&post, postID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	comment, e := h.create(post, authorID, input.Body)
+	if e != nil {
+		apierr.Write(c, e)
 		return
 	}
 
-	comment := models.Comment{
-		Body:     input.Body,
-		PostID:   post.ID,
-		AuthorID: authorID,
+	c.JSON(http.StatusCreated, commentView(h.db, comment, authorID))
+}
+
+// create adds a comment to post after the ban checks, community rules and
+// spam filter, and returns it with its author
+func (h *CommentHandler) create(post models.Post, authorID int, body string) (models.Comment, *apierr.Error) {
+	if e := participationError(h.db, authorID, post.CommunityID); e != nil {
+		return models.Comment{}, e
 	}
 
-	if err := h.db.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
-		return
+	decision, e := applyCommunityRules(h.db, post.CommunityID, authorID, automod.Content{
+		Type: automod.TypeComment,
+		Body: body,
+	})
+	if e != nil {
+		return models.Comment{}, e
+	}
+
+	comment := models.Comment{
+		Body:      body,
+		PostID:    post.ID,
+		AuthorID:  authorID,
+		ModStatus: modStatusFor(decision.Action),
+		ModReason: decision.Reason,
+	}
+	score := holdSpam(h.spamFilter, spam.Content{Body: comment.Body, AuthorID: authorID}, &comment.ModStatus, &comment.ModReason)
+	comment.ContentHash = score.Hash
+	comment.SpamScore = score.Score
+	renderComment(&comment)
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		return syncMentions(tx, models.RevisionComment, comment.ID, post.ID, authorID, "", comment.Body)
+	})
+	if err != nil {
+		return models.Comment{}, apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to create comment")
 	}
 
+	h.events.Publish(events.Event{
+		Type:        events.CommentCreated,
+		CommunityID: post.CommunityID,
+		PostID:      post.ID,
+		CommentID:   comment.ID,
+		UserID:      authorID,
+	})
+
 	h.db.Preload("User").First(&comment, comment.ID)
-	c.JSON(http.StatusCreated, comment)
+	return comment, nil
 }
 
 // UpdateComment updates a comment (owner only)
@@ -134,45 +199,84 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 
 	authorID, ok := extractUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	var input struct {
-		Body string `json:"body" binding:"required"`
-	}
+	var input commentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	var comment models.Comment
 	if err := h.db.First(&comment, commentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		apierr.NotFound(c, "Comment not found")
 		return
 	}
 
 	if comment.AuthorID != authorID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own comments"})
+		apierr.Forbidden(c, "You can only edit your own comments")
+		return
+	}
+
+	var post models.Post
+	if err := h.db.Select("id", "community_id").First(&post, comment.PostID).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+	if !checkParticipation(c, h.db, authorID, post.CommunityID) {
 		return
 	}
 
-	comment.Body = input.Body
-	h.db.Save(&comment)
+	if input.Body != comment.Body {
+		e := reviewEdit(h.db, h.spamFilter, post.CommunityID, authorID, automod.Content{
+			Type: automod.TypeComment,
+			Body: input.Body,
+		}, &comment.ModStatus, &comment.ModReason, &comment.ContentHash, &comment.SpamScore)
+		if e != nil {
+			apierr.Write(c, e)
+			return
+		}
+
+		original := models.Revision{
+			Body:      comment.Body,
+			EditorID:  comment.AuthorID,
+			CreatedAt: comment.CreatedAt,
+		}
+
+		now := time.Now()
+		comment.Body = input.Body
+		comment.EditedAt = &now
+		renderComment(&comment)
+
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&comment).Error; err != nil {
+				return err
+			}
+			if err := syncMentions(tx, models.RevisionComment, comment.ID, comment.PostID, comment.AuthorID, original.Body, comment.Body); err != nil {
+				return err
+			}
+			return recordRevision(tx, original, models.Revision{
+				ContentType: models.RevisionComment,
+				ContentID:   comment.ID,
+				Body:        comment.Body,
+				EditorID:    authorID,
+			})
+		})
+		if database.IsUniqueViolation(err) {
+			apierr.Conflict(c, "Comment was edited at the same time, try again")
+			return
+		}
+		if err != nil {
+			apierr.Internal(c, "Failed to update comment")
+			return
+		}
+	}
+
 	h.db.Preload("User").First(&comment, comment.ID)
 
-	up, down := h.calculateCommentVotes(comment.ID)
-	c.JSON(http.StatusOK, gin.H{
-		"id":         comment.ID,
-		"body":       comment.Body,
-		"author_id":  comment.AuthorID,
-		"post_id":    comment.PostID,
-		"user":       comment.User,
-		"upvotes":    up,
-		"downvotes":  down,
-		"created_at": comment.CreatedAt,
-		"updated_at": comment.UpdatedAt,
-	})
+	c.JSON(http.StatusOK, commentView(h.db, comment, authorID))
 }
 
 // DeleteComment deletes a comment and its votes (owner only)
@@ -181,26 +285,37 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 
 	authorID, ok := extractUserID(c)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	var comment models.Comment
 	if err := h.db.First(&comment, commentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		apierr.NotFound(c, "Comment not found")
 		return
 	}
 
 	if comment.AuthorID != authorID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own comments"})
+		apierr.Forbidden(c, "You can only delete your own comments")
 		return
 	}
 
-	// Clean up votes on this comment too
-	h.db.Where("comment_id = ?", comment.ID).Delete(&models.Vote{})
+	var post models.Post
+	h.db.Select("id", "community_id").Limit(1).Find(&post, comment.PostID)
 
-	if err := h.db.Delete(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+	// Clean up votes and mentions on this comment too; the karma its votes
+	// earned goes with it
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := withdrawVotes(tx, karma.Comment, post.CommunityID, map[int]int{comment.ID: comment.AuthorID}); err != nil {
+			return err
+		}
+		if err := deleteMentions(tx, models.RevisionComment, comment.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&comment).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to delete comment")
 		return
 	}
 
@@ -209,78 +324,10 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 
 // UpvoteComment — one vote per user, toggles off if same, switches if opposite
 func (h *CommentHandler) UpvoteComment(c *gin.Context) {
-	commentID := c.Param("commentId")
-
-	voterID, ok := extractUserID(c)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-
-	var comment models.Comment
-	if err := h.db.First(&comment, commentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
-		return
-	}
-
-	var existing models.Vote
-	err := h.db.Where("user_id = ? AND comment_id = ?", voterID, commentID).First(&existing).Error
-
-	if err == nil {
-		if existing.VoteType == 1 {
-			// Already upvoted — toggle off
-			h.db.Delete(&existing)
-			c.JSON(http.StatusOK, gin.H{"message": "Vote removed"})
-			return
-		}
-		// Was a downvote — switch to upvote
-		existing.VoteType = 1
-		h.db.Save(&existing)
-		c.JSON(http.StatusOK, gin.H{"message": "Vote updated"})
-		return
-	}
-
-	// No vote yet — create upvote
-	vote := models.Vote{UserID: voterID, CommentID: comment.ID, VoteType: 1}
-	h.db.Create(&vote)
-	c.JSON(http.StatusOK, gin.H{"message": "Vote recorded"})
+	h.voteComment(c, 1)
 }
 
 // DownvoteComment — one vote per user, toggles off if same, switches if opposite
 func (h *CommentHandler) DownvoteComment(c *gin.Context) {
-	commentID := c.Param("commentId")
-
-	voterID, ok := extractUserID(c)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
-
-	var comment models.Comment
-	if err := h.db.First(&comment, commentID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
-		return
-	}
-
-	var existing models.Vote
-	err := h.db.Where("user_id = ? AND comment_id = ?", voterID, commentID).First(&existing).Error
-
-	if err == nil {
-		if existing.VoteType == -1 {
-			// Already downvoted — toggle off
-			h.db.Delete(&existing)
-			c.JSON(http.StatusOK, gin.H{"message": "Vote removed"})
-			return
-		}
-		// Was an upvote — switch to downvote
-		existing.VoteType = -1
-		h.db.Save(&existing)
-		c.JSON(http.StatusOK, gin.H{"message": "Vote updated"})
-		return
-	}
-
-	// No vote yet — create downvote
-	vote := models.Vote{UserID: voterID, CommentID: comment.ID, VoteType: -1}
-	h.db.Create(&vote)
-	c.JSON(http.StatusOK, gin.H{"message": "Vote recorded"})
+	h.voteComment(c, -1)
 }
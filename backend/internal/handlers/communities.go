@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// Community names follow the r/name syntax recognised in mentions
+var communityNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,50}$`)
+
+type CommunityHandler struct {
+	db *gorm.DB
+}
+
+func NewCommunityHandler(db *gorm.DB) *CommunityHandler {
+	return &CommunityHandler{db: db}
+}
+
+// findCommunity looks up a community by case-insensitive name
+func findCommunity(db *gorm.DB, name string) (*models.Community, error) {
+	var community models.Community
+	if err := db.Where("LOWER(name) = ?", strings.ToLower(name)).First(&community).Error; err != nil {
+		return nil, err
+	}
+	return &community, nil
+}
+
+func (h *CommunityHandler) memberCount(communityID int) int64 {
+	var count int64
+	h.db.Model(&models.CommunityMember{}).Where("community_id = ?", communityID).Count(&count)
+	return count
+}
+
+func (h *CommunityHandler) communityResponse(community models.Community, viewerID int) gin.H {
+	joined := false
+	if viewerID != 0 {
+		var count int64
+		h.db.Model(&models.CommunityMember{}).Where("community_id = ? AND user_id = ?", community.ID, viewerID).Count(&count)
+		joined = count > 0
+	}
+
+	return gin.H{
+		"id":          community.ID,
+		"name":        community.Name,
+		"description": community.Description,
+		"created_by":  community.CreatedBy,
+		"created_at":  community.CreatedAt,
+		"members":     h.memberCount(community.ID),
+		"joined":      joined,
+	}
+}
+
+// GetCommunities lists communities alphabetically
+func (h *CommunityHandler) GetCommunities(c *gin.Context) {
+	limit, offset := pageParams(c)
+
+	var communities []models.Community
+	if err := h.db.Order("name asc").Limit(limit).Offset(offset).Find(&communities).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch communities")
+		return
+	}
+
+	c.JSON(http.StatusOK, communities)
+}
+
+// GetCommunity returns a community by name with its member count
+func (h *CommunityHandler) GetCommunity(c *gin.Context) {
+	viewerID, _ := extractUserID(c)
+
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.communityResponse(*community, viewerID))
+}
+
+// communityInput describes a new community
+type communityInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// CreateCommunity creates a community; the creator joins it automatically
+func (h *CommunityHandler) CreateCommunity(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input communityInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	if !communityNamePattern.MatchString(input.Name) {
+		apierr.BadRequest(c, "Community names are 3-50 letters, digits, '_' or '-'")
+		return
+	}
+	if _, err := findCommunity(h.db, input.Name); err == nil {
+		apierr.Conflict(c, "Community already exists")
+		return
+	}
+
+	community := models.Community{
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedBy:   userID,
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&community).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CommunityMember{CommunityID: community.ID, UserID: userID}).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to create community")
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.communityResponse(community, userID))
+}
+
+// JoinCommunity adds the caller to a community so its posts reach their home feed
+func (h *CommunityHandler) JoinCommunity(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.CommunityMember{}).Where("community_id = ? AND user_id = ?", community.ID, userID).Count(&count)
+	if count == 0 {
+		if err := h.db.Create(&models.CommunityMember{CommunityID: community.ID, UserID: userID}).Error; err != nil {
+			apierr.Internal(c, "Failed to join community")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, h.communityResponse(*community, userID))
+}
+
+// LeaveCommunity removes the caller from a community
+func (h *CommunityHandler) LeaveCommunity(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	if err := h.db.Where("community_id = ? AND user_id = ?", community.ID, userID).Delete(&models.CommunityMember{}).Error; err != nil {
+		apierr.Internal(c, "Failed to leave community")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.communityResponse(*community, userID))
+}
+
+// GetMyCommunities lists the communities the caller has joined
+func (h *CommunityHandler) GetMyCommunities(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var communities []models.Community
+	err := h.db.Joins("JOIN community_members ON community_members.community_id = communities.id AND community_members.user_id = ?", userID).
+		Order("communities.name asc").Find(&communities).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to fetch communities")
+		return
+	}
+
+	c.JSON(http.StatusOK, communities)
+}
+
+// TrendingCommunity is a community with the stats it trends by
+type TrendingCommunity struct {
+	Community models.Community      `json:"community"`
+	Stats     models.CommunityStats `json:"stats"`
+}
+
+// GetTrending lists communities by their aggregated trend score. Stats are
+// refreshed by a background job, so this never scans posts or comments.
+func (h *CommunityHandler) GetTrending(c *gin.Context) {
+	limit, offset := pageParams(c)
+
+	var stats []models.CommunityStats
+	if err := h.db.Where("trend_score > 0").Order("trend_score desc").Limit(limit).Offset(offset).Find(&stats).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch trending communities")
+		return
+	}
+
+	ids := make([]int, len(stats))
+	for i, s := range stats {
+		ids[i] = s.CommunityID
+	}
+	communities := make(map[int]models.Community)
+	if len(ids) > 0 {
+		var found []models.Community
+		h.db.Where("id IN ?", ids).Find(&found)
+		for _, community := range found {
+			communities[community.ID] = community
+		}
+	}
+
+	trending := make([]TrendingCommunity, 0, len(stats))
+	for _, s := range stats {
+		community, ok := communities[s.CommunityID]
+		if !ok {
+			continue
+		}
+		trending = append(trending, TrendingCommunity{Community: community, Stats: s})
+	}
+
+	c.JSON(http.StatusOK, trending)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// moderatorInput appoints a moderator
+type moderatorInput struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+// ModeratorsResponse is a community's creator and appointed moderators
+type ModeratorsResponse struct {
+	Creator    *PublicUser  `json:"creator"`
+	Moderators []PublicUser `json:"moderators"`
+}
+
+// loadOwnedCommunity resolves the :name community if the caller created it
+// or is an admin; only they appoint and remove moderators
+func (h *CommunityHandler) loadOwnedCommunity(c *gin.Context) (*models.Community, bool) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return nil, false
+	}
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return nil, false
+	}
+
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return nil, false
+	}
+	if community.CreatedBy != user.ID && user.Role != models.RoleAdmin {
+		apierr.Forbidden(c, "Only the community's creator can manage its moderators")
+		return nil, false
+	}
+	return community, true
+}
+
+// GetModerators lists a community's creator and appointed moderators
+func (h *CommunityHandler) GetModerators(c *gin.Context) {
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	var users []models.User
+	err = h.db.Joins("JOIN community_moderators ON community_moderators.user_id = users.id AND community_moderators.community_id = ?", community.ID).
+		Order("community_moderators.created_at asc").Find(&users).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to fetch moderators")
+		return
+	}
+
+	response := ModeratorsResponse{Moderators: make([]PublicUser, 0, len(users))}
+	var creator models.User
+	if h.db.First(&creator, community.CreatedBy).Error == nil {
+		view := publicUser(creator)
+		response.Creator = &view
+	}
+	for _, user := range users {
+		response.Moderators = append(response.Moderators, publicUser(user))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AddModerator appoints a user to moderate a community (its creator or
+// admins only)
+func (h *CommunityHandler) AddModerator(c *gin.Context) {
+	community, ok := h.loadOwnedCommunity(c)
+	if !ok {
+		return
+	}
+
+	var input moderatorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var target models.User
+	if err := h.db.First(&target, input.UserID).Error; err != nil {
+		apierr.NotFound(c, "User not found")
+		return
+	}
+	if target.ID == community.CreatedBy {
+		apierr.Conflict(c, "The creator already moderates this community")
+		return
+	}
+
+	callerID, _ := extractUserID(c)
+	appointment := models.CommunityModerator{CommunityID: community.ID, UserID: target.ID, AddedByID: callerID}
+	result := h.db.Where("community_id = ? AND user_id = ?", community.ID, target.ID).FirstOrCreate(&appointment)
+	if result.Error != nil {
+		apierr.Internal(c, "Failed to add moderator")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierr.Conflict(c, "User already moderates this community")
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// RemoveModerator removes a user's appointment as a community moderator
+// (its creator or admins only)
+func (h *CommunityHandler) RemoveModerator(c *gin.Context) {
+	community, ok := h.loadOwnedCommunity(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("community_id = ? AND user_id = ?", community.ID, c.Param("userId")).Delete(&models.CommunityModerator{})
+	if result.Error != nil {
+		apierr.Internal(c, "Failed to remove moderator")
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierr.NotFound(c, "User does not moderate this community")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Moderator removed"})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/automod"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+)
+
+// loadCommunityRules returns a community's posting requirements and parsed
+// automod rules. Communities without rules get empty ones.
+func loadCommunityRules(db *gorm.DB, communityID int) (automod.Requirements, *automod.RuleSet, error) {
+	var req automod.Requirements
+	var record models.CommunityRules
+	if err := db.Where("community_id = ?", communityID).Limit(1).Find(&record).Error; err != nil {
+		return req, nil, err
+	}
+
+	if record.Requirements != "" {
+		if err := json.Unmarshal([]byte(record.Requirements), &req); err != nil {
+			return req, nil, err
+		}
+	}
+	if record.Automod == "" {
+		return req, nil, nil
+	}
+	rules, err := automod.Parse([]byte(record.Automod))
+	return req, rules, err
+}
+
+// enforceCommunityRules checks content against a community's posting
+// requirements and runs its automod rules. A violated requirement is written
+// as a 422 naming the rule and reported as not ok.
+func enforceCommunityRules(c *gin.Context, db *gorm.DB, communityID, authorID int, content automod.Content) (automod.Decision, bool) {
+	decision, e := applyCommunityRules(db, communityID, authorID, content)
+	if e != nil {
+		apierr.Write(c, e)
+		return decision, false
+	}
+	return decision, true
+}
+
+// applyCommunityRules is enforceCommunityRules returning the error instead of
+// writing it
+func applyCommunityRules(db *gorm.DB, communityID, authorID int, content automod.Content) (automod.Decision, *apierr.Error) {
+	if communityID == 0 {
+		return automod.Decision{}, nil
+	}
+
+	req, rules, err := loadCommunityRules(db, communityID)
+	if err != nil {
+		return automod.Decision{}, apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to load community rules")
+	}
+
+	var author models.User
+	db.First(&author, authorID)
+	content.AuthorCreatedAt = author.CreatedAt
+	content.AuthorKarma = karma.Of(db, authorID)
+
+	now := time.Now()
+	if v := req.Check(content, now); v != nil {
+		return automod.Decision{}, apierr.New(http.StatusUnprocessableEntity, apierr.CodeRuleViolation, v.Message).With("rule", v.Rule)
+	}
+	return rules.Evaluate(content, now), nil
+}
+
+// modStatusFor maps an automod action to the moderation state of new content
+func modStatusFor(action automod.Action) string {
+	switch action {
+	case automod.ActionRemove:
+		return models.ModStatusRemoved
+	case automod.ActionRequireApproval:
+		return models.ModStatusPending
+	case automod.ActionFlag:
+		return models.ModStatusFlagged
+	default:
+		return models.ModStatusNone
+	}
+}
+
+// modSeverity orders moderation states from visible to removed
+func modSeverity(status string) int {
+	switch status {
+	case models.ModStatusFlagged:
+		return 1
+	case models.ModStatusPending:
+		return 2
+	case models.ModStatusRemoved:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// reviewEdit runs edited content through the community rules and spam
+// filter, as creating it would. An edit can hold or remove content but never
+// lifts an earlier decision. Spam is only rescored when the content's hash
+// changes, so an edit isn't counted as a repost of itself.
+func reviewEdit(db *gorm.DB, filter *spam.Filter, communityID, authorID int, content automod.Content, status, reason, hash *string, score *float64) *apierr.Error {
+	decision, e := applyCommunityRules(db, communityID, authorID, content)
+	if e != nil {
+		return e
+	}
+	if next := modStatusFor(decision.Action); modSeverity(next) > modSeverity(*status) {
+		*status = next
+		*reason = decision.Reason
+	}
+
+	text := spam.Content{Title: content.Title, Body: content.Body, URL: content.URL, AuthorID: authorID}
+	if spam.Hash(text) != *hash {
+		result := holdSpam(filter, text, status, reason)
+		*hash = result.Hash
+		*score = result.Score
+	}
+	return nil
+}
+
+// GetRules returns a community's posting requirements. Moderators also get
+// the automod rule source.
+func (h *CommunityHandler) GetRules(c *gin.Context) {
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	var record models.CommunityRules
+	h.db.Where("community_id = ?", community.ID).Limit(1).Find(&record)
+
+	var req automod.Requirements
+	if record.Requirements != "" {
+		json.Unmarshal([]byte(record.Requirements), &req)
+	}
+	response := gin.H{"requirements": req}
+
+	if viewerID, ok := extractUserID(c); ok {
+		var viewer models.User
+		if h.db.First(&viewer, viewerID).Error == nil && policy.IsModerator(h.db, viewer, *community) {
+			response["automod"] = record.Automod
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// rulesInput replaces a community's posting requirements and automod
+// rules
+type rulesInput struct {
+	Requirements automod.Requirements `json:"requirements"`
+	Automod      string               `json:"automod"`
+}
+
+// UpdateRules replaces a community's posting requirements and automod rules
+// (moderators only). automod is YAML or JSON text.
+func (h *CommunityHandler) UpdateRules(c *gin.Context) {
+	community, _, isMod, ok := h.loadCommunityModerator(c)
+	if !ok {
+		return
+	}
+	if !isMod {
+		apierr.Forbidden(c, "You do not have permission to moderate here")
+		return
+	}
+
+	var input rulesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+	if err := input.Requirements.Validate(); err != nil {
+		apierr.Invalid(c, "requirements", err.Error())
+		return
+	}
+	if input.Automod != "" {
+		if _, err := automod.Parse([]byte(input.Automod)); err != nil {
+			apierr.Invalid(c, "automod", err.Error())
+			return
+		}
+	}
+
+	encoded, err := json.Marshal(input.Requirements)
+	if err != nil {
+		apierr.Internal(c, "Failed to save rules")
+		return
+	}
+
+	record := models.CommunityRules{CommunityID: community.ID}
+	h.db.Where("community_id = ?", community.ID).Limit(1).Find(&record)
+	record.Requirements = string(encoded)
+	record.Automod = input.Automod
+	if err := h.db.Save(&record).Error; err != nil {
+		apierr.Internal(c, "Failed to save rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requirements": input.Requirements, "automod": record.Automod})
+}
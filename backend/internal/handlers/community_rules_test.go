@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestEditsAreModerated(t *testing.T) {
+	db := testdb.New(t)
+	posts := NewPostHandler(db, nil, newSpamFilter(db), nil)
+	comments := NewCommentHandler(db, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+	db.Create(&models.CommunityRules{
+		CommunityID:  gaming.ID,
+		Requirements: `{"banned_keywords": ["casino"]}`,
+		Automod:      "rules:\n  - name: giveaways\n    if:\n      keywords: [giveaway]\n    action: require_approval\n",
+	})
+
+	post := createPost(t, db, author, gaming)
+	comment, e := comments.create(post, author.ID, "A perfectly ordinary comment")
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	editPost := func(body string) int {
+		path := fmt.Sprintf("/posts/%d", post.ID)
+		return serve(t, http.MethodPut, "/posts/:id", path, author.ID, updatePostInput{Body: &body}, posts.UpdatePost).Code
+	}
+	editComment := func(body string) int {
+		path := fmt.Sprintf("/comments/%d", comment.ID)
+		return serve(t, http.MethodPut, "/comments/:commentId", path, author.ID, commentInput{Body: body}, comments.UpdateComment).Code
+	}
+	status := func(model any, id int) string {
+		var s string
+		db.Model(model).Select("mod_status").Where("id = ?", id).Scan(&s)
+		return s
+	}
+
+	if got := editPost("Visit my casino"); got != http.StatusUnprocessableEntity {
+		t.Errorf("post edit breaking a requirement: status %d, want 422", got)
+	}
+	if got := editComment("Visit my casino"); got != http.StatusUnprocessableEntity {
+		t.Errorf("comment edit breaking a requirement: status %d, want 422", got)
+	}
+
+	if got := editPost("Enter the giveaway"); got != http.StatusOK {
+		t.Fatalf("post edit: status %d", got)
+	}
+	if got := status(&models.Post{}, post.ID); got != models.ModStatusPending {
+		t.Errorf("post edited into an automod match is %q, want pending", got)
+	}
+	if got := editComment("Enter the giveaway"); got != http.StatusOK {
+		t.Fatalf("comment edit: status %d", got)
+	}
+	if got := status(&models.Comment{}, comment.ID); got != models.ModStatusPending {
+		t.Errorf("comment edited into an automod match is %q, want pending", got)
+	}
+
+	// Editing the match away doesn't release the content from review
+	if got := editPost("Nothing to see here"); got != http.StatusOK {
+		t.Fatalf("post edit: status %d", got)
+	}
+	if got := status(&models.Post{}, post.ID); got != models.ModStatusPending {
+		t.Errorf("post edited after being held is %q, want pending", got)
+	}
+
+	db.Create(&models.Ban{UserID: author.ID, CommunityID: &gaming.ID})
+	if got := editPost("Edited while banned"); got != http.StatusForbidden {
+		t.Errorf("post edit while banned: status %d, want 403", got)
+	}
+	if got := editComment("Edited while banned"); got != http.StatusForbidden {
+		t.Errorf("comment edit while banned: status %d, want 403", got)
+	}
+}
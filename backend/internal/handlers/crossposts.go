@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/automod"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// crosspostInfo holds the parent summaries and crosspost counts of a page
+// of posts
+type crosspostInfo struct {
+	parents map[int]*CrosspostParent
+	counts  map[int]int
+}
+
+// parent returns the summary of the post a crosspost was shared from, or
+// nil if the post is not a crosspost or its parent is gone
+func (info crosspostInfo) parent(post models.Post) *CrosspostParent {
+	if post.CrosspostParentID == nil {
+		return nil
+	}
+	return info.parents[*post.CrosspostParentID]
+}
+
+// loadCrosspostInfo fetches the parents of crossposts and the number of
+// communities each post has been crossposted to, in two queries
+func loadCrosspostInfo(db *gorm.DB, posts []models.Post, viewerID int) crosspostInfo {
+	info := crosspostInfo{parents: make(map[int]*CrosspostParent), counts: make(map[int]int)}
+	if len(posts) == 0 {
+		return info
+	}
+
+	var parentIDs []int
+	for _, post := range posts {
+		if post.CrosspostParentID != nil {
+			parentIDs = append(parentIDs, *post.CrosspostParentID)
+		}
+	}
+	if len(parentIDs) > 0 {
+		var parents []models.Post
+		db.Preload("User").Scopes(policy.VisiblePosts(viewerID)).Where("id IN ?", parentIDs).Find(&parents)
+		ensurePostsRendered(db, parents)
+		for _, p := range parents {
+			info.parents[p.ID] = crosspostSummary(p)
+		}
+	}
+
+	var rows []struct {
+		ParentID int
+		Count    int
+	}
+	db.Model(&models.Post{}).
+		Select("crosspost_parent_id AS parent_id, COUNT(DISTINCT community_id) AS count").
+		Where("crosspost_parent_id IN ?", postIDsOf(posts)).
+		Group("crosspost_parent_id").Scan(&rows)
+	for _, r := range rows {
+		info.counts[r.ParentID] = r.Count
+	}
+	return info
+}
+
+// crosspostSummary is the part of a parent post embedded in its crossposts
+func crosspostSummary(post models.Post) *CrosspostParent {
+	return &CrosspostParent{
+		ID:          post.ID,
+		Title:       post.Title,
+		Kind:        post.Kind,
+		Excerpt:     post.Excerpt,
+		Image:       post.Image,
+		URL:         post.URL,
+		Domain:      post.Domain,
+		Community:   post.Community,
+		CommunityID: post.CommunityID,
+		User:        publicUser(post.User),
+		CreatedAt:   post.CreatedAt,
+	}
+}
+
+// crosspostInput names the community to crosspost into and an optional
+// new title
+type crosspostInput struct {
+	CommunityID int    `json:"community_id" binding:"required"`
+	Title       string `json:"title"`
+}
+
+// Crosspost shares an existing post into another community. Crossposting a
+// crosspost shares the original, so attribution always points at the root.
+func (h *PostHandler) Crosspost(c *gin.Context) {
+	authorID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input crosspostInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var parent models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(authorID)).First(&parent, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+	if parent.CrosspostParentID != nil {
+		if err := h.db.Scopes(policy.VisiblePosts(authorID)).First(&parent, *parent.CrosspostParentID).Error; err != nil {
+			apierr.NotFound(c, "Original post not found")
+			return
+		}
+	}
+
+	var community models.Community
+	if err := h.db.First(&community, input.CommunityID).Error; err != nil {
+		apierr.BadRequest(c, "Community not found")
+		return
+	}
+	if community.ID == parent.CommunityID {
+		apierr.BadRequest(c, "Post already belongs to this community")
+		return
+	}
+
+	if !checkParticipation(c, h.db, authorID, community.ID) {
+		return
+	}
+
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		title = parent.Title
+	}
+
+	// The crosspost must satisfy the target community's rules, and pass the
+	// spam filter, as if the original were posted there
+	post, e := h.create(models.Post{
+		Title:             title,
+		Kind:              models.PostKindCrosspost,
+		AuthorID:          authorID,
+		UserID:            authorID,
+		CommunityID:       community.ID,
+		Community:         community.Name,
+		CrosspostParentID: &parent.ID,
+	}, automod.Content{
+		Type:  automod.TypePost,
+		Kind:  parent.Kind,
+		Title: title,
+		Body:  parent.Body,
+		URL:   parent.URL,
+	})
+	if e != nil {
+		apierr.Write(c, e)
+		return
+	}
+
+	c.JSON(http.StatusCreated, postView(h.db, post, authorID))
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func crosspost(t *testing.T, h *PostHandler, userID, postID int, input crosspostInput) int {
+	t.Helper()
+	path := fmt.Sprintf("/posts/%d/crosspost", postID)
+	return serve(t, http.MethodPost, "/posts/:id/crosspost", path, userID, input, h.Crosspost).Code
+}
+
+func TestCrosspost(t *testing.T) {
+	db := testdb.New(t)
+	bus := events.NewBus()
+	feed, unsubscribe := bus.Subscribe(16)
+	defer unsubscribe()
+	h := NewPostHandler(db, nil, newSpamFilter(db), bus)
+
+	author := createUser(t, db, "author", models.RoleUser)
+	sharer := createUser(t, db, "sharer", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+	cooking := createCommunity(t, db, "cooking", author)
+	original := createPost(t, db, author, gaming)
+
+	tests := []struct {
+		name   string
+		userID int
+		postID int
+		input  crosspostInput
+		want   int
+	}{
+		{"anonymous", 0, original.ID, crosspostInput{CommunityID: cooking.ID}, http.StatusUnauthorized},
+		{"same community", sharer.ID, original.ID, crosspostInput{CommunityID: gaming.ID}, http.StatusBadRequest},
+		{"missing community", sharer.ID, original.ID, crosspostInput{CommunityID: 9999}, http.StatusBadRequest},
+		{"missing post", sharer.ID, 9999, crosspostInput{CommunityID: cooking.ID}, http.StatusNotFound},
+		{"crosspost", sharer.ID, original.ID, crosspostInput{CommunityID: cooking.ID, Title: "Look at this, @author"}, http.StatusCreated},
+		{"again", author.ID, original.ID, crosspostInput{CommunityID: cooking.ID}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if got := crosspost(t, h, tt.userID, tt.postID, tt.input); got != tt.want {
+			t.Errorf("%s: crosspost = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	var shared models.Post
+	if err := db.Where("crosspost_parent_id = ?", original.ID).First(&shared).Error; err != nil {
+		t.Fatal(err)
+	}
+	if shared.CommunityID != cooking.ID || shared.Kind != models.PostKindCrosspost || shared.ContentHash == "" {
+		t.Errorf("crosspost = %+v", shared)
+	}
+	var mentions int64
+	db.Model(&models.Mention{}).Where("content_id = ? AND user_id = ?", shared.ID, author.ID).Count(&mentions)
+	if mentions != 1 {
+		t.Errorf("crosspost title recorded %d mentions, want 1", mentions)
+	}
+	if len(feed) != 1 {
+		t.Fatalf("published %d events, want 1", len(feed))
+	}
+	if e := <-feed; e.Type != events.PostCreated || e.PostID != shared.ID {
+		t.Errorf("published %+v", e)
+	}
+}
+
+func TestCrosspostsAreCheckedForSpam(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	spammer := createUser(t, db, "spammer", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+
+	original := models.Post{Title: "cheap pills", Body: "http://a.example http://b.example http://c.example",
+		UserID: spammer.ID, AuthorID: spammer.ID, CommunityID: gaming.ID}
+	db.Create(&original)
+
+	for i := 0; i < 3; i++ {
+		community := createCommunity(t, db, fmt.Sprintf("target%d", i), author)
+		if got := crosspost(t, h, spammer.ID, original.ID, crosspostInput{CommunityID: community.ID}); got != http.StatusCreated {
+			t.Fatalf("crosspost %d = %d", i, got)
+		}
+	}
+
+	var held int64
+	db.Model(&models.Post{}).Where("crosspost_parent_id = ? AND mod_status = ?", original.ID, models.ModStatusPending).Count(&held)
+	if held == 0 {
+		t.Error("no crosspost of link spam from a new account was held for review")
+	}
+}
+
+func TestConcurrentCrosspostsAreCreatedOnce(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	sharer := createUser(t, db, "sharer", models.RoleUser)
+	original := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+	cooking := createCommunity(t, db, "cooking", author)
+
+	const attempts = 8
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- crosspost(t, h, sharer.ID, original.ID, crosspostInput{CommunityID: cooking.ID})
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent crosspost = %d, want 201 or 409", code)
+		}
+	}
+	var count int64
+	db.Model(&models.Post{}).Where("crosspost_parent_id = ?", original.ID).Count(&count)
+	if created != 1 || count != 1 {
+		t.Errorf("%d crossposts created, %d stored; want 1", created, count)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/feed"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// feedQuery reads ?sort=, ?t=, ?flair=, ?limit= and ?cursor= into a feed query
+func feedQuery(c *gin.Context, defaultSort feed.Sort) (feed.Query, bool) {
+	sort, err := feed.ParseSort(c.Query("sort"), defaultSort)
+	if err != nil {
+		apierr.Invalid(c, "sort", err.Error())
+		return feed.Query{}, false
+	}
+	window, err := feed.ParseWindow(c.Query("t"))
+	if err != nil {
+		apierr.Invalid(c, "t", err.Error())
+		return feed.Query{}, false
+	}
+
+	query := feed.Query{Sort: sort, Window: window}
+	if flair := c.Query("flair"); flair != "" {
+		if query.FlairID, err = strconv.Atoi(flair); err != nil {
+			apierr.Invalid(c, "flair", "Invalid flair ID")
+			return feed.Query{}, false
+		}
+	}
+	query.Limit, _ = pageParams(c)
+	if cursor := c.Query("cursor"); cursor != "" {
+		if query.After, err = feed.DecodeCursor(cursor); err != nil {
+			apierr.Invalid(c, "cursor", "Invalid cursor")
+			return feed.Query{}, false
+		}
+	}
+	return query, true
+}
+
+// servePage runs a feed query over a source and writes one page of posts
+func (h *PostHandler) servePage(c *gin.Context, source feed.Source, query feed.Query, viewerID int, name string) {
+	var posts []models.Post
+	err := h.db.Preload("User").
+		Scopes(preloadPostKinds, source.Scope(viewerID), policy.VisiblePosts(viewerID), policy.UnmutedPosts(viewerID), query.Scope).
+		Find(&posts).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to fetch feed")
+		return
+	}
+
+	posts, next := query.Page(posts)
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       postViews(h.db, posts, viewerID),
+		"next_cursor": next,
+		"source":      name,
+	})
+}
+
+// GetHomeFeed returns posts from followed users and joined communities,
+// ranked with ?sort= (default hot) and paged with ?cursor=. Users who
+// follow nothing yet get the popular feed instead.
+func (h *PostHandler) GetHomeFeed(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	query, ok := feedQuery(c, feed.SortHot)
+	if !ok {
+		return
+	}
+
+	if !feed.HasSubscriptions(h.db, userID) {
+		h.servePage(c, feed.Popular{}, query, userID, "popular")
+		return
+	}
+	h.servePage(c, feed.Subscriptions{}, query, userID, "home")
+}
+
+// GetPopularFeed returns recent well-received posts from across the site
+func (h *PostHandler) GetPopularFeed(c *gin.Context) {
+	viewerID, _ := extractUserID(c)
+
+	query, ok := feedQuery(c, feed.SortHot)
+	if !ok {
+		return
+	}
+	h.servePage(c, feed.Popular{}, query, viewerID, "popular")
+}
+
+// GetAllFeed returns every post on the site
+func (h *PostHandler) GetAllFeed(c *gin.Context) {
+	viewerID, _ := extractUserID(c)
+
+	query, ok := feedQuery(c, feed.SortHot)
+	if !ok {
+		return
+	}
+	h.servePage(c, feed.All{}, query, viewerID, "all")
+}
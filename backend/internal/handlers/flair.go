@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+const maxFlairText = 64
+
+var flairColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type flairInput struct {
+	Text            string `json:"text"`
+	TextColor       string `json:"text_color"`
+	BackgroundColor string `json:"background_color"`
+}
+
+func (in *flairInput) validate() error {
+	in.Text = strings.TrimSpace(in.Text)
+	if len(in.Text) > maxFlairText {
+		return fmt.Errorf("flair text must be at most %d characters", maxFlairText)
+	}
+	for _, color := range []string{in.TextColor, in.BackgroundColor} {
+		if color != "" && !flairColorPattern.MatchString(color) {
+			return fmt.Errorf("colors must look like #RRGGBB")
+		}
+	}
+	return nil
+}
+
+// loadCommunityModerator resolves the :name community and the caller,
+// reporting whether the caller moderates it
+func (h *CommunityHandler) loadCommunityModerator(c *gin.Context) (*models.Community, models.User, bool, bool) {
+	var user models.User
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return nil, user, false, false
+	}
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return nil, user, false, false
+	}
+
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return nil, user, false, false
+	}
+
+	return community, user, policy.IsModerator(h.db, user, *community), true
+}
+
+// GetFlairTemplates lists a community's post flair
+func (h *CommunityHandler) GetFlairTemplates(c *gin.Context) {
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return
+	}
+
+	var templates []models.FlairTemplate
+	h.db.Where("community_id = ?", community.ID).Order("id asc").Find(&templates)
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// flairTemplateInput describes a new flair template
+type flairTemplateInput struct {
+	flairInput
+	ModOnly bool `json:"mod_only"`
+}
+
+// CreateFlairTemplate adds a post flair to a community (moderators only)
+func (h *CommunityHandler) CreateFlairTemplate(c *gin.Context) {
+	community, _, isMod, ok := h.loadCommunityModerator(c)
+	if !ok {
+		return
+	}
+	if !isMod {
+		apierr.Forbidden(c, "You do not have permission to moderate here")
+		return
+	}
+
+	var input flairTemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+	if err := input.validate(); err != nil {
+		apierr.BadRequest(c, err.Error())
+		return
+	}
+	if input.Text == "" {
+		apierr.BadRequest(c, "Flair text is required")
+		return
+	}
+
+	template := models.FlairTemplate{
+		CommunityID:     community.ID,
+		Text:            input.Text,
+		TextColor:       input.TextColor,
+		BackgroundColor: input.BackgroundColor,
+		ModOnly:         input.ModOnly,
+	}
+	if err := h.db.Create(&template).Error; err != nil {
+		apierr.Internal(c, "Failed to create flair")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// DeleteFlairTemplate removes a post flair; posts using it lose their flair
+// (moderators only)
+func (h *CommunityHandler) DeleteFlairTemplate(c *gin.Context) {
+	community, _, isMod, ok := h.loadCommunityModerator(c)
+	if !ok {
+		return
+	}
+	if !isMod {
+		apierr.Forbidden(c, "You do not have permission to moderate here")
+		return
+	}
+
+	var template models.FlairTemplate
+	if err := h.db.Where("id = ? AND community_id = ?", c.Param("flairId"), community.ID).First(&template).Error; err != nil {
+		apierr.NotFound(c, "Flair not found")
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Post{}).Where("flair_id = ?", template.ID).Update("flair_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to delete flair")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Flair deleted successfully"})
+}
+
+// userFlairInput sets a user's flair; moderators may set it for another
+// user_id
+type userFlairInput struct {
+	flairInput
+	UserID int `json:"user_id"`
+}
+
+// SetUserFlair sets the caller's flair in a community. Moderators may set
+// another member's flair with user_id. Empty text removes the flair.
+func (h *CommunityHandler) SetUserFlair(c *gin.Context) {
+	community, user, isMod, ok := h.loadCommunityModerator(c)
+	if !ok {
+		return
+	}
+
+	var input userFlairInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+	if err := input.validate(); err != nil {
+		apierr.BadRequest(c, err.Error())
+		return
+	}
+
+	targetID := user.ID
+	if input.UserID != 0 && input.UserID != user.ID {
+		if !isMod {
+			apierr.Forbidden(c, "Only moderators can set other users' flair")
+			return
+		}
+		var target models.User
+		if err := h.db.Select("id").First(&target, input.UserID).Error; err != nil {
+			apierr.NotFound(c, "User not found")
+			return
+		}
+		targetID = target.ID
+	}
+
+	if input.Text == "" {
+		if err := h.db.Where("community_id = ? AND user_id = ?", community.ID, targetID).Delete(&models.UserFlair{}).Error; err != nil {
+			apierr.Internal(c, "Failed to remove flair")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Flair removed"})
+		return
+	}
+
+	flair := models.UserFlair{CommunityID: community.ID, UserID: targetID}
+	h.db.Where(&flair).First(&flair)
+	flair.Text = input.Text
+	flair.TextColor = input.TextColor
+	flair.BackgroundColor = input.BackgroundColor
+	if err := h.db.Save(&flair).Error; err != nil {
+		apierr.Internal(c, "Failed to set flair")
+		return
+	}
+
+	c.JSON(http.StatusOK, flair)
+}
+
+// pickPostFlair validates a flair chosen for a new post in a community
+func pickPostFlair(db *gorm.DB, author models.User, community *models.Community, flairID *int) error {
+	if flairID == nil {
+		return nil
+	}
+	if community == nil {
+		return errors.New("flair can only be used on community posts")
+	}
+
+	var template models.FlairTemplate
+	if err := db.Where("id = ? AND community_id = ?", *flairID, community.ID).First(&template).Error; err != nil {
+		return errors.New("flair not found in this community")
+	}
+	if template.ModOnly && !policy.IsModerator(db, author, *community) {
+		return errors.New("this flair is reserved for moderators")
+	}
+	return nil
+}
+
+type flairKey struct{ communityID, userID int }
+
+// loadUserFlair fetches the community flair of post or comment authors in
+// one query
+func loadUserFlair(db *gorm.DB, keys []flairKey) map[flairKey]*models.UserFlair {
+	flair := make(map[flairKey]*models.UserFlair)
+
+	communityIDs := make(map[int]bool)
+	var userIDs []int
+	for _, k := range keys {
+		if k.communityID != 0 {
+			communityIDs[k.communityID] = true
+			userIDs = append(userIDs, k.userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return flair
+	}
+	ids := make([]int, 0, len(communityIDs))
+	for id := range communityIDs {
+		ids = append(ids, id)
+	}
+
+	var found []models.UserFlair
+	db.Where("community_id IN ? AND user_id IN ?", ids, userIDs).Find(&found)
+	for i := range found {
+		flair[flairKey{found[i].CommunityID, found[i].UserID}] = &found[i]
+	}
+	return flair
+}
+
+func postFlairKeys(posts []models.Post) []flairKey {
+	keys := make([]flairKey, len(posts))
+	for i, p := range posts {
+		keys[i] = flairKey{p.CommunityID, p.UserID}
+	}
+	return keys
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestFlairTemplates(t *testing.T) {
+	db := testdb.New(t)
+	h := NewCommunityHandler(db)
+	creator := createUser(t, db, "creator", models.RoleUser)
+	member := createUser(t, db, "member", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", creator)
+
+	create := func(userID int, body flairTemplateInput) *httptest.ResponseRecorder {
+		return serve(t, http.MethodPost, "/communities/:name/flair", "/communities/gaming/flair", userID, body, h.CreateFlairTemplate)
+	}
+	news := flairTemplateInput{flairInput: flairInput{Text: " News ", BackgroundColor: "#ff4500"}}
+
+	tests := []struct {
+		name   string
+		userID int
+		body   flairTemplateInput
+		want   int
+	}{
+		{"anonymous", 0, news, http.StatusUnauthorized},
+		{"member", member.ID, news, http.StatusForbidden},
+		{"blank text", creator.ID, flairTemplateInput{flairInput: flairInput{Text: "  "}}, http.StatusBadRequest},
+		{"bad color", creator.ID, flairTemplateInput{flairInput: flairInput{Text: "News", TextColor: "red"}}, http.StatusBadRequest},
+		{"moderator", creator.ID, news, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		if w := create(tt.userID, tt.body); w.Code != tt.want {
+			t.Errorf("%s: create = %d %s, want %d", tt.name, w.Code, w.Body, tt.want)
+		}
+	}
+
+	w := serve(t, http.MethodGet, "/communities/:name/flair", "/communities/gaming/flair", 0, nil, h.GetFlairTemplates)
+	var templates []models.FlairTemplate
+	if err := json.Unmarshal(w.Body.Bytes(), &templates); err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Text != "News" || templates[0].CommunityID != gaming.ID {
+		t.Fatalf("templates = %+v", templates)
+	}
+
+	post := createPost(t, db, member, gaming)
+	db.Model(&post).Update("flair_id", templates[0].ID)
+	path := fmt.Sprintf("/communities/gaming/flair/%d", templates[0].ID)
+	if w := serve(t, http.MethodDelete, "/communities/:name/flair/:flairId", path, member.ID, nil, h.DeleteFlairTemplate); w.Code != http.StatusForbidden {
+		t.Errorf("member delete = %d, want 403", w.Code)
+	}
+	if w := serve(t, http.MethodDelete, "/communities/:name/flair/:flairId", path, creator.ID, nil, h.DeleteFlairTemplate); w.Code != http.StatusOK {
+		t.Fatalf("moderator delete = %d", w.Code)
+	}
+	db.First(&post, post.ID)
+	if post.FlairID != nil {
+		t.Errorf("post kept deleted flair %d", *post.FlairID)
+	}
+}
+
+func TestSetUserFlair(t *testing.T) {
+	db := testdb.New(t)
+	h := NewCommunityHandler(db)
+	creator := createUser(t, db, "creator", models.RoleUser)
+	member := createUser(t, db, "member", models.RoleUser)
+	other := createUser(t, db, "other", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", creator)
+
+	set := func(userID int, body userFlairInput) int {
+		return serve(t, http.MethodPut, "/communities/:name/user-flair", "/communities/gaming/user-flair", userID, body, h.SetUserFlair).Code
+	}
+	flairOf := func(userID int) string {
+		var flair models.UserFlair
+		db.Where("community_id = ? AND user_id = ?", gaming.ID, userID).Limit(1).Find(&flair)
+		return flair.Text
+	}
+
+	tests := []struct {
+		name   string
+		userID int
+		body   userFlairInput
+		want   int
+	}{
+		{"own flair", member.ID, userFlairInput{flairInput: flairInput{Text: "Veteran"}}, http.StatusOK},
+		{"own flair again", member.ID, userFlairInput{flairInput: flairInput{Text: "Old timer"}}, http.StatusOK},
+		{"member sets another's flair", member.ID, userFlairInput{flairInput: flairInput{Text: "Noob"}, UserID: other.ID}, http.StatusForbidden},
+		{"moderator sets another's flair", creator.ID, userFlairInput{flairInput: flairInput{Text: "Helper"}, UserID: other.ID}, http.StatusOK},
+		{"missing user", creator.ID, userFlairInput{flairInput: flairInput{Text: "Ghost"}, UserID: 9999}, http.StatusNotFound},
+		{"bad color", member.ID, userFlairInput{flairInput: flairInput{Text: "x", TextColor: "#12"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if got := set(tt.userID, tt.body); got != tt.want {
+			t.Errorf("%s: set = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if got := flairOf(member.ID); got != "Old timer" {
+		t.Errorf("member flair = %q", got)
+	}
+	if got := flairOf(other.ID); got != "Helper" {
+		t.Errorf("other flair = %q", got)
+	}
+	var count int64
+	db.Model(&models.UserFlair{}).Where("user_id = ?", 9999).Count(&count)
+	if count != 0 {
+		t.Error("flair was stored for a missing user")
+	}
+
+	if got := set(member.ID, userFlairInput{}); got != http.StatusOK || flairOf(member.ID) != "" {
+		t.Errorf("removing flair = %d, flair %q", got, flairOf(member.ID))
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+)
+
+// sseKeepalive is how often an idle subscription stream sends a comment so
+// that proxies keep the connection open
+const sseKeepalive = 15 * time.Second
+
+// GraphQLHandler serves the GraphQL API: queries over posts, comments, users
+// and communities, mutations for voting and commenting, and a subscription
+// for new comments streamed as server-sent events
+type GraphQLHandler struct {
+	db       *gorm.DB
+	posts    *PostHandler
+	comments *CommentHandler
+	events   *events.Bus
+	limits   queryLimits
+	schema   graphql.Schema
+}
+
+func NewGraphQLHandler(db *gorm.DB, posts *PostHandler, comments *CommentHandler, bus *events.Bus) *GraphQLHandler {
+	h := &GraphQLHandler{
+		db:       db,
+		posts:    posts,
+		comments: comments,
+		events:   bus,
+		limits:   queryLimits{MaxDepth: defaultMaxQueryDepth, MaxComplexity: defaultMaxQueryComplexity},
+	}
+	if n, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_DEPTH")); err == nil && n > 0 {
+		h.limits.MaxDepth = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("GRAPHQL_MAX_COMPLEXITY")); err == nil && n > 0 {
+		h.limits.MaxComplexity = n
+	}
+
+	schema, err := h.buildSchema()
+	if err != nil {
+		log.Fatalf("Invalid GraphQL schema: %v", err)
+	}
+	h.schema = schema
+	return h
+}
+
+// graphqlRequest is the body of a GraphQL request
+type graphqlRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// gqlRequest is the state shared by the resolvers of one operation
+type gqlRequest struct {
+	gin      *gin.Context
+	viewerID int
+	loaders  *gqlLoaders
+}
+
+type gqlRequestKey struct{}
+
+// requestOf returns the state of the operation being resolved.
+// Subscription events carry their own, so every event is resolved afresh.
+func requestOf(p graphql.ResolveParams) *gqlRequest {
+	if event, ok := p.Info.RootValue.(commentEvent); ok {
+		return event.req
+	}
+	req, _ := p.Context.Value(gqlRequestKey{}).(*gqlRequest)
+	return req
+}
+
+// graphqlError carries an API error's code, and any extra context such as a
+// ban, into the extensions of a GraphQL error
+type graphqlError struct {
+	err *apierr.Error
+}
+
+func (e graphqlError) Error() string { return e.err.Message }
+
+func (e graphqlError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.err.Code}
+	for k, v := range e.err.Extra {
+		ext[k] = v
+	}
+	return ext
+}
+
+// Serve runs a GraphQL operation. Subscriptions stream their results as
+// server-sent events, so they must be requested with
+// "Accept: text/event-stream".
+func (h *GraphQLHandler) Serve(c *gin.Context) {
+	var input graphqlRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	doc, op, errs := h.prepare(input)
+	if errs != nil {
+		c.JSON(http.StatusBadRequest, &graphql.Result{Errors: errs})
+		return
+	}
+
+	viewerID, _ := extractUserID(c)
+	req := &gqlRequest{gin: c, viewerID: viewerID, loaders: newGQLLoaders(h.db, viewerID)}
+	params := graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: input.OperationName,
+		Args:          input.Variables,
+		Context:       context.WithValue(c.Request.Context(), gqlRequestKey{}, req),
+	}
+
+	if op.Operation != ast.OperationTypeSubscription {
+		c.JSON(http.StatusOK, graphql.Execute(params))
+		return
+	}
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		err := fmt.Errorf("subscriptions are streamed as server-sent events; send Accept: text/event-stream")
+		c.JSON(http.StatusNotAcceptable, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	h.stream(c, graphql.ExecuteSubscription(params))
+}
+
+// prepare parses and validates a request and checks the operation it runs
+// against the depth and complexity limits
+func (h *GraphQLHandler) prepare(input graphqlRequest) (*ast.Document, *ast.OperationDefinition, []gqlerrors.FormattedError) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(input.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, nil, gqlerrors.FormatErrors(err)
+	}
+
+	if result := graphql.ValidateDocument(&h.schema, doc, nil); !result.IsValid {
+		return nil, nil, result.Errors
+	}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		candidate, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if input.OperationName == "" && op != nil {
+			return nil, nil, gqlerrors.FormatErrors(fmt.Errorf("operationName is required when the document has several operations"))
+		}
+		if input.OperationName == "" || (candidate.Name != nil && candidate.Name.Value == input.OperationName) {
+			op = candidate
+		}
+	}
+	if op == nil {
+		return nil, nil, gqlerrors.FormatErrors(fmt.Errorf("unknown operation %q", input.OperationName))
+	}
+
+	if err := h.limits.check(&h.schema, doc, op, input.Variables); err != nil {
+		return nil, nil, []gqlerrors.FormattedError{{Message: err.Error(), Extensions: err.Extensions()}}
+	}
+	return doc, op, nil
+}
+
+// stream writes each subscription result as a "next" event and a final
+// "complete" event, following the graphql-sse protocol
+func (h *GraphQLHandler) stream(c *gin.Context, results chan *graphql.Result) {
+	// Let the executor finish whenever the client goes away
+	defer func() {
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+
+	// Streams outlive the server's write timeout
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(sseKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+		case result, ok := <-results:
+			if !ok {
+				fmt.Fprint(c.Writer, "event: complete\ndata:\n\n")
+				c.Writer.Flush()
+				return
+			}
+			data, err := json.Marshal(result)
+			if err != nil {
+				log.Printf("failed to encode subscription result: %v", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "event: next\ndata: %s\n\n", data)
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	defaultMaxQueryDepth      = 10
+	defaultMaxQueryComplexity = 5000
+)
+
+// queryLimits bound the work a single GraphQL operation may ask for. Depth
+// counts nested selections. Complexity counts fields, with the selections
+// of a list field counted once per item it may return, as given by its
+// limit argument.
+type queryLimits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// limitError reports an operation over the limits
+type limitError struct {
+	code    string
+	message string
+}
+
+func (e *limitError) Error() string { return e.message }
+
+func (e *limitError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// check measures op and reports whether it is over the limits
+func (l queryLimits) check(schema *graphql.Schema, doc *ast.Document, op *ast.OperationDefinition, vars map[string]interface{}) *limitError {
+	w := limitWalker{schema: schema, vars: vars, fragments: make(map[string]*ast.FragmentDefinition), visiting: make(map[string]bool)}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			w.fragments[frag.Name.Value] = frag
+		}
+	}
+
+	depth, cost := w.selectionSet(op.SelectionSet, rootType(schema, op))
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &limitError{"query_too_deep", fmt.Sprintf("Query depth %d exceeds the limit of %d", depth, l.MaxDepth)}
+	}
+	if l.MaxComplexity > 0 && cost > l.MaxComplexity {
+		return &limitError{"query_too_complex", fmt.Sprintf("Query complexity %d exceeds the limit of %d", cost, l.MaxComplexity)}
+	}
+	return nil
+}
+
+func rootType(schema *graphql.Schema, op *ast.OperationDefinition) graphql.Type {
+	switch op.Operation {
+	case ast.OperationTypeMutation:
+		return schema.MutationType()
+	case ast.OperationTypeSubscription:
+		return schema.SubscriptionType()
+	default:
+		return schema.QueryType()
+	}
+}
+
+type limitWalker struct {
+	schema    *graphql.Schema
+	vars      map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	// visiting guards against fragments that spread themselves
+	visiting map[string]bool
+}
+
+// selectionSet returns the depth and complexity of the selections made on a
+// value of type parent
+func (w *limitWalker) selectionSet(set *ast.SelectionSet, parent graphql.Type) (depth, cost int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			// Introspection is answered from the schema and costs nothing
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			def := fieldDef(parent, s.Name.Value)
+			var child graphql.Type
+			if def != nil {
+				child, _ = graphql.GetNamed(def.Type).(graphql.Type)
+			}
+			d, c = w.selectionSet(s.SelectionSet, child)
+			d, c = d+1, 1+w.listSize(s, def)*c
+		case *ast.InlineFragment:
+			d, c = w.selectionSet(s.SelectionSet, w.typeCondition(s.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			frag := w.fragments[s.Name.Value]
+			if frag == nil || w.visiting[frag.Name.Value] {
+				continue
+			}
+			w.visiting[frag.Name.Value] = true
+			d, c = w.selectionSet(frag.SelectionSet, w.typeCondition(frag.TypeCondition, parent))
+			delete(w.visiting, frag.Name.Value)
+		}
+		depth = max(depth, d)
+		cost += c
+	}
+	return depth, cost
+}
+
+func (w *limitWalker) typeCondition(cond *ast.Named, parent graphql.Type) graphql.Type {
+	if cond == nil {
+		return parent
+	}
+	return w.schema.Type(cond.Name.Value)
+}
+
+func fieldDef(parent graphql.Type, name string) *graphql.FieldDefinition {
+	if obj, ok := parent.(*graphql.Object); ok {
+		return obj.Fields()[name]
+	}
+	return nil
+}
+
+// listSize is how many items a field may return: its limit argument, or
+// that argument's default, capped at the largest page. Fields without a
+// limit return one.
+func (w *limitWalker) listSize(field *ast.Field, def *graphql.FieldDefinition) int {
+	if def == nil {
+		return 1
+	}
+	var arg *graphql.Argument
+	for _, a := range def.Args {
+		if a.Name() == "limit" {
+			arg = a
+		}
+	}
+	if arg == nil {
+		return 1
+	}
+
+	size := defaultPageLimit
+	if n, ok := arg.DefaultValue.(int); ok {
+		size = n
+	}
+	for _, a := range field.Arguments {
+		if a.Name.Value != "limit" {
+			continue
+		}
+		switch v := a.Value.(type) {
+		case *ast.IntValue:
+			size, _ = strconv.Atoi(v.Value)
+		case *ast.Variable:
+			switch n := w.vars[v.Name.Value].(type) {
+			case float64:
+				size = int(n)
+			case int:
+				size = n
+			}
+		}
+	}
+	return min(max(size, 1), maxPageLimit)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/dataloader"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// communityStats is a community's member count and whether the viewer joined
+type communityStats struct {
+	Members int64
+	Joined  bool
+}
+
+// privacyInfo is what the privacy rules need to know about a user
+type privacyInfo struct {
+	settings models.PrivacySettings
+	audience policy.Audience
+}
+
+// gqlLoaders batch the lookups of one GraphQL request, so that a list of
+// posts costs the same few queries whether it has one post or a hundred
+type gqlLoaders struct {
+	db       *gorm.DB
+	viewerID int
+
+	posts       *dataloader.Loader[int, *PostView]
+	communities *dataloader.Loader[int, *models.Community]
+	stats       *dataloader.Loader[int, communityStats]
+	privacy     *dataloader.Loader[int, privacyInfo]
+
+	// Nested lists take a limit, so there is one loader per field and limit
+	mu    sync.Mutex
+	lists map[string]any
+}
+
+func newGQLLoaders(db *gorm.DB, viewerID int) *gqlLoaders {
+	l := &gqlLoaders{db: db, viewerID: viewerID, lists: make(map[string]any)}
+
+	l.posts = dataloader.New(func(ids []int) (map[int]*PostView, error) {
+		views, err := LoadPosts(db, ids, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[int]*PostView, len(views))
+		for _, view := range views {
+			out[view.ID] = &view
+		}
+		return out, nil
+	})
+
+	l.communities = dataloader.New(func(ids []int) (map[int]*models.Community, error) {
+		var communities []models.Community
+		if err := db.Where("id IN ?", ids).Find(&communities).Error; err != nil {
+			return nil, err
+		}
+		out := make(map[int]*models.Community, len(communities))
+		for i := range communities {
+			out[communities[i].ID] = &communities[i]
+		}
+		return out, nil
+	})
+
+	l.stats = dataloader.New(func(ids []int) (map[int]communityStats, error) {
+		var rows []struct {
+			CommunityID int
+			Members     int64
+			Joined      bool
+		}
+		err := db.Model(&models.CommunityMember{}).
+			Select("community_id, COUNT(*) AS members, BOOL_OR(user_id = ?) AS joined", viewerID).
+			Where("community_id IN ?", ids).Group("community_id").Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[int]communityStats, len(rows))
+		for _, row := range rows {
+			out[row.CommunityID] = communityStats{Members: row.Members, Joined: row.Joined}
+		}
+		return out, nil
+	})
+
+	l.privacy = dataloader.New(func(ids []int) (map[int]privacyInfo, error) {
+		settings, err := policy.LoadPrivacies(db, ids)
+		if err != nil {
+			return nil, err
+		}
+		audiences, err := policy.AudiencesOf(db, viewerID, ids)
+		if err != nil {
+			return nil, err
+		}
+		out := make(map[int]privacyInfo, len(ids))
+		for _, id := range ids {
+			out[id] = privacyInfo{settings: settings[id], audience: audiences[id]}
+		}
+		return out, nil
+	})
+
+	return l
+}
+
+// listLoader returns the loader for one nested list field and limit,
+// creating it on first use
+func listLoader[V any](l *gqlLoaders, field string, limit int, fetch dataloader.BatchFunc[int, V]) *dataloader.Loader[int, V] {
+	key := fmt.Sprintf("%s:%d", field, limit)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if loader, ok := l.lists[key].(*dataloader.Loader[int, V]); ok {
+		return loader
+	}
+	loader := dataloader.New(fetch)
+	l.lists[key] = loader
+	return loader
+}
+
+// topPerGroup returns, for each group, the value column of up to limit rows
+// of table, newest first. It runs one windowed query for all groups.
+func topPerGroup(db *gorm.DB, table, group, value string, groups []int, limit int, scopes ...func(*gorm.DB) *gorm.DB) (map[int][]int, error) {
+	ranked := db.Table(table).Scopes(scopes...).
+		Select(fmt.Sprintf("%[1]s.%[3]s AS value, %[1]s.%[2]s AS grp, ROW_NUMBER() OVER (PARTITION BY %[1]s.%[2]s ORDER BY %[1]s.created_at DESC, %[1]s.id DESC) AS rn", table, group, value)).
+		Where(fmt.Sprintf("%s.%s IN ?", table, group), groups)
+
+	var rows []struct{ Value, Grp int }
+	if err := db.Table("(?) AS ranked", ranked).Select("value, grp").Where("rn <= ?", limit).Order("grp, rn").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int][]int)
+	for _, row := range rows {
+		out[row.Grp] = append(out[row.Grp], row.Value)
+	}
+	return out, nil
+}
+
+// postsBy loads the newest posts of each user or community
+func (l *gqlLoaders) postsBy(column string, limit int) *dataloader.Loader[int, []PostView] {
+	return listLoader(l, "posts."+column, limit, func(groups []int) (map[int][]PostView, error) {
+		ids, err := topPerGroup(l.db, "posts", column, "id", groups, limit, policy.VisiblePosts(l.viewerID))
+		if err != nil {
+			return nil, err
+		}
+		var all []int
+		for _, group := range ids {
+			all = append(all, group...)
+		}
+		posts, err := LoadPosts(l.db, all, l.viewerID)
+		if err != nil {
+			return nil, err
+		}
+		views := make(map[int]PostView, len(posts))
+		for _, view := range posts {
+			views[view.ID] = view
+		}
+		out := make(map[int][]PostView, len(groups))
+		for _, group := range groups {
+			out[group] = []PostView{}
+			for _, id := range ids[group] {
+				if view, ok := views[id]; ok {
+					out[group] = append(out[group], view)
+				}
+			}
+		}
+		return out, nil
+	})
+}
+
+// commentsOn loads the newest comments of each post
+func (l *gqlLoaders) commentsOn(limit int) *dataloader.Loader[int, []CommentView] {
+	return listLoader(l, "comments", limit, func(postIDs []int) (map[int][]CommentView, error) {
+		ids, err := topPerGroup(l.db, "comments", "post_id", "id", postIDs, limit, policy.VisibleComments(l.viewerID))
+		if err != nil {
+			return nil, err
+		}
+		var all []int
+		for _, group := range ids {
+			all = append(all, group...)
+		}
+		comments, err := LoadComments(l.db, all, l.viewerID)
+		if err != nil {
+			return nil, err
+		}
+		views := make(map[int]CommentView, len(comments))
+		for _, view := range comments {
+			views[view.ID] = view
+		}
+		out := make(map[int][]CommentView, len(postIDs))
+		for _, postID := range postIDs {
+			out[postID] = []CommentView{}
+			for _, id := range ids[postID] {
+				if view, ok := views[id]; ok {
+					out[postID] = append(out[postID], view)
+				}
+			}
+		}
+		return out, nil
+	})
+}
+
+// follows loads the newest followers (group "following_id") or followed
+// users (group "follower_id") of each user
+func (l *gqlLoaders) follows(group string, limit int) *dataloader.Loader[int, []PublicUser] {
+	value := "follower_id"
+	if group == "follower_id" {
+		value = "following_id"
+	}
+	return listLoader(l, "follows."+group, limit, func(userIDs []int) (map[int][]PublicUser, error) {
+		ids, err := topPerGroup(l.db, "follows", group, value, userIDs, limit)
+		if err != nil {
+			return nil, err
+		}
+		var all []int
+		for _, group := range ids {
+			all = append(all, group...)
+		}
+		users, err := LoadUsers(l.db, all, nil)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int]PublicUser, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+		out := make(map[int][]PublicUser, len(userIDs))
+		for _, userID := range userIDs {
+			out[userID] = []PublicUser{}
+			for _, id := range ids[userID] {
+				if u, ok := byID[id]; ok {
+					out[userID] = append(out[userID], u)
+				}
+			}
+		}
+		return out, nil
+	})
+}
+
+// guardedList resolves a user's posts or follows if the privacy rule lets
+// the viewer see them. The privacy settings and the list are both queued
+// before either is resolved, so a list of users still costs one batch each.
+func guardedList[V any](l *gqlLoaders, ownerID int, rule func(models.PrivacySettings, policy.Audience) bool, message string, list func() (V, error)) func() (interface{}, error) {
+	privacy := l.privacy.Load(ownerID)
+	return func() (interface{}, error) {
+		info, err := privacy()
+		if err != nil {
+			return nil, err
+		}
+		if !rule(info.settings, info.audience) {
+			return nil, graphqlError{apierr.New(http.StatusForbidden, apierr.CodeForbidden, message)}
+		}
+		return list()
+	}
+}
@@ -0,0 +1,574 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/feed"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// Nested lists are shorter by default than top-level ones
+const nestedListLimit = 10
+
+// commentEvent is the payload of a commentAdded subscription event
+type commentEvent struct {
+	comment CommentView
+	req     *gqlRequest
+}
+
+// fieldOf resolves a field from a source value of type T
+func fieldOf[T any](t graphql.Output, resolve func(T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: t,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return resolve(p.Source.(T)), nil
+		},
+	}
+}
+
+func limitArg(def int) *graphql.ArgumentConfig {
+	return &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: def}
+}
+
+// limitOf returns the limit argument capped at the largest page
+func limitOf(p graphql.ResolveParams) int {
+	limit, _ := p.Args["limit"].(int)
+	return min(max(limit, 1), maxPageLimit)
+}
+
+// idArg parses an ID argument
+func idArg(p graphql.ResolveParams, name string) (int, error) {
+	s, _ := p.Args[name].(string)
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, graphqlError{apierr.New(http.StatusBadRequest, apierr.CodeBadRequest, "Invalid "+name)}
+	}
+	return id, nil
+}
+
+// postThunk resolves a post through the request's loader
+func postThunk(req *gqlRequest, id int) func() (interface{}, error) {
+	load := req.loaders.posts.Load(id)
+	return func() (interface{}, error) {
+		view, err := load()
+		if err != nil || view == nil {
+			return nil, err
+		}
+		return *view, nil
+	}
+}
+
+func listThunk[V any](load func() (V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) { return load() }
+}
+
+// requireViewer returns the authenticated caller
+func requireViewer(req *gqlRequest) (int, error) {
+	if req.viewerID == 0 {
+		return 0, graphqlError{apierr.New(http.StatusUnauthorized, apierr.CodeUnauthorized, "User not authenticated")}
+	}
+	return req.viewerID, nil
+}
+
+func (h *GraphQLHandler) buildSchema() (graphql.Schema, error) {
+	voteDirection := graphql.NewEnum(graphql.EnumConfig{
+		Name: "VoteDirection",
+		Values: graphql.EnumValueConfigMap{
+			"UP":   {Value: 1},
+			"DOWN": {Value: -1},
+		},
+	})
+	postSort := graphql.NewEnum(graphql.EnumConfig{
+		Name: "PostSort",
+		Values: graphql.EnumValueConfigMap{
+			"HOT": {Value: feed.SortHot},
+			"NEW": {Value: feed.SortNew},
+			"TOP": {Value: feed.SortTop},
+		},
+	})
+
+	var userType, postType, commentType, communityType *graphql.Object
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        fieldOf(graphql.NewNonNull(graphql.ID), func(u PublicUser) interface{} { return u.ID }),
+				"username":  fieldOf(graphql.NewNonNull(graphql.String), func(u PublicUser) interface{} { return u.Username }),
+				"bio":       fieldOf(graphql.NewNonNull(graphql.String), func(u PublicUser) interface{} { return u.Bio }),
+				"avatar":    fieldOf(graphql.NewNonNull(graphql.String), func(u PublicUser) interface{} { return u.Avatar }),
+				"role":      fieldOf(graphql.NewNonNull(graphql.String), func(u PublicUser) interface{} { return u.Role }),
+				"createdAt": fieldOf(graphql.NewNonNull(graphql.DateTime), func(u PublicUser) interface{} { return u.CreatedAt }),
+				"posts": {
+					Type:        graphql.NewList(graphql.NewNonNull(postType)),
+					Description: "Newest posts first; null with an error if the profile is private",
+					Args:        graphql.FieldConfigArgument{"limit": limitArg(nestedListLimit)},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						req, user := requestOf(p), p.Source.(PublicUser)
+						posts := req.loaders.postsBy("user_id", limitOf(p)).Load(user.ID)
+						return guardedList(req.loaders, user.ID, policy.CanViewProfile, "This profile is private", posts), nil
+					},
+				},
+				"followers": {
+					Type:        graphql.NewList(graphql.NewNonNull(userType)),
+					Description: "Newest first; null with an error if the user hides them",
+					Args:        graphql.FieldConfigArgument{"limit": limitArg(defaultPageLimit)},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						req, user := requestOf(p), p.Source.(PublicUser)
+						followers := req.loaders.follows("following_id", limitOf(p)).Load(user.ID)
+						return guardedList(req.loaders, user.ID, policy.CanViewFollowers, "This user's followers are private", followers), nil
+					},
+				},
+				"following": {
+					Type:        graphql.NewList(graphql.NewNonNull(userType)),
+					Description: "Newest first; null with an error if the user hides them",
+					Args:        graphql.FieldConfigArgument{"limit": limitArg(defaultPageLimit)},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						req, user := requestOf(p), p.Source.(PublicUser)
+						following := req.loaders.follows("follower_id", limitOf(p)).Load(user.ID)
+						return guardedList(req.loaders, user.ID, policy.CanViewFollowers, "This user's follows are private", following), nil
+					},
+				},
+			}
+		}),
+	})
+
+	communityType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Community",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			stats := func(p graphql.ResolveParams, get func(communityStats) interface{}) (interface{}, error) {
+				load := requestOf(p).loaders.stats.Load(p.Source.(models.Community).ID)
+				return func() (interface{}, error) {
+					s, err := load()
+					return get(s), err
+				}, nil
+			}
+			return graphql.Fields{
+				"id":          fieldOf(graphql.NewNonNull(graphql.ID), func(c models.Community) interface{} { return c.ID }),
+				"name":        fieldOf(graphql.NewNonNull(graphql.String), func(c models.Community) interface{} { return c.Name }),
+				"description": fieldOf(graphql.NewNonNull(graphql.String), func(c models.Community) interface{} { return c.Description }),
+				"createdAt":   fieldOf(graphql.NewNonNull(graphql.DateTime), func(c models.Community) interface{} { return c.CreatedAt }),
+				"members": {
+					Type: graphql.NewNonNull(graphql.Int),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return stats(p, func(s communityStats) interface{} { return s.Members })
+					},
+				},
+				"joined": {
+					Type: graphql.NewNonNull(graphql.Boolean),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return stats(p, func(s communityStats) interface{} { return s.Joined })
+					},
+				},
+				"posts": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+					Description: "Newest posts first",
+					Args:        graphql.FieldConfigArgument{"limit": limitArg(nestedListLimit)},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						req := requestOf(p)
+						return listThunk(req.loaders.postsBy("community_id", limitOf(p)).Load(p.Source.(models.Community).ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	postType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Post",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           fieldOf(graphql.NewNonNull(graphql.ID), func(v PostView) interface{} { return v.ID }),
+				"title":        fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Title }),
+				"kind":         fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Kind }),
+				"body":         fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Body }),
+				"bodyHtml":     fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.BodyHTML }),
+				"excerpt":      fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Excerpt }),
+				"url":          fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.URL }),
+				"domain":       fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Domain }),
+				"image":        fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.Image }),
+				"upvotes":      fieldOf(graphql.NewNonNull(graphql.Int), func(v PostView) interface{} { return v.Upvotes }),
+				"downvotes":    fieldOf(graphql.NewNonNull(graphql.Int), func(v PostView) interface{} { return v.Downvotes }),
+				"score":        fieldOf(graphql.NewNonNull(graphql.Int), func(v PostView) interface{} { return v.Upvotes - v.Downvotes }),
+				"commentCount": fieldOf(graphql.NewNonNull(graphql.Int), func(v PostView) interface{} { return v.Comments }),
+				"createdAt":    fieldOf(graphql.NewNonNull(graphql.DateTime), func(v PostView) interface{} { return v.CreatedAt }),
+				"editedAt": fieldOf(graphql.DateTime, func(v PostView) interface{} {
+					if v.EditedAt == nil {
+						return nil
+					}
+					return *v.EditedAt
+				}),
+				"userVote":  fieldOf(graphql.NewNonNull(graphql.Int), func(v PostView) interface{} { return v.UserVote }),
+				"saved":     fieldOf(graphql.NewNonNull(graphql.Boolean), func(v PostView) interface{} { return v.Saved }),
+				"modStatus": fieldOf(graphql.NewNonNull(graphql.String), func(v PostView) interface{} { return v.ModStatus }),
+				"author":    fieldOf(graphql.NewNonNull(userType), func(v PostView) interface{} { return v.User }),
+				"community": {
+					Type: communityType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						view := p.Source.(PostView)
+						if view.CommunityID == 0 {
+							return nil, nil
+						}
+						load := requestOf(p).loaders.communities.Load(view.CommunityID)
+						return func() (interface{}, error) {
+							community, err := load()
+							if err != nil || community == nil {
+								return nil, err
+							}
+							return *community, nil
+						}, nil
+					},
+				},
+				"comments": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+					Description: "Newest comments first, replies included",
+					Args:        graphql.FieldConfigArgument{"limit": limitArg(defaultPageLimit)},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return listThunk(requestOf(p).loaders.commentsOn(limitOf(p)).Load(p.Source.(PostView).ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	commentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Comment",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        fieldOf(graphql.NewNonNull(graphql.ID), func(v CommentView) interface{} { return v.ID }),
+				"body":      fieldOf(graphql.NewNonNull(graphql.String), func(v CommentView) interface{} { return v.Body }),
+				"bodyHtml":  fieldOf(graphql.NewNonNull(graphql.String), func(v CommentView) interface{} { return v.BodyHTML }),
+				"excerpt":   fieldOf(graphql.NewNonNull(graphql.String), func(v CommentView) interface{} { return v.Excerpt }),
+				"upvotes":   fieldOf(graphql.NewNonNull(graphql.Int), func(v CommentView) interface{} { return v.Upvotes }),
+				"downvotes": fieldOf(graphql.NewNonNull(graphql.Int), func(v CommentView) interface{} { return v.Downvotes }),
+				"score":     fieldOf(graphql.NewNonNull(graphql.Int), func(v CommentView) interface{} { return v.Upvotes - v.Downvotes }),
+				"createdAt": fieldOf(graphql.NewNonNull(graphql.DateTime), func(v CommentView) interface{} { return v.CreatedAt }),
+				"editedAt": fieldOf(graphql.DateTime, func(v CommentView) interface{} {
+					if v.EditedAt == nil {
+						return nil
+					}
+					return *v.EditedAt
+				}),
+				"userVote":  fieldOf(graphql.NewNonNull(graphql.Int), func(v CommentView) interface{} { return v.UserVote }),
+				"saved":     fieldOf(graphql.NewNonNull(graphql.Boolean), func(v CommentView) interface{} { return v.Saved }),
+				"collapsed": fieldOf(graphql.NewNonNull(graphql.Boolean), func(v CommentView) interface{} { return v.Collapsed }),
+				"modStatus": fieldOf(graphql.NewNonNull(graphql.String), func(v CommentView) interface{} { return v.ModStatus }),
+				"parentCommentId": fieldOf(graphql.ID, func(v CommentView) interface{} {
+					if v.ParentCommentID == nil {
+						return nil
+					}
+					return *v.ParentCommentID
+				}),
+				"author": fieldOf(graphql.NewNonNull(userType), func(v CommentView) interface{} { return v.User }),
+				"post": {
+					Type: postType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return postThunk(requestOf(p), p.Source.(CommentView).PostID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": {
+				Type:        userType,
+				Description: "The authenticated caller, or null",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return h.user(requestOf(p).viewerID, "")
+				},
+			},
+			"user": {
+				Type:        userType,
+				Description: "A user by id or username",
+				Args: graphql.FieldConfigArgument{
+					"id":       {Type: graphql.ID},
+					"username": {Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					username, _ := p.Args["username"].(string)
+					if _, ok := p.Args["id"]; !ok {
+						return h.user(0, username)
+					}
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return h.user(id, "")
+				},
+			},
+			"post": {
+				Type: postType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return postThunk(requestOf(p), id), nil
+				},
+			},
+			"posts": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType))),
+				Description: "The global feed",
+				Args: graphql.FieldConfigArgument{
+					"sort":   {Type: postSort, DefaultValue: feed.SortNew},
+					"limit":  limitArg(defaultPageLimit),
+					"offset": {Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := requestOf(p)
+					sort, _ := p.Args["sort"].(feed.Sort)
+					offset, _ := p.Args["offset"].(int)
+					q := feed.Query{Sort: sort}
+
+					var posts []models.Post
+					err := h.db.Preload("User").
+						Scopes(preloadPostKinds, policy.VisiblePosts(req.viewerID), policy.UnmutedPosts(req.viewerID), q.Scope).
+						Limit(limitOf(p)).Offset(max(offset, 0)).Find(&posts).Error
+					if err != nil {
+						return nil, err
+					}
+					return postViews(h.db, posts, req.viewerID), nil
+				},
+			},
+			"comments": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))),
+				Description: "A post's comments, newest first",
+				Args: graphql.FieldConfigArgument{
+					"postId": {Type: graphql.NewNonNull(graphql.ID)},
+					"limit":  limitArg(defaultPageLimit),
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					postID, err := idArg(p, "postId")
+					if err != nil {
+						return nil, err
+					}
+					return listThunk(requestOf(p).loaders.commentsOn(limitOf(p)).Load(postID)), nil
+				},
+			},
+			"community": {
+				Type: communityType,
+				Args: graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					community, err := findCommunity(h.db, p.Args["name"].(string))
+					if err != nil {
+						return nil, nil
+					}
+					return *community, nil
+				},
+			},
+			"communities": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(communityType))),
+				Description: "Communities alphabetically",
+				Args: graphql.FieldConfigArgument{
+					"limit":  limitArg(defaultPageLimit),
+					"offset": {Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					offset, _ := p.Args["offset"].(int)
+					var communities []models.Community
+					if err := h.db.Order("name asc").Limit(limitOf(p)).Offset(max(offset, 0)).Find(&communities).Error; err != nil {
+						return nil, err
+					}
+					return communities, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"votePost": {
+				Type:        postType,
+				Description: "Votes on a post; repeating a vote removes it",
+				Args: graphql.FieldConfigArgument{
+					"postId":    {Type: graphql.NewNonNull(graphql.ID)},
+					"direction": {Type: graphql.NewNonNull(voteDirection)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := requestOf(p)
+					voterID, err := requireViewer(req)
+					if err != nil {
+						return nil, err
+					}
+					postID, err := idArg(p, "postId")
+					if err != nil {
+						return nil, err
+					}
+
+					vote := models.Vote{UserID: voterID, PostID: postID, VoteType: p.Args["direction"].(int)}
+					recordVoteSource(req.gin, &vote)
+					if _, e := h.posts.vote(vote); e != nil {
+						return nil, graphqlError{e}
+					}
+
+					var post models.Post
+					if err := h.db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(voterID)).First(&post, postID).Error; err != nil {
+						return nil, nil
+					}
+					return postView(h.db, post, voterID), nil
+				},
+			},
+			"voteComment": {
+				Type:        commentType,
+				Description: "Votes on a comment; repeating a vote removes it",
+				Args: graphql.FieldConfigArgument{
+					"commentId": {Type: graphql.NewNonNull(graphql.ID)},
+					"direction": {Type: graphql.NewNonNull(voteDirection)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := requestOf(p)
+					voterID, err := requireViewer(req)
+					if err != nil {
+						return nil, err
+					}
+					commentID, err := idArg(p, "commentId")
+					if err != nil {
+						return nil, err
+					}
+
+					vote := models.Vote{UserID: voterID, CommentID: commentID, VoteType: p.Args["direction"].(int)}
+					recordVoteSource(req.gin, &vote)
+					if _, e := h.comments.vote(vote); e != nil {
+						return nil, graphqlError{e}
+					}
+
+					var comment models.Comment
+					if err := h.db.Preload("User").Scopes(policy.VisibleComments(voterID)).First(&comment, commentID).Error; err != nil {
+						return nil, nil
+					}
+					return commentView(h.db, comment, voterID), nil
+				},
+			},
+			"createComment": {
+				Type: graphql.NewNonNull(commentType),
+				Args: graphql.FieldConfigArgument{
+					"postId": {Type: graphql.NewNonNull(graphql.ID)},
+					"body":   {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					authorID, err := requireViewer(requestOf(p))
+					if err != nil {
+						return nil, err
+					}
+					postID, err := idArg(p, "postId")
+					if err != nil {
+						return nil, err
+					}
+					body := p.Args["body"].(string)
+					if body == "" {
+						return nil, graphqlError{apierr.New(http.StatusUnprocessableEntity, apierr.CodeValidation, "body is required")}
+					}
+
+					var post models.Post
+					if err := h.db.First(&post, postID).Error; err != nil {
+						return nil, graphqlError{apierr.New(http.StatusNotFound, apierr.CodeNotFound, "Post not found")}
+					}
+					comment, e := h.comments.create(post, authorID, body)
+					if e != nil {
+						return nil, graphqlError{e}
+					}
+					return commentView(h.db, comment, authorID), nil
+				},
+			},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"commentAdded": {
+				Type:        graphql.NewNonNull(commentType),
+				Description: "New comments on a post, as the caller would see them",
+				Args:        graphql.FieldConfigArgument{"postId": {Type: graphql.NewNonNull(graphql.ID)}},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					postID, err := idArg(p, "postId")
+					if err != nil {
+						return nil, err
+					}
+					return h.commentsAdded(requestOf(p), postID, p.Context.Done())
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(commentEvent).comment, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+// user looks up a user by ID, or by username when id is 0
+func (h *GraphQLHandler) user(id int, username string) (interface{}, error) {
+	var ids []int
+	var usernames []string
+	switch {
+	case id != 0:
+		ids = []int{id}
+	case username != "":
+		usernames = []string{username}
+	default:
+		return nil, nil
+	}
+	users, err := LoadUsers(h.db, ids, usernames)
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+	return users[0], nil
+}
+
+// commentsAdded streams the comments created on a post until done is
+// closed. Each comment is loaded as the subscriber may see it; those it may
+// not, such as comments held for review, are skipped.
+func (h *GraphQLHandler) commentsAdded(req *gqlRequest, postID int, done <-chan struct{}) (chan interface{}, error) {
+	var post models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(req.viewerID)).First(&post, postID).Error; err != nil {
+		return nil, graphqlError{apierr.New(http.StatusNotFound, apierr.CodeNotFound, "Post not found")}
+	}
+
+	created, unsubscribe := h.events.Subscribe(32)
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+		for {
+			var e events.Event
+			select {
+			case <-done:
+				return
+			case e = <-created:
+			}
+			if e.Type != events.CommentCreated || e.PostID != postID {
+				continue
+			}
+
+			var comment models.Comment
+			if err := h.db.Preload("User").Scopes(policy.VisibleComments(req.viewerID)).First(&comment, e.CommentID).Error; err != nil {
+				continue
+			}
+			event := commentEvent{
+				comment: commentView(h.db, comment, req.viewerID),
+				req:     &gqlRequest{gin: req.gin, viewerID: req.viewerID, loaders: newGQLLoaders(h.db, req.viewerID)},
+			}
+			select {
+			case out <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return out, nil
+}
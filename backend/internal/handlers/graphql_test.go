@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestQueryLimits(t *testing.T) {
+	h := NewGraphQLHandler(nil, nil, nil, nil)
+	h.limits = queryLimits{MaxDepth: 4, MaxComplexity: 300}
+
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		code  string
+	}{
+		{"shallow", `{ posts(limit: 10) { id author { username } } }`, nil, ""},
+		{"too deep", `{ posts { comments { post { author { posts { id } } } } } }`, nil, "query_too_deep"},
+		// 1 + 25 * (1 + 1 + 25 * (1 + 1 + 1)) = 1926
+		{"too complex", `{ posts { id comments { id author { id } } } }`, nil, "query_too_complex"},
+		{"complex by variable", `query($n: Int) { posts(limit: $n) { id comments(limit: $n) { id } } }`, map[string]interface{}{"n": float64(50)}, "query_too_complex"},
+		{"small by variable", `query($n: Int) { posts(limit: $n) { id comments(limit: $n) { id } } }`, map[string]interface{}{"n": float64(5)}, ""},
+		{"deep fragment", `{ posts { ...P } } fragment P on Post { comments { post { author { id } } } }`, nil, "query_too_deep"},
+		{"introspection is free", `{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`, nil, ""},
+	}
+	for _, tt := range tests {
+		_, _, errs := h.prepare(graphqlRequest{Query: tt.query, Variables: tt.vars})
+		var code interface{} = ""
+		if len(errs) > 0 {
+			code = errs[0].Extensions["code"]
+			if code == nil {
+				t.Errorf("%s: unexpected error %v", tt.name, errs)
+				continue
+			}
+		}
+		if code != tt.code {
+			t.Errorf("%s: got code %v, want %q", tt.name, code, tt.code)
+		}
+	}
+}
+
+func TestGraphQLRequestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewGraphQLHandler(nil, nil, nil, nil)
+	router := gin.New()
+	router.POST("/graphql", h.Serve)
+
+	tests := []struct {
+		name    string
+		query   string
+		status  int
+		message string
+	}{
+		{"syntax", `{ posts {`, http.StatusBadRequest, "Syntax Error"},
+		{"unknown field", `{ nope }`, http.StatusBadRequest, "Cannot query field"},
+		{"ambiguous operation", `query A { me { id } } query B { me { id } }`, http.StatusBadRequest, "operationName is required"},
+		{"subscription without event stream", `subscription { commentAdded(postId: 1) { id } }`, http.StatusNotAcceptable, "text/event-stream"},
+	}
+	for _, tt := range tests {
+		body, _ := json.Marshal(graphqlRequest{Query: tt.query})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if !strings.Contains(w.Body.String(), tt.message) {
+			t.Errorf("%s: body %s does not mention %q", tt.name, w.Body, tt.message)
+		}
+	}
+}
@@ -1,15 +1,45 @@
 package handlers
 
 import (
+	"context"
+	"log"
+	"os"
+	"time"
+
 	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/storage"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/trending"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/unfurl"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/voteguard"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/webhook"
 )
 
+const defaultTrendingInterval = 10 * time.Minute
+
 // Handler combines all handler types
 type Handler struct {
-	Auth    *AuthHandler
-	Post    *PostHandler
-	Comment *CommentHandler
-	User    *UserHandler
+	Auth       *AuthHandler
+	Post       *PostHandler
+	Comment    *CommentHandler
+	User       *UserHandler
+	Moderation *ModerationHandler
+	Revision   *RevisionHandler
+	Media      *MediaHandler
+	Mention    *MentionHandler
+	Saved      *SavedHandler
+	Block      *BlockHandler
+	Community  *CommunityHandler
+	GraphQL    *GraphQLHandler
+	Webhook    *WebhookHandler
+
+	// Storage holds uploaded media
+	Storage storage.Storage
+	// Events announces new content to subscribers in this process
+	Events *events.Bus
 }
 
 // NewHandler creates a unified handler with all sub-handlers
@@ -18,10 +48,76 @@ func NewHandler(db *database.Database) *Handler {
 	dbService := database.New()
 	gormDB := dbService.GetDB()
 
+	store, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+
+	// Link previews are fetched in the background for the life of the process
+	previews := unfurl.NewWorker(context.Background(), gormDB, unfurl.NewFetcher(), 2)
+
+	// Community stats are aggregated in the background for trending lists
+	interval := defaultTrendingInterval
+	if d, err := time.ParseDuration(os.Getenv("TRENDING_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+	trending.NewAggregator(gormDB, interval).Start(context.Background())
+
+	// Karma is maintained incrementally; backfill it once from existing votes
+	var karmaRows int64
+	gormDB.Model(&models.Karma{}).Count(&karmaRows)
+	if karmaRows == 0 {
+		if err := karma.Rebuild(gormDB); err != nil {
+			log.Printf("karma backfill failed: %v", err)
+		}
+	}
+
+	// Votes are analyzed in the background for rings, brigading and bursts
+	guard, err := voteguard.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid vote analysis configuration: %v", err)
+	}
+	voteguard.NewAnalyzer(gormDB, guard).Start(context.Background())
+
+	// New content is scored for spam; the classifier learns from moderator reviews
+	spamConfig, err := spam.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid spam filter configuration: %v", err)
+	}
+	classifier, err := spam.LoadNaiveBayes(gormDB)
+	if err != nil {
+		log.Printf("Failed to load spam classifier, starting untrained: %v", err)
+	}
+	spamFilter := spam.NewFilter(gormDB, spamConfig, classifier)
+
+	bus := events.NewBus()
+
+	// Events are delivered to registered webhooks in the background
+	webhookConfig, err := webhook.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid webhook configuration: %v", err)
+	}
+	dispatcher := webhook.NewDispatcher(gormDB, webhookConfig, webhookData(gormDB))
+	dispatcher.Start(context.Background(), bus)
+
+	posts := NewPostHandler(gormDB, previews, spamFilter, bus)
+	comments := NewCommentHandler(gormDB, spamFilter, bus)
+
 	return &Handler{
-		Auth:    NewAuthHandler(gormDB),
-		Post:    NewPostHandler(gormDB),
-		Comment: NewCommentHandler(gormDB),
-		User:    NewUserHandler(gormDB),
+		Auth:       NewAuthHandler(gormDB),
+		Post:       posts,
+		Comment:    comments,
+		User:       NewUserHandler(gormDB),
+		Moderation: NewModerationHandler(gormDB, spamFilter, bus),
+		Revision:   NewRevisionHandler(gormDB),
+		Media:      NewMediaHandler(gormDB, store),
+		Mention:    NewMentionHandler(gormDB),
+		Saved:      NewSavedHandler(gormDB),
+		Block:      NewBlockHandler(gormDB),
+		Community:  NewCommunityHandler(gormDB),
+		GraphQL:    NewGraphQLHandler(gormDB, posts, comments, bus),
+		Webhook:    NewWebhookHandler(gormDB, dispatcher),
+		Storage:    store,
+		Events:     bus,
 	}
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve sends a JSON request to handler as userID (0 for anonymous) and
+// returns the response. Route parameters are filled from pattern.
+func serve(t *testing.T, method, pattern, path string, userID int, body any, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	r := gin.New()
+	r.Handle(method, pattern, func(c *gin.Context) {
+		if userID != 0 {
+			c.Set("user_id", userID)
+		}
+		handler(c)
+	})
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func createUser(t *testing.T, db *gorm.DB, name, role string) models.User {
+	t.Helper()
+	user := models.User{Username: name, Email: name + "@example.com", Password: "x", Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func createCommunity(t *testing.T, db *gorm.DB, name string, creator models.User) models.Community {
+	t.Helper()
+	community := models.Community{Name: name, CreatedBy: creator.ID}
+	if err := db.Create(&community).Error; err != nil {
+		t.Fatal(err)
+	}
+	return community
+}
+
+func createPost(t *testing.T, db *gorm.DB, author models.User, community models.Community) models.Post {
+	t.Helper()
+	post := models.Post{Title: "a post", Body: "body", UserID: author.ID, AuthorID: author.ID, Author: author.Username,
+		CommunityID: community.ID, Community: community.Name}
+	if err := db.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+	return post
+}
+
+func newSpamFilter(db *gorm.DB) *spam.Filter {
+	return spam.NewFilter(db, spam.DefaultConfig(), spam.NewNaiveBayes())
+}
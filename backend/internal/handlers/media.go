@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/media"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/storage"
+)
+
+const defaultMaxUploadBytes = 10 << 20 // 10 MB
+
+type MediaHandler struct {
+	db       *gorm.DB
+	store    storage.Storage
+	maxBytes int64
+}
+
+func NewMediaHandler(db *gorm.DB, store storage.Storage) *MediaHandler {
+	maxBytes := int64(defaultMaxUploadBytes)
+	if v, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_BYTES"), 10, 64); err == nil && v > 0 {
+		maxBytes = v
+	}
+	return &MediaHandler{db: db, store: store, maxBytes: maxBytes}
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Upload accepts a multipart "file" field, sanitizes it and stores it
+func (h *MediaHandler) Upload(c *gin.Context) {
+	ownerID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	// Leave headroom for the multipart envelope around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+1<<20)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			apierr.TooLarge(c, fmt.Sprintf("File must be at most %d bytes", h.maxBytes))
+			return
+		}
+		apierr.BadRequest(c, "A file is required")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxBytes {
+		apierr.TooLarge(c, fmt.Sprintf("File must be at most %d bytes", h.maxBytes))
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxBytes+1))
+	if err != nil {
+		apierr.BadRequest(c, "Failed to read upload")
+		return
+	}
+	if int64(len(data)) > h.maxBytes {
+		apierr.TooLarge(c, fmt.Sprintf("File must be at most %d bytes", h.maxBytes))
+		return
+	}
+
+	processed, err := media.Process(data)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		apierr.UnsupportedMedia(c, "Only JPEG, PNG and GIF images are supported")
+		return
+	}
+	if err != nil {
+		apierr.BadRequest(c, "Invalid image")
+		return
+	}
+
+	name, err := randomKey()
+	if err != nil {
+		apierr.Internal(c, "Failed to store upload")
+		return
+	}
+	key := fmt.Sprintf("%d/%s.%s", ownerID, name, processed.Ext)
+	thumbKey := fmt.Sprintf("%d/%s_thumb.jpg", ownerID, name)
+
+	ctx := c.Request.Context()
+	if err := h.store.Put(ctx, key, bytes.NewReader(processed.Data), processed.ContentType); err != nil {
+		apierr.Internal(c, "Failed to store upload")
+		return
+	}
+	if err := h.store.Put(ctx, thumbKey, bytes.NewReader(processed.Thumbnail), "image/jpeg"); err != nil {
+		h.store.Delete(ctx, key)
+		apierr.Internal(c, "Failed to store upload")
+		return
+	}
+
+	record := models.Media{
+		OwnerID:      ownerID,
+		StorageKey:   key,
+		ThumbnailKey: thumbKey,
+		URL:          h.store.URL(key),
+		ThumbnailURL: h.store.URL(thumbKey),
+		ContentType:  processed.ContentType,
+		Size:         len(processed.Data),
+		Width:        processed.Width,
+		Height:       processed.Height,
+	}
+
+	if err := h.db.Create(&record).Error; err != nil {
+		h.store.Delete(ctx, key)
+		h.store.Delete(ctx, thumbKey)
+		apierr.Internal(c, "Failed to store upload")
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+// findOwnedMedia loads an uploaded file belonging to ownerID
+func findOwnedMedia(db *gorm.DB, ownerID, mediaID int) (*models.Media, error) {
+	var record models.Media
+	if err := db.Where("id = ? AND owner_id = ?", mediaID, ownerID).First(&record).Error; err != nil {
+		return nil, fmt.Errorf("media %d not found", mediaID)
+	}
+	return &record, nil
+}
+
+// resolveMediaItems replaces media IDs in gallery items with the URLs of
+// the caller's uploads
+func resolveMediaItems(db *gorm.DB, ownerID int, items []models.PostMediaInput) error {
+	for i, item := range items {
+		if item.MediaID == nil {
+			continue
+		}
+		record, err := findOwnedMedia(db, ownerID, *item.MediaID)
+		if err != nil {
+			return err
+		}
+		items[i].URL = record.URL
+	}
+	return nil
+}
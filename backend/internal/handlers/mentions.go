@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/mentions"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+type MentionHandler struct {
+	db *gorm.DB
+}
+
+func NewMentionHandler(db *gorm.DB) *MentionHandler {
+	return &MentionHandler{db: db}
+}
+
+func lowerAll(names []string) []string {
+	lowered := make([]string, len(names))
+	for i, n := range names {
+		lowered[i] = strings.ToLower(n)
+	}
+	return lowered
+}
+
+// resolveUserIDs looks up the IDs of existing users by case-insensitive name
+func resolveUserIDs(tx *gorm.DB, names []string) ([]int, error) {
+	var ids []int
+	if len(names) == 0 {
+		return ids, nil
+	}
+	err := tx.Model(&models.User{}).Where("LOWER(username) IN ?", lowerAll(names)).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// resolveCommunityIDs looks up the IDs of existing communities by case-insensitive name
+func resolveCommunityIDs(tx *gorm.DB, names []string) ([]int, error) {
+	var ids []int
+	if len(names) == 0 {
+		return ids, nil
+	}
+	err := tx.Model(&models.Community{}).Where("LOWER(name) IN ?", lowerAll(names)).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// syncMentions stores the mentions added between oldText and newText and
+// removes the ones edited out. Mentions that survive an edit keep their row,
+// so the mentioned user is not notified again. Names that don't resolve to a
+// user or community are ignored, as are self-mentions and mentions of users
+// who have blocked the author.
+func syncMentions(tx *gorm.DB, contentType string, contentID, postID, authorID int, oldText, newText string) error {
+	oldUsers, oldCommunities := mentions.Extract(oldText)
+	newUsers, newCommunities := mentions.Extract(newText)
+	addedUsers, removedUsers := mentions.Diff(oldUsers, newUsers)
+	addedCommunities, removedCommunities := mentions.Diff(oldCommunities, newCommunities)
+
+	if ids, err := resolveUserIDs(tx, removedUsers); err != nil {
+		return err
+	} else if len(ids) > 0 {
+		if err := tx.Where("content_type = ? AND content_id = ? AND user_id IN ?", contentType, contentID, ids).Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+	}
+	if ids, err := resolveCommunityIDs(tx, removedCommunities); err != nil {
+		return err
+	} else if len(ids) > 0 {
+		if err := tx.Where("content_type = ? AND content_id = ? AND community_id IN ?", contentType, contentID, ids).Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+	}
+
+	var added []models.Mention
+	userIDs, err := resolveUserIDs(tx, addedUsers)
+	if err != nil {
+		return err
+	}
+	var blockedBy []int
+	if len(userIDs) > 0 {
+		err := tx.Model(&models.Block{}).Where("blocker_id IN ? AND blocked_id = ?", userIDs, authorID).Pluck("blocker_id", &blockedBy).Error
+		if err != nil {
+			return err
+		}
+	}
+	for _, id := range userIDs {
+		if id == authorID || slices.Contains(blockedBy, id) {
+			continue
+		}
+		added = append(added, models.Mention{UserID: &id})
+	}
+	communityIDs, err := resolveCommunityIDs(tx, addedCommunities)
+	if err != nil {
+		return err
+	}
+	for _, id := range communityIDs {
+		added = append(added, models.Mention{CommunityID: &id})
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+	for i := range added {
+		added[i].ContentType = contentType
+		added[i].ContentID = contentID
+		added[i].PostID = postID
+		added[i].AuthorID = authorID
+	}
+	return tx.Create(&added).Error
+}
+
+// deleteMentions removes every mention made by a post or comment
+func deleteMentions(tx *gorm.DB, contentType string, contentID int) error {
+	return tx.Where("content_type = ? AND content_id = ?", contentType, contentID).Delete(&models.Mention{}).Error
+}
+
+// postMentionText is the text scanned for mentions in a post
+func postMentionText(post models.Post) string {
+	return post.Title + "\n" + post.Body
+}
+
+// MentionView is a mention of the caller with an excerpt of the post or
+// comment it was made in
+type MentionView struct {
+	ID          int        `json:"id"`
+	ContentType string     `json:"content_type"`
+	ContentID   int        `json:"content_id"`
+	PostID      int        `json:"post_id"`
+	PostTitle   string     `json:"post_title"`
+	Excerpt     string     `json:"excerpt"`
+	Author      PublicUser `json:"author"`
+	Read        bool       `json:"read"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// GetMyMentions lists the posts and comments mentioning the authenticated
+// user, newest first. ?unread=true limits it to unread mentions.
+func (h *MentionHandler) GetMyMentions(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	limit, offset := pageParams(c)
+
+	query := h.db.Preload("Author").Where("mentions.user_id = ?", userID).Scopes(h.visibleMentions(userID))
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var list []models.Mention
+	if err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&list).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch mentions")
+		return
+	}
+
+	postIDs := make([]int, 0, len(list))
+	var commentIDs []int
+	for _, m := range list {
+		postIDs = append(postIDs, m.PostID)
+		if m.ContentType == models.RevisionComment {
+			commentIDs = append(commentIDs, m.ContentID)
+		}
+	}
+
+	posts := make(map[int]models.Post)
+	if len(postIDs) > 0 {
+		var found []models.Post
+		h.db.Select("id", "title", "body", "excerpt", "render_version").Scopes(policy.VisiblePosts(userID)).Where("id IN ?", postIDs).Find(&found)
+		ensurePostsRendered(h.db, found)
+		for _, p := range found {
+			posts[p.ID] = p
+		}
+	}
+	comments := make(map[int]models.Comment)
+	if len(commentIDs) > 0 {
+		var found []models.Comment
+		h.db.Select("id", "body", "excerpt", "render_version").Scopes(policy.VisibleComments(userID)).Where("id IN ?", commentIDs).Find(&found)
+		ensureCommentsRendered(h.db, found)
+		for _, cm := range found {
+			comments[cm.ID] = cm
+		}
+	}
+
+	result := make([]MentionView, 0, len(list))
+	for _, m := range list {
+		post, ok := posts[m.PostID]
+		if !ok {
+			continue
+		}
+		excerpt := post.Excerpt
+		if m.ContentType == models.RevisionComment {
+			comment, ok := comments[m.ContentID]
+			if !ok {
+				continue
+			}
+			excerpt = comment.Excerpt
+		}
+		result = append(result, MentionView{
+			ID:          m.ID,
+			ContentType: m.ContentType,
+			ContentID:   m.ContentID,
+			PostID:      m.PostID,
+			PostTitle:   post.Title,
+			Excerpt:     excerpt,
+			Author:      publicUser(m.Author),
+			Read:        m.ReadAt != nil,
+			CreatedAt:   m.CreatedAt,
+		})
+	}
+
+	var unread int64
+	h.db.Model(&models.Mention{}).Where("mentions.user_id = ? AND mentions.read_at IS NULL", userID).Scopes(h.visibleMentions(userID)).Count(&unread)
+
+	c.JSON(http.StatusOK, gin.H{"mentions": result, "unread": unread})
+}
+
+// visibleMentions leaves out mentions in posts or comments the user can't
+// see, so they are neither listed nor counted as unread
+func (h *MentionHandler) visibleMentions(userID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		posts := h.db.Model(&models.Post{}).Select("posts.id").Scopes(policy.VisiblePosts(userID))
+		comments := h.db.Model(&models.Comment{}).Select("comments.id").Scopes(policy.VisibleComments(userID))
+		return db.Where("mentions.post_id IN (?)", posts).
+			Where("mentions.content_type <> ? OR mentions.content_id IN (?)", models.RevisionComment, comments)
+	}
+}
+
+// markReadInput lists the mentions to mark read; none means all
+type markReadInput struct {
+	IDs []int `json:"ids"`
+}
+
+// MarkMentionsRead marks the given mention IDs, or all of the user's
+// mentions when none are given, as read
+func (h *MentionHandler) MarkMentionsRead(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input markReadInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apierr.Bind(c, err)
+			return
+		}
+	}
+
+	query := h.db.Model(&models.Mention{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(input.IDs) > 0 {
+		query = query.Where("id IN ?", input.IDs)
+	}
+	if err := query.Update("read_at", time.Now()).Error; err != nil {
+		apierr.Internal(c, "Failed to update mentions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Mentions marked as read"})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestMyMentionsLeaveOutHiddenContent(t *testing.T) {
+	db := testdb.New(t)
+	comments := NewCommentHandler(db, newSpamFilter(db), nil)
+	h := NewMentionHandler(db)
+	author := createUser(t, db, "author", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	visible, e := comments.create(post, author.ID, "Hey @reader, what do you think?")
+	if e != nil {
+		t.Fatal(e)
+	}
+	held, e := comments.create(post, author.ID, "Another question for @reader")
+	if e != nil {
+		t.Fatal(e)
+	}
+	db.Model(&held).Update("mod_status", models.ModStatusPending)
+	// Cached by an older renderer, so the excerpt has to be rebuilt
+	db.Model(&visible).UpdateColumns(map[string]any{"excerpt": "", "render_version": 0})
+
+	w := serve(t, http.MethodGet, "/me/mentions", "/me/mentions", reader.ID, nil, h.GetMyMentions)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", w.Code, w.Body)
+	}
+	var response struct {
+		Mentions []MentionView `json:"mentions"`
+		Unread   int           `json:"unread"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Mentions) != 1 || response.Mentions[0].ContentID != visible.ID {
+		t.Fatalf("mentions %+v, want only the visible comment's", response.Mentions)
+	}
+	if response.Unread != 1 {
+		t.Errorf("unread %d, want 1: held comments don't count", response.Unread)
+	}
+	if response.Mentions[0].Excerpt == "" {
+		t.Error("excerpt of a stale comment was not rendered")
+	}
+}
@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/voteguard"
+)
+
+type ModerationHandler struct {
+	db         *gorm.DB
+	spamFilter *spam.Filter
+	events     *events.Bus
+}
+
+func NewModerationHandler(db *gorm.DB, spamFilter *spam.Filter, bus *events.Bus) *ModerationHandler {
+	return &ModerationHandler{db: db, spamFilter: spamFilter, events: bus}
+}
+
+// checkParticipation writes a 403 and returns false if the user is banned
+// site-wide or from the given community
+func checkParticipation(c *gin.Context, db *gorm.DB, userID, communityID int) bool {
+	if e := participationError(db, userID, communityID); e != nil {
+		apierr.Write(c, e)
+		return false
+	}
+	return true
+}
+
+// participationError is the error checkParticipation writes, or nil if the
+// user may participate
+func participationError(db *gorm.DB, userID, communityID int) *apierr.Error {
+	err := policy.CheckParticipation(db, userID, communityID)
+	var banErr *policy.BanError
+	if errors.As(err, &banErr) {
+		return apierr.New(http.StatusForbidden, apierr.CodeBanned, banErr.Error()).With("ban", banErr.Ban)
+	}
+	if err != nil {
+		return apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to check ban status")
+	}
+	return nil
+}
+
+// loadModerator returns the authenticated user if they may moderate the
+// given community, writing an error response otherwise
+func (h *ModerationHandler) loadModerator(c *gin.Context, communityID int) (models.User, bool) {
+	var moderator models.User
+	moderatorID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return moderator, false
+	}
+
+	if err := h.db.First(&moderator, moderatorID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return moderator, false
+	}
+
+	if !policy.Moderates(h.db, moderator, communityID) {
+		apierr.Forbidden(c, "You do not have permission to moderate here")
+		return moderator, false
+	}
+
+	return moderator, true
+}
+
+// BanUser bans a user site-wide or from a single community
+func (h *ModerationHandler) BanUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	var input models.CreateBanRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	communityID := 0
+	if input.CommunityID != nil {
+		communityID = *input.CommunityID
+	}
+
+	moderator, ok := h.loadModerator(c, communityID)
+	if !ok {
+		return
+	}
+
+	var target models.User
+	if err := h.db.First(&target, userID).Error; err != nil {
+		apierr.NotFound(c, "User not found")
+		return
+	}
+
+	if target.ID == moderator.ID {
+		apierr.BadRequest(c, "You cannot ban yourself")
+		return
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		apierr.BadRequest(c, "Expiry must be in the future")
+		return
+	}
+
+	ban := models.Ban{
+		UserID:      target.ID,
+		CommunityID: input.CommunityID,
+		Reason:      input.Reason,
+		Shadow:      input.Shadow,
+		BannedByID:  moderator.ID,
+		ExpiresAt:   input.ExpiresAt,
+	}
+
+	if err := h.db.Create(&ban).Error; err != nil {
+		apierr.Internal(c, "Failed to ban user")
+		return
+	}
+
+	// Shadow bans only work while the user doesn't know about them
+	if !ban.Shadow {
+		h.events.Publish(events.Event{
+			Type:        events.UserBanned,
+			CommunityID: communityID,
+			UserID:      target.ID,
+			BanID:       ban.ID,
+		})
+	}
+
+	c.JSON(http.StatusCreated, ban)
+}
+
+// UnbanUser lifts a ban by expiring it immediately
+func (h *ModerationHandler) UnbanUser(c *gin.Context) {
+	userID := c.Param("id")
+	banID := c.Param("banId")
+
+	var ban models.Ban
+	if err := h.db.Where("id = ? AND user_id = ?", banID, userID).First(&ban).Error; err != nil {
+		apierr.NotFound(c, "Ban not found")
+		return
+	}
+
+	communityID := 0
+	if ban.CommunityID != nil {
+		communityID = *ban.CommunityID
+	}
+	if _, ok := h.loadModerator(c, communityID); !ok {
+		return
+	}
+
+	now := time.Now()
+	ban.ExpiresAt = &now
+	if err := h.db.Save(&ban).Error; err != nil {
+		apierr.Internal(c, "Failed to lift ban")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ban lifted successfully"})
+}
+
+// GetUserBans lists a user's bans, active and expired (moderators only)
+func (h *ModerationHandler) GetUserBans(c *gin.Context) {
+	userID := c.Param("id")
+
+	var moderator models.User
+	moderatorID, _ := extractUserID(c)
+	if err := h.db.First(&moderator, moderatorID).Error; err != nil || (moderator.Role != models.RoleModerator && moderator.Role != models.RoleAdmin) {
+		apierr.Forbidden(c, "You do not have permission to view bans")
+		return
+	}
+
+	var bans []models.Ban
+	if err := h.db.Where("user_id = ?", userID).Order("created_at desc").Find(&bans).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch bans")
+		return
+	}
+
+	now := time.Now()
+	var responses []gin.H
+	for _, ban := range bans {
+		responses = append(responses, gin.H{
+			"ban":    ban,
+			"active": ban.Active(now),
+		})
+	}
+
+	if responses == nil {
+		responses = []gin.H{}
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// modStatusInput is a moderator's decision on a post or comment
+type modStatusInput struct {
+	Status string `json:"status" binding:"required,oneof=approved removed"`
+	Reason string `json:"reason"`
+}
+
+// ModeratePost approves or removes a post, e.g. one held by automod
+func (h *ModerationHandler) ModeratePost(c *gin.Context) {
+	var input modStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var post models.Post
+	if err := h.db.First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	if _, ok := h.loadModerator(c, post.CommunityID); !ok {
+		return
+	}
+
+	previous := post.ModStatus
+	if err := h.db.Model(&post).Updates(map[string]interface{}{"mod_status": input.Status, "mod_reason": input.Reason}).Error; err != nil {
+		apierr.Internal(c, "Failed to update post")
+		return
+	}
+	learnFromReview(h.spamFilter, spam.Content{Title: post.Title, Body: post.Body, URL: post.URL}, previous, input.Status)
+
+	c.JSON(http.StatusOK, gin.H{"id": post.ID, "mod_status": input.Status, "mod_reason": input.Reason})
+}
+
+// ModerateComment approves or removes a comment, e.g. one held by automod
+func (h *ModerationHandler) ModerateComment(c *gin.Context) {
+	var input modStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var comment models.Comment
+	if err := h.db.First(&comment, c.Param("commentId")).Error; err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+
+	var post models.Post
+	h.db.Select("id", "community_id").First(&post, comment.PostID)
+
+	if _, ok := h.loadModerator(c, post.CommunityID); !ok {
+		return
+	}
+
+	previous := comment.ModStatus
+	if err := h.db.Model(&comment).Updates(map[string]interface{}{"mod_status": input.Status, "mod_reason": input.Reason}).Error; err != nil {
+		apierr.Internal(c, "Failed to update comment")
+		return
+	}
+	learnFromReview(h.spamFilter, spam.Content{Body: comment.Body}, previous, input.Status)
+
+	c.JSON(http.StatusOK, gin.H{"id": comment.ID, "mod_status": input.Status, "mod_reason": input.Reason})
+}
+
+// loadStaff returns the authenticated user if they are a site moderator or
+// admin, writing an error response otherwise
+func (h *ModerationHandler) loadStaff(c *gin.Context) (models.User, bool) {
+	var user models.User
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return user, false
+	}
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return user, false
+	}
+	if user.Role != models.RoleModerator && user.Role != models.RoleAdmin {
+		apierr.Forbidden(c, "Only site moderators can review votes")
+		return user, false
+	}
+	return user, true
+}
+
+// GetVoteReport lists accounts whose votes were flagged as manipulated and
+// are awaiting review
+func (h *ModerationHandler) GetVoteReport(c *gin.Context) {
+	if _, ok := h.loadStaff(c); !ok {
+		return
+	}
+
+	limit, offset := pageParams(c)
+	entries, err := voteguard.Report(h.db, limit, offset)
+	if err != nil {
+		apierr.Internal(c, "Failed to load vote report")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// voteReviewInput settles a voter's flagged votes
+type voteReviewInput struct {
+	UserID  int  `json:"user_id" binding:"required"`
+	Restore bool `json:"restore"`
+}
+
+// ReviewVotes settles an account's flagged votes: restore counts them again,
+// otherwise they stay discounted. Reviewed votes are never flagged again.
+func (h *ModerationHandler) ReviewVotes(c *gin.Context) {
+	if _, ok := h.loadStaff(c); !ok {
+		return
+	}
+
+	var input voteReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	reviewed, err := voteguard.Review(h.db, input.UserID, input.Restore)
+	if err != nil {
+		apierr.Internal(c, "Failed to review votes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviewed": reviewed, "restored": input.Restore})
+}
+
+// GetModQueue lists posts and comments held or flagged for review, oldest
+// first. Admins see every community; everyone else the communities they
+// created or were appointed to moderate. ?community= narrows the queue to one community and
+// ?status=pending|flagged to one state.
+func (h *ModerationHandler) GetModQueue(c *gin.Context) {
+	var user models.User
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return
+	}
+
+	statuses := []string{models.ModStatusPending, models.ModStatusFlagged}
+	switch status := c.Query("status"); status {
+	case "":
+	case models.ModStatusPending, models.ModStatusFlagged:
+		statuses = []string{status}
+	default:
+		apierr.BadRequest(c, "status must be pending or flagged")
+		return
+	}
+
+	// Admins see everything unless they ask for one community
+	allCommunities := user.Role == models.RoleAdmin
+	var communityIDs []int
+	if name := c.Query("community"); name != "" {
+		community, err := findCommunity(h.db, name)
+		if err != nil {
+			apierr.NotFound(c, "Community not found")
+			return
+		}
+		if !policy.IsModerator(h.db, user, *community) {
+			apierr.Forbidden(c, "You do not have permission to moderate here")
+			return
+		}
+		allCommunities = false
+		communityIDs = []int{community.ID}
+	} else if !allCommunities {
+		var err error
+		if communityIDs, err = policy.ModeratedCommunities(h.db, user.ID); err != nil {
+			apierr.Internal(c, "Failed to load moderation queue")
+			return
+		}
+		if len(communityIDs) == 0 {
+			apierr.Forbidden(c, "You do not moderate any communities")
+			return
+		}
+	}
+
+	limit, offset := pageParams(c)
+
+	postQuery := h.db.Preload("User").Where("posts.mod_status IN ?", statuses)
+	commentQuery := h.db.Preload("User").Joins("JOIN posts ON posts.id = comments.post_id").Where("comments.mod_status IN ?", statuses)
+	if !allCommunities {
+		postQuery = postQuery.Where("posts.community_id IN ?", communityIDs)
+		commentQuery = commentQuery.Where("posts.community_id IN ?", communityIDs)
+	}
+
+	var posts []models.Post
+	if err := postQuery.Order("posts.created_at asc").Limit(limit).Offset(offset).Find(&posts).Error; err != nil {
+		apierr.Internal(c, "Failed to load moderation queue")
+		return
+	}
+	var comments []models.Comment
+	if err := commentQuery.Order("comments.created_at asc").Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
+		apierr.Internal(c, "Failed to load moderation queue")
+		return
+	}
+	ensurePostsRendered(h.db, posts)
+	ensureCommentsRendered(h.db, comments)
+
+	queuedPosts := make([]gin.H, 0, len(posts))
+	for _, post := range posts {
+		queuedPosts = append(queuedPosts, gin.H{
+			"id":           post.ID,
+			"title":        post.Title,
+			"kind":         post.Kind,
+			"excerpt":      post.Excerpt,
+			"url":          post.URL,
+			"community":    post.Community,
+			"community_id": post.CommunityID,
+			"user":         gin.H{"id": post.User.ID, "username": post.User.Username},
+			"mod_status":   post.ModStatus,
+			"mod_reason":   post.ModReason,
+			"spam_score":   post.SpamScore,
+			"created_at":   post.CreatedAt,
+		})
+	}
+	queuedComments := make([]gin.H, 0, len(comments))
+	for _, comment := range comments {
+		queuedComments = append(queuedComments, gin.H{
+			"id":         comment.ID,
+			"post_id":    comment.PostID,
+			"excerpt":    comment.Excerpt,
+			"user":       gin.H{"id": comment.User.ID, "username": comment.User.Username},
+			"mod_status": comment.ModStatus,
+			"mod_reason": comment.ModReason,
+			"spam_score": comment.SpamScore,
+			"created_at": comment.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"posts": queuedPosts, "comments": queuedComments})
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/automod"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/openapi"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/voteguard"
+)
+
+// APIInfo describes the API in the OpenAPI document
+var APIInfo = openapi.Info{
+	Title:   "Reddit Clone API",
+	Version: "1.0.0",
+	Description: "Errors share one envelope with a stable code; see the Error schema. " +
+		"Send X-Request-ID to correlate a request with server logs. " +
+		"Unversioned /api paths serve v1 for older app builds and are deprecated. " +
+		"App builds send X-Client-Version; builds older than the supported minimum get 426 upgrade_required.",
+}
+
+// Shapes of responses built by hand rather than from a model
+var (
+	communityResponse = openapi.Fields{
+		"id": 0, "name": "", "description": "", "created_by": 0, "created_at": models.Community{}.CreatedAt,
+		"members": 0, "joined": false,
+	}
+
+	feedPage = openapi.Fields{"posts": []PostView{}, "next_cursor": "", "source": ""}
+
+	modStatusResponse = openapi.Fields{"id": 0, "mod_status": "", "mod_reason": ""}
+
+	pagingParams = []openapi.Param{
+		{Name: "limit", Type: "integer", Description: "Page size, 25 by default and at most 100"},
+		{Name: "offset", Type: "integer"},
+	}
+
+	feedParams = []openapi.Param{
+		{Name: "sort", Enum: []string{"hot", "new", "top"}},
+		{Name: "t", Description: "Time window for top", Enum: []string{"hour", "day", "week", "month", "year", "all"}},
+		{Name: "flair", Type: "integer", Description: "Only posts with this flair template"},
+		{Name: "limit", Type: "integer", Description: "Page size, 25 by default and at most 100"},
+		{Name: "cursor", Description: "next_cursor from the previous page"},
+	}
+)
+
+// webhookDescription explains how deliveries are made and signed
+const webhookDescription = "Events: post.created, comment.created, vote.milestone, report.filed and user.banned. " +
+	"Each is POSTed as JSON with the headers X-Webhook-Event, X-Webhook-ID (the same for retries), X-Webhook-Timestamp and " +
+	"X-Webhook-Signature: sha256= and the hex HMAC-SHA256 of \"<timestamp>.<body>\" keyed with the secret returned here, which is not shown again. " +
+	"Any response other than 2xx is retried with exponential backoff."
+
+// Operations documents every route registered by the server. The server's
+// tests fail if a route is missing here, so add new routes to both.
+func Operations() []openapi.Operation {
+	const (
+		get    = http.MethodGet
+		post   = http.MethodPost
+		put    = http.MethodPut
+		del    = http.MethodDelete
+		public = openapi.Public
+		opt    = openapi.Optional
+		auth   = openapi.Required
+	)
+	forbidden := []int{http.StatusForbidden}
+	conflict := []int{http.StatusConflict}
+
+	return []openapi.Operation{
+		// Meta
+		{Method: get, Path: "/health", Tag: "Meta", Summary: "Health check", Auth: public, Response: openapi.Fields{"status": ""}},
+		{Method: get, Path: "/api/openapi.json", Tag: "Meta", Summary: "This OpenAPI document", Auth: public, Response: openapi.Fields{}},
+		{Method: get, Path: "/api/docs", Tag: "Meta", Summary: "Interactive API reference", Auth: public, Response: "", ContentType: "text/html"},
+
+		// Auth
+		{Method: post, Path: "/api/v1/register", Tag: "Auth", Summary: "Create an account", Auth: public, Body: registerInput{}, Response: AuthResponse{}, Status: http.StatusCreated, Errors: conflict},
+		{Method: post, Path: "/api/v1/login", Tag: "Auth", Summary: "Log in with email and password", Auth: public, Body: loginInput{}, Response: AuthResponse{}, Errors: []int{http.StatusUnauthorized}},
+		{Method: post, Path: "/api/v1/auth/google", Tag: "Auth", Summary: "Log in with a Google ID token", Auth: public, Body: oauthInput{}, Response: AuthResponse{}, Errors: []int{http.StatusUnauthorized}},
+		{Method: post, Path: "/api/v1/auth/apple", Tag: "Auth", Summary: "Log in with an Apple ID token", Auth: public, Body: oauthInput{}, Response: AuthResponse{}, Errors: []int{http.StatusUnauthorized}},
+		{Method: get, Path: "/api/v1/me", Tag: "Auth", Summary: "The logged-in user", Auth: auth, Response: Me{}},
+
+		// Posts
+		{Method: get, Path: "/api/v1/posts", Tag: "Posts", Summary: "List posts", Auth: opt, Query: feedParams, Response: []PostView{}},
+		{Method: get, Path: "/api/v1/posts/:id", Tag: "Posts", Summary: "Get a post", Auth: opt, Response: PostView{}},
+		{Method: post, Path: "/api/v1/posts", Tag: "Posts", Summary: "Create a post", Auth: auth, Body: createPostInput{}, Response: PostView{}, Status: http.StatusCreated,
+			Description: "Posts that break a community's requirements or automod rules are rejected with 422 rule_violation.",
+			Errors:      []int{http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity}},
+		{Method: put, Path: "/api/v1/posts/:id", Tag: "Posts", Summary: "Edit your post", Auth: auth, Body: updatePostInput{}, Response: PostView{},
+			Description: "Edits are checked against the community's requirements and automod rules like new posts.",
+			Errors:      []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+		{Method: del, Path: "/api/v1/posts/:id", Tag: "Posts", Summary: "Delete your post", Auth: auth, Errors: forbidden},
+		{Method: post, Path: "/api/v1/posts/:id/vote", Tag: "Posts", Summary: "Vote on a post; repeating a vote removes it", Auth: auth, Body: voteInput{}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/posts/:id/poll/vote", Tag: "Posts", Summary: "Vote in a poll", Auth: auth, Body: pollVoteInput{}, Response: PollView{}, Errors: []int{http.StatusForbidden, http.StatusConflict}},
+		{Method: post, Path: "/api/v1/posts/:id/crosspost", Tag: "Posts", Summary: "Crosspost into another community", Auth: auth, Body: crosspostInput{}, Response: PostView{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity}},
+		{Method: post, Path: "/api/v1/posts/:id/save", Tag: "Saved", Summary: "Save a post, optionally into a folder", Auth: auth, Body: saveInput{}, Response: models.SavedItem{}, Status: http.StatusCreated},
+		{Method: del, Path: "/api/v1/posts/:id/save", Tag: "Saved", Summary: "Unsave a post", Auth: auth},
+		{Method: post, Path: "/api/v1/posts/:id/hide", Tag: "Posts", Summary: "Hide a post from your feeds", Auth: auth},
+		{Method: del, Path: "/api/v1/posts/:id/hide", Tag: "Posts", Summary: "Unhide a post", Auth: auth},
+		{Method: get, Path: "/api/v1/posts/:id/revisions", Tag: "Posts", Summary: "A post's edit history", Auth: opt, Response: RevisionHistory{},
+			Query:       []openapi.Param{{Name: "from", Type: "integer"}, {Name: "to", Type: "integer"}},
+			Description: "With from and to, returns {from, to, title_diff, diff} comparing the two versions instead.",
+			Errors:      forbidden},
+
+		// Feeds
+		{Method: get, Path: "/api/v1/feed/home", Tag: "Feeds", Summary: "Posts from followed users and joined communities", Auth: auth, Query: feedParams, Response: feedPage},
+		{Method: get, Path: "/api/v1/feed/popular", Tag: "Feeds", Summary: "Popular posts site-wide", Auth: opt, Query: feedParams, Response: feedPage},
+		{Method: get, Path: "/api/v1/feed/all", Tag: "Feeds", Summary: "All posts site-wide", Auth: opt, Query: feedParams, Response: feedPage},
+
+		// Comments
+		{Method: get, Path: "/api/v1/posts/:id/comments", Tag: "Comments", Summary: "A post's comments", Auth: opt, Response: []CommentView{}},
+		{Method: post, Path: "/api/v1/posts/:id/comments", Tag: "Comments", Summary: "Comment on a post", Auth: auth, Body: commentInput{}, Response: CommentView{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusForbidden, http.StatusUnprocessableEntity}},
+		{Method: put, Path: "/api/v1/comments/:commentId", Tag: "Comments", Summary: "Edit your comment", Auth: auth, Body: commentInput{}, Response: CommentView{},
+			Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity}},
+		{Method: del, Path: "/api/v1/comments/:commentId", Tag: "Comments", Summary: "Delete your comment", Auth: auth, Errors: forbidden},
+		{Method: post, Path: "/api/v1/comments/:commentId/upvote", Tag: "Comments", Summary: "Upvote a comment; repeating removes the vote", Auth: auth, Errors: forbidden},
+		{Method: post, Path: "/api/v1/comments/:commentId/downvote", Tag: "Comments", Summary: "Downvote a comment; repeating removes the vote", Auth: auth, Errors: forbidden},
+		{Method: post, Path: "/api/v1/comments/:commentId/save", Tag: "Saved", Summary: "Save a comment, optionally into a folder", Auth: auth, Body: saveInput{}, Response: models.SavedItem{}, Status: http.StatusCreated},
+		{Method: del, Path: "/api/v1/comments/:commentId/save", Tag: "Saved", Summary: "Unsave a comment", Auth: auth},
+		{Method: get, Path: "/api/v1/comments/:commentId/revisions", Tag: "Comments", Summary: "A comment's edit history", Auth: opt, Response: RevisionHistory{},
+			Query: []openapi.Param{{Name: "from", Type: "integer"}, {Name: "to", Type: "integer"}}, Errors: forbidden},
+
+		// Users
+		{Method: get, Path: "/api/v1/users/:id", Tag: "Users", Summary: "A user's profile and posts", Auth: opt, Response: openapi.Fields{
+			"user":            PublicUser{},
+			"private":         false,
+			"posts":           []PostView{},
+			"follower_count":  0,
+			"following_count": 0,
+			"is_following":    false,
+			"karma":           karma.Totals{},
+		}, Description: "A private profile returns only user, private and is_following to callers outside its audience: the user and the people they follow."},
+		{Method: put, Path: "/api/v1/users/:id", Tag: "Users", Summary: "Edit your profile", Auth: auth, Body: profileInput{}, Response: Me{}, Errors: forbidden},
+		{Method: get, Path: "/api/v1/users/:id/followers", Tag: "Users", Summary: "A user's followers", Auth: opt, Response: []PublicUser{}, Errors: forbidden},
+		{Method: get, Path: "/api/v1/users/:id/following", Tag: "Users", Summary: "Users a user follows", Auth: opt, Response: []PublicUser{}, Errors: forbidden},
+		{Method: get, Path: "/api/v1/users/:id/upvoted", Tag: "Users", Summary: "Posts a user upvoted, if they show their votes", Auth: opt, Query: pagingParams, Response: []PostView{}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/users/:id/follow", Tag: "Users", Summary: "Follow a user", Auth: auth},
+		{Method: del, Path: "/api/v1/users/:id/follow", Tag: "Users", Summary: "Unfollow a user", Auth: auth},
+		{Method: post, Path: "/api/v1/users/:id/block", Tag: "Users", Summary: "Block a user", Auth: auth},
+		{Method: del, Path: "/api/v1/users/:id/block", Tag: "Users", Summary: "Unblock a user", Auth: auth},
+
+		// Me
+		{Method: get, Path: "/api/v1/me/mentions", Tag: "Me", Summary: "Your mentions", Auth: auth,
+			Query: append([]openapi.Param{{Name: "unread", Type: "boolean"}}, pagingParams...),
+			Response: openapi.Fields{
+				"mentions": []MentionView{},
+				"unread":   0,
+			}},
+		{Method: post, Path: "/api/v1/me/mentions/read", Tag: "Me", Summary: "Mark mentions read; all of them if no ids are given", Auth: auth, Body: markReadInput{}},
+		{Method: get, Path: "/api/v1/me/saved", Tag: "Saved", Summary: "Your saved posts and comments", Auth: auth,
+			Query: append([]openapi.Param{
+				{Name: "type", Enum: []string{"post", "comment"}},
+				{Name: "folder_id", Description: "A folder ID, or none for unfiled items"},
+			}, pagingParams...),
+			Response: []SavedItemView{}},
+		{Method: get, Path: "/api/v1/me/saved/folders", Tag: "Saved", Summary: "Your saved folders", Auth: auth, Response: []models.SavedFolder{}},
+		{Method: post, Path: "/api/v1/me/saved/folders", Tag: "Saved", Summary: "Create a saved folder", Auth: auth, Body: folderInput{}, Response: models.SavedFolder{}, Status: http.StatusCreated, Errors: conflict},
+		{Method: del, Path: "/api/v1/me/saved/folders/:folderId", Tag: "Saved", Summary: "Delete a saved folder; its items become unfiled", Auth: auth},
+		{Method: get, Path: "/api/v1/me/hidden", Tag: "Me", Summary: "Posts you have hidden", Auth: auth, Query: pagingParams, Response: []PostView{}},
+		{Method: get, Path: "/api/v1/me/blocked", Tag: "Me", Summary: "Users you have blocked", Auth: auth, Response: []BlockedUser{}},
+		{Method: get, Path: "/api/v1/me/communities", Tag: "Me", Summary: "Communities you have joined", Auth: auth, Response: []models.Community{}},
+		{Method: get, Path: "/api/v1/me/privacy", Tag: "Me", Summary: "Your privacy settings", Auth: auth, Response: models.PrivacySettings{}},
+		{Method: put, Path: "/api/v1/me/privacy", Tag: "Me", Summary: "Change your privacy settings; omitted fields are kept", Auth: auth, Body: privacyInput{}, Response: models.PrivacySettings{}},
+
+		// Communities
+		{Method: get, Path: "/api/v1/communities", Tag: "Communities", Summary: "List communities", Auth: opt, Query: pagingParams, Response: []openapi.Fields{communityResponse}},
+		{Method: get, Path: "/api/v1/communities/trending", Tag: "Communities", Summary: "Trending communities", Auth: opt, Query: pagingParams, Response: []TrendingCommunity{}},
+		{Method: get, Path: "/api/v1/communities/:name", Tag: "Communities", Summary: "Get a community", Auth: opt, Response: communityResponse},
+		{Method: post, Path: "/api/v1/communities", Tag: "Communities", Summary: "Create a community", Auth: auth, Body: communityInput{}, Response: communityResponse, Status: http.StatusCreated,
+			Errors: []int{http.StatusForbidden, http.StatusConflict}},
+		{Method: post, Path: "/api/v1/communities/:name/join", Tag: "Communities", Summary: "Join a community", Auth: auth, Response: communityResponse},
+		{Method: del, Path: "/api/v1/communities/:name/join", Tag: "Communities", Summary: "Leave a community", Auth: auth, Response: communityResponse},
+		{Method: get, Path: "/api/v1/communities/:name/flair", Tag: "Communities", Summary: "A community's flair templates", Auth: opt, Response: []models.FlairTemplate{}},
+		{Method: post, Path: "/api/v1/communities/:name/flair", Tag: "Communities", Summary: "Create a flair template (moderators)", Auth: auth, Body: flairTemplateInput{}, Response: models.FlairTemplate{}, Status: http.StatusCreated, Errors: forbidden},
+		{Method: del, Path: "/api/v1/communities/:name/flair/:flairId", Tag: "Communities", Summary: "Delete a flair template (moderators)", Auth: auth, Errors: forbidden},
+		{Method: put, Path: "/api/v1/communities/:name/user-flair", Tag: "Communities", Summary: "Set your flair, or a member's as a moderator; empty text removes it", Auth: auth, Body: userFlairInput{}, Response: models.UserFlair{}, Errors: forbidden},
+		{Method: get, Path: "/api/v1/communities/:name/rules", Tag: "Communities", Summary: "Posting requirements; moderators also get the automod YAML", Auth: opt, Response: openapi.Fields{
+			"requirements": automod.Requirements{}, "automod": "",
+		}},
+		{Method: put, Path: "/api/v1/communities/:name/rules", Tag: "Communities", Summary: "Replace posting requirements and automod rules (moderators)", Auth: auth, Body: rulesInput{}, Response: openapi.Fields{
+			"requirements": automod.Requirements{}, "automod": "",
+		}, Errors: forbidden},
+		{Method: get, Path: "/api/v1/communities/:name/moderators", Tag: "Communities", Summary: "A community's creator and appointed moderators", Auth: opt, Response: ModeratorsResponse{}},
+		{Method: post, Path: "/api/v1/communities/:name/moderators", Tag: "Communities", Summary: "Appoint a moderator (creator or admin)", Auth: auth, Body: moderatorInput{}, Response: models.CommunityModerator{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+		{Method: del, Path: "/api/v1/communities/:name/moderators/:userId", Tag: "Communities", Summary: "Remove a moderator (creator or admin)", Auth: auth, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+
+		// Media
+		{Method: post, Path: "/api/v1/media", Tag: "Media", Summary: "Upload a JPEG, PNG or GIF", Auth: auth, Upload: "file", Response: models.Media{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusForbidden, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType}},
+
+		// Moderation
+		{Method: get, Path: "/api/v1/users/:id/bans", Tag: "Moderation", Summary: "A user's bans", Auth: auth, Response: []openapi.Fields{{"ban": models.Ban{}, "active": false}}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/users/:id/bans", Tag: "Moderation", Summary: "Ban a user site-wide or from a community", Auth: auth, Body: models.CreateBanRequest{}, Response: models.Ban{}, Status: http.StatusCreated, Errors: forbidden},
+		{Method: del, Path: "/api/v1/users/:id/bans/:banId", Tag: "Moderation", Summary: "Lift a ban", Auth: auth, Errors: forbidden},
+		{Method: put, Path: "/api/v1/posts/:id/mod-status", Tag: "Moderation", Summary: "Approve or remove a post", Auth: auth, Body: modStatusInput{}, Response: modStatusResponse, Errors: forbidden},
+		{Method: put, Path: "/api/v1/comments/:commentId/mod-status", Tag: "Moderation", Summary: "Approve or remove a comment", Auth: auth, Body: modStatusInput{}, Response: modStatusResponse, Errors: forbidden},
+		{Method: post, Path: "/api/v1/posts/:id/report", Tag: "Moderation", Summary: "Report a post to its moderators", Auth: auth, Body: models.CreateReportRequest{}, Response: models.Report{}, Status: http.StatusCreated,
+			Description: "Flags the post for the moderation queue unless a moderator already reviewed it. Each user can report a post once.", Errors: conflict},
+		{Method: post, Path: "/api/v1/comments/:commentId/report", Tag: "Moderation", Summary: "Report a comment to its moderators", Auth: auth, Body: models.CreateReportRequest{}, Response: models.Report{}, Status: http.StatusCreated, Errors: conflict},
+		{Method: get, Path: "/api/v1/moderation/queue", Tag: "Moderation", Summary: "Held and flagged posts and comments", Auth: auth,
+			Query: append([]openapi.Param{
+				{Name: "community", Description: "Limit to one community by name"},
+				{Name: "status", Enum: []string{"pending", "flagged"}},
+			}, pagingParams...),
+			Response: openapi.Fields{
+				"posts": []openapi.Fields{{
+					"id": 0, "title": "", "kind": "", "excerpt": "", "url": "", "community": "", "community_id": 0,
+					"user": openapi.Fields{"id": 0, "username": ""}, "mod_status": "", "mod_reason": "", "spam_score": 0.0,
+					"created_at": models.Post{}.CreatedAt,
+				}},
+				"comments": []openapi.Fields{{
+					"id": 0, "post_id": 0, "excerpt": "", "user": openapi.Fields{"id": 0, "username": ""},
+					"mod_status": "", "mod_reason": "", "spam_score": 0.0, "created_at": models.Comment{}.CreatedAt,
+				}},
+			},
+			Errors: forbidden},
+		{Method: get, Path: "/api/v1/moderation/votes", Tag: "Moderation", Summary: "Accounts with votes flagged as manipulated", Auth: auth, Query: pagingParams, Response: []voteguard.ReportEntry{}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/moderation/votes/review", Tag: "Moderation", Summary: "Settle an account's flagged votes", Auth: auth, Body: voteReviewInput{}, Response: openapi.Fields{
+			"reviewed": 0, "restored": false,
+		}, Errors: forbidden},
+
+		// Webhooks
+		{Method: get, Path: "/api/v1/communities/:name/webhooks", Tag: "Webhooks", Summary: "A community's webhooks", Auth: auth, Response: []models.Webhook{}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/communities/:name/webhooks", Tag: "Webhooks", Summary: "Register a webhook for a community's events", Auth: auth, Body: webhookInput{}, Response: createdWebhook{}, Status: http.StatusCreated,
+			Description: webhookDescription, Errors: forbidden},
+		{Method: get, Path: "/api/v1/webhooks", Tag: "Webhooks", Summary: "App webhooks (admins)", Auth: auth, Response: []models.Webhook{}, Errors: forbidden},
+		{Method: post, Path: "/api/v1/webhooks", Tag: "Webhooks", Summary: "Register a webhook for events from every community (admins)", Auth: auth, Body: webhookInput{}, Response: createdWebhook{}, Status: http.StatusCreated,
+			Description: webhookDescription, Errors: forbidden},
+		{Method: put, Path: "/api/v1/webhooks/:webhookId", Tag: "Webhooks", Summary: "Change or disable a webhook", Auth: auth, Body: updateWebhookInput{}, Response: models.Webhook{}},
+		{Method: del, Path: "/api/v1/webhooks/:webhookId", Tag: "Webhooks", Summary: "Delete a webhook and its delivery history", Auth: auth},
+		{Method: get, Path: "/api/v1/webhooks/:webhookId/deliveries", Tag: "Webhooks", Summary: "A webhook's deliveries, newest first", Auth: auth,
+			Query:    append([]openapi.Param{{Name: "status", Description: "dead lists deliveries that ran out of attempts", Enum: []string{"pending", "delivered", "dead"}}}, pagingParams...),
+			Response: []models.WebhookDelivery{}},
+		{Method: post, Path: "/api/v1/webhooks/:webhookId/deliveries/:deliveryId/redeliver", Tag: "Webhooks", Summary: "Queue a delivered or dead delivery again", Auth: auth,
+			Response: models.WebhookDelivery{}, Status: http.StatusAccepted, Errors: conflict},
+
+		// GraphQL
+		{Method: post, Path: "/api/v1/graphql", Tag: "GraphQL", Summary: "Run a GraphQL query, mutation or subscription", Auth: opt, Body: graphqlRequest{},
+			Response: openapi.Fields{"data": openapi.Fields{}, "errors": []openapi.Fields{{"message": "", "path": []any{}, "extensions": openapi.Fields{"code": ""}}}},
+			Description: "Queries cover posts, comments, users and communities; mutations vote and comment, and need a token. " +
+				"Subscriptions (commentAdded) are streamed as server-sent events when requested with Accept: text/event-stream. " +
+				"Operations deeper or more complex than the limits are rejected with 400 and the code query_too_deep or query_too_complex.",
+			Errors: []int{http.StatusNotAcceptable}},
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+const (
+	maxGalleryItems     = 20
+	minPollOptions      = 2
+	maxPollOptions      = 6
+	defaultPollDuration = 3 * 24 * time.Hour
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// parseContentURL validates an http(s) URL and returns its domain without
+// a leading "www."
+func parseContentURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid URL: %q", raw)
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), nil
+}
+
+// applyPostKind validates the type-specific fields of a new post and fills
+// in the post's kind, URL, domain, gallery media and poll
+func applyPostKind(post *models.Post, input models.CreatePostRequest, legacyImage string) error {
+	kind := input.Kind
+	if kind == "" {
+		// Older clients send a single image and no kind
+		switch {
+		case legacyImage != "":
+			kind = models.PostKindGallery
+			input.Media = []models.PostMediaInput{{URL: legacyImage}}
+		case input.URL != "":
+			kind = models.PostKindLink
+		default:
+			kind = models.PostKindText
+		}
+	}
+
+	if kind != models.PostKindLink && kind != models.PostKindVideo && input.URL != "" {
+		return fmt.Errorf("url is only allowed on link and video posts")
+	}
+	if kind != models.PostKindGallery && len(input.Media) > 0 {
+		return fmt.Errorf("media is only allowed on gallery posts")
+	}
+	if kind != models.PostKindPoll && input.Poll != nil {
+		return fmt.Errorf("poll is only allowed on poll posts")
+	}
+
+	post.Kind = kind
+
+	switch kind {
+	case models.PostKindText:
+		return nil
+
+	case models.PostKindLink, models.PostKindVideo:
+		if input.URL == "" {
+			return fmt.Errorf("url is required for %s posts", kind)
+		}
+		domain, err := parseContentURL(input.URL)
+		if err != nil {
+			return err
+		}
+		post.URL = strings.TrimSpace(input.URL)
+		if kind == models.PostKindLink {
+			post.Domain = domain
+		}
+		return nil
+
+	case models.PostKindGallery:
+		if len(input.Media) == 0 {
+			return fmt.Errorf("gallery posts need at least one media item")
+		}
+		if len(input.Media) > maxGalleryItems {
+			return fmt.Errorf("gallery posts can have at most %d media items", maxGalleryItems)
+		}
+		for i, item := range input.Media {
+			// Uploaded media URLs come from our own storage and are already resolved
+			if item.MediaID == nil {
+				if _, err := parseContentURL(item.URL); err != nil {
+					return fmt.Errorf("media item %d: %w", i+1, err)
+				}
+			}
+			post.Media = append(post.Media, models.PostMedia{
+				Position: i,
+				MediaID:  item.MediaID,
+				URL:      strings.TrimSpace(item.URL),
+				Caption:  item.Caption,
+			})
+		}
+		// Keep the legacy image field populated for older clients
+		post.Image = post.Media[0].URL
+		return nil
+
+	case models.PostKindPoll:
+		return applyPoll(post, input.Poll)
+
+	default:
+		return fmt.Errorf("unknown post kind: %q", kind)
+	}
+}
+
+func applyPoll(post *models.Post, input *models.CreatePollRequest) error {
+	if input == nil {
+		return fmt.Errorf("poll is required for poll posts")
+	}
+	if len(input.Options) < minPollOptions || len(input.Options) > maxPollOptions {
+		return fmt.Errorf("polls need between %d and %d options", minPollOptions, maxPollOptions)
+	}
+
+	now := time.Now()
+	closesAt := now.Add(defaultPollDuration)
+	if input.ClosesAt != nil {
+		closesAt = *input.ClosesAt
+	}
+	if !closesAt.After(now) || closesAt.Sub(now) > maxPollDuration {
+		return fmt.Errorf("poll must close within %d days", int(maxPollDuration.Hours()/24))
+	}
+
+	poll := &models.Poll{ClosesAt: closesAt}
+	seen := make(map[string]bool)
+	for i, text := range input.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return fmt.Errorf("poll options cannot be empty")
+		}
+		if seen[strings.ToLower(text)] {
+			return fmt.Errorf("duplicate poll option: %q", text)
+		}
+		seen[strings.ToLower(text)] = true
+		poll.Options = append(poll.Options, models.PollOption{Position: i, Text: text})
+	}
+
+	post.Poll = poll
+	return nil
+}
+
+// preloadPostKinds loads the gallery media, poll options and flair of posts
+func preloadPostKinds(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Flair").
+		Preload("Media", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") }).
+		Preload("Poll.Options", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") })
+}
+
+// loadPollVotes returns the option each poll was voted for by the viewer
+func loadPollVotes(db *gorm.DB, posts []models.Post, viewerID int) map[int]int {
+	votes := make(map[int]int)
+	if viewerID == 0 {
+		return votes
+	}
+
+	var pollIDs []int
+	for _, post := range posts {
+		if post.Poll != nil {
+			pollIDs = append(pollIDs, post.Poll.ID)
+		}
+	}
+	if len(pollIDs) == 0 {
+		return votes
+	}
+
+	var pollVotes []models.PollVote
+	db.Where("user_id = ? AND poll_id IN ?", viewerID, pollIDs).Find(&pollVotes)
+	for _, v := range pollVotes {
+		votes[v.PollID] = v.OptionID
+	}
+	return votes
+}
+
+// pollVoteInput picks a poll option
+type pollVoteInput struct {
+	OptionID int `json:"option_id" binding:"required"`
+}
+
+// VotePoll casts the authenticated user's single vote on a poll post
+func (h *PostHandler) VotePoll(c *gin.Context) {
+	postID := c.Param("id")
+
+	voterID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input pollVoteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var post models.Post
+	if err := h.db.Preload("Poll").Scopes(policy.VisiblePosts(voterID)).First(&post, postID).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+	if post.Poll == nil {
+		apierr.BadRequest(c, "Post is not a poll")
+		return
+	}
+	if post.Poll.Closed(time.Now()) {
+		apierr.BadRequest(c, "Poll is closed")
+		return
+	}
+
+	if !checkParticipation(c, h.db, voterID, post.CommunityID) {
+		return
+	}
+
+	var option models.PollOption
+	if err := h.db.Where("id = ? AND poll_id = ?", input.OptionID, post.Poll.ID).First(&option).Error; err != nil {
+		apierr.BadRequest(c, "Invalid poll option")
+		return
+	}
+
+	errAlreadyVoted := errors.New("already voted")
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.PollVote{}).Where("poll_id = ? AND user_id = ?", post.Poll.ID, voterID).Count(&existing)
+		if existing > 0 {
+			return errAlreadyVoted
+		}
+		vote := models.PollVote{PollID: post.Poll.ID, UserID: voterID, OptionID: option.ID}
+		if err := tx.Create(&vote).Error; err != nil {
+			return err
+		}
+		return tx.Model(&option).UpdateColumn("votes", gorm.Expr("votes + 1")).Error
+	})
+	if errors.Is(err, errAlreadyVoted) || database.IsUniqueViolation(err) {
+		apierr.Conflict(c, "You have already voted in this poll")
+		return
+	}
+	if err != nil {
+		apierr.Internal(c, "Failed to record poll vote")
+		return
+	}
+
+	h.db.Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position asc") }).First(post.Poll, post.Poll.ID)
+	c.JSON(http.StatusOK, pollView(post.Poll, map[int]int{post.Poll.ID: option.ID}))
+}
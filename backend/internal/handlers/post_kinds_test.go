@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func createPoll(t *testing.T, db *gorm.DB, post models.Post, closesAt time.Time) models.Poll {
+	t.Helper()
+	poll := models.Poll{PostID: post.ID, ClosesAt: closesAt, Options: []models.PollOption{
+		{Position: 0, Text: "yes"},
+		{Position: 1, Text: "no"},
+	}}
+	if err := db.Create(&poll).Error; err != nil {
+		t.Fatal(err)
+	}
+	return poll
+}
+
+func TestVotePoll(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, nil, nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	voter := createUser(t, db, "voter", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+	open := createPost(t, db, author, gaming)
+	poll := createPoll(t, db, open, time.Now().Add(time.Hour))
+	closed := createPost(t, db, author, gaming)
+	closedPoll := createPoll(t, db, closed, time.Now().Add(-time.Hour))
+	plain := createPost(t, db, author, gaming)
+	removed := createPost(t, db, author, gaming)
+	removedPoll := createPoll(t, db, removed, time.Now().Add(time.Hour))
+	db.Model(&removed).Update("mod_status", models.ModStatusRemoved)
+
+	vote := func(userID, postID, optionID int) int {
+		path := fmt.Sprintf("/posts/%d/poll/vote", postID)
+		return serve(t, http.MethodPost, "/posts/:id/poll/vote", path, userID, pollVoteInput{OptionID: optionID}, h.VotePoll).Code
+	}
+
+	tests := []struct {
+		name     string
+		userID   int
+		postID   int
+		optionID int
+		want     int
+	}{
+		{"anonymous", 0, open.ID, poll.Options[0].ID, http.StatusUnauthorized},
+		{"not a poll", voter.ID, plain.ID, poll.Options[0].ID, http.StatusBadRequest},
+		{"closed poll", voter.ID, closed.ID, closedPoll.Options[0].ID, http.StatusBadRequest},
+		{"removed post", voter.ID, removed.ID, removedPoll.Options[0].ID, http.StatusNotFound},
+		{"another poll's option", voter.ID, open.ID, closedPoll.Options[0].ID, http.StatusBadRequest},
+		{"vote", voter.ID, open.ID, poll.Options[0].ID, http.StatusOK},
+		{"second vote", voter.ID, open.ID, poll.Options[1].ID, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if got := vote(tt.userID, tt.postID, tt.optionID); got != tt.want {
+			t.Errorf("%s: vote = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	var option models.PollOption
+	db.First(&option, poll.Options[0].ID)
+	if option.Votes != 1 {
+		t.Errorf("option has %d votes, want 1", option.Votes)
+	}
+}
+
+func TestConcurrentPollVotesAreCountedOnce(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, nil, nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	voter := createUser(t, db, "voter", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+	poll := createPoll(t, db, post, time.Now().Add(time.Hour))
+
+	const attempts = 8
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("/posts/%d/poll/vote", post.ID)
+			codes <- serve(t, http.MethodPost, "/posts/:id/poll/vote", path, voter.ID, pollVoteInput{OptionID: poll.Options[0].ID}, h.VotePoll).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	counted := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			counted++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent poll vote = %d, want 200 or 409", code)
+		}
+	}
+	var option models.PollOption
+	db.First(&option, poll.Options[0].ID)
+	if counted != 1 || option.Votes != 1 {
+		t.Errorf("%d votes accepted, option has %d; want 1", counted, option.Votes)
+	}
+}
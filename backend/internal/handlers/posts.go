@@ -2,112 +2,97 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/automod"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/feed"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/unfurl"
 )
 
 type PostHandler struct {
-	db *gorm.DB
+	db         *gorm.DB
+	previews   *unfurl.Worker
+	spamFilter *spam.Filter
+	events     *events.Bus
 }
 
-func NewPostHandler(db *gorm.DB) *PostHandler {
-	return &PostHandler{db: db}
-}
-
-func (h *PostHandler) calculateVotes(postID int) (int, int) {
-	var upvotes, downvotes int64
-	h.db.Model(&models.Vote{}).Where("post_id = ? AND vote_type = ?", postID, 1).Count(&upvotes)
-	h.db.Model(&models.Vote{}).Where("post_id = ? AND vote_type = ?", postID, -1).Count(&downvotes)
-	return int(upvotes), int(downvotes)
+func NewPostHandler(db *gorm.DB, previews *unfurl.Worker, spamFilter *spam.Filter, bus *events.Bus) *PostHandler {
+	return &PostHandler{db: db, previews: previews, spamFilter: spamFilter, events: bus}
 }
 
+// GetPosts returns the global feed. ?sort=new|hot|top (default new) and
+// ?t=hour|day|week|month|year|all for top.
 func (h *PostHandler) GetPosts(c *gin.Context) {
-	var posts []models.Post
+	viewerID, _ := extractUserID(c)
 
-	if err := h.db.Preload("User").Order("created_at desc").Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts"})
+	query, ok := feedQuery(c, feed.SortNew)
+	if !ok {
 		return
 	}
+	query.Limit = 0
 
-	// DON'T embed models.Post — build each response manually
-	var responses []gin.H
-	for _, post := range posts {
-		up, down := h.calculateVotes(post.ID)
-		responses = append(responses, gin.H{
-			"id":         post.ID,
-			"title":      post.Title,
-			"body":       post.Body,
-			"content":    post.Content,
-			"image":      post.Image,
-			"user_id":    post.UserID,
-			"author_id":  post.AuthorID,
-			"community":  post.Community,
-			"user":       post.User,
-			"upvotes":    up,
-			"downvotes":  down,
-			"comments":   post.Comments,
-			"created_at": post.CreatedAt,
-			"updated_at": post.UpdatedAt,
-		})
-	}
-
-	// If no posts, return empty array not null
-	if responses == nil {
-		responses = []gin.H{}
+	var posts []models.Post
+	if err := h.db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(viewerID), policy.UnmutedPosts(viewerID), query.Scope).Find(&posts).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch posts")
+		return
 	}
 
-	c.JSON(http.StatusOK, responses)
+	c.JSON(http.StatusOK, postViews(h.db, posts, viewerID))
 }
 
 // GetPost returns a single post by ID
 func (h *PostHandler) GetPost(c *gin.Context) {
 	postID := c.Param("id")
+	viewerID, _ := extractUserID(c)
 	var post models.Post
 
-	if err := h.db.Preload("User").First(&post, postID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	if err := h.db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(viewerID)).First(&post, postID).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
 		return
 	}
 
-	up, down := h.calculateVotes(post.ID)
-
-	c.JSON(http.StatusOK, gin.H{
-		"id":         post.ID,
-		"title":      post.Title,
-		"body":       post.Body,
-		"content":    post.Content,
-		"image":      post.Image,
-		"user_id":    post.UserID,
-		"author_id":  post.AuthorID,
-		"user":       post.User,
-		"upvotes":    up,
-		"downvotes":  down,
-		"created_at": post.CreatedAt,
-		"updated_at": post.UpdatedAt,
-	})
+	c.JSON(http.StatusOK, postView(h.db, post, viewerID))
+}
+
+// createPostInput describes a new post of any kind
+type createPostInput struct {
+	Title       string                    `json:"title" binding:"required"`
+	Kind        string                    `json:"kind"`
+	Body        string                    `json:"body"`
+	Content     string                    `json:"content"`
+	Image       string                    `json:"image"` // legacy single image, becomes a one-item gallery
+	URL         string                    `json:"url"`
+	Media       []models.PostMediaInput   `json:"media"`
+	Poll        *models.CreatePollRequest `json:"poll"`
+	CommunityID int                       `json:"community_id"`
+	FlairID     *int                      `json:"flair_id"`
 }
 
 // CreatePost creates a new post (PROTECTED - requires authentication)
 func (h *PostHandler) CreatePost(c *gin.Context) {
-	var input struct {
-		Title   string `json:"title" binding:"required"`
-		Body    string `json:"body"`
-		Content string `json:"content"`
-		Image   string `json:"image"`
-	}
+	var input createPostInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Title is required"})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Get user ID from auth middleware
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
@@ -121,7 +106,11 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	case float64:
 		authorID = int(v)
 	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		apierr.Internal(c, "Invalid user ID type")
+		return
+	}
+
+	if !checkParticipation(c, h.db, authorID, input.CommunityID) {
 		return
 	}
 
@@ -132,23 +121,117 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	}
 
 	post := models.Post{
-		Title:    input.Title,
-		Body:     postContent,
-		Content:  postContent,
-		Image:    input.Image,
-		AuthorID: authorID,
-		UserID:   authorID,
+		Title:       input.Title,
+		Body:        postContent,
+		Content:     postContent,
+		AuthorID:    authorID,
+		UserID:      authorID,
+		CommunityID: input.CommunityID,
+	}
+
+	var community *models.Community
+	if input.CommunityID != 0 {
+		community = &models.Community{}
+		if err := h.db.First(community, input.CommunityID).Error; err != nil {
+			apierr.Invalid(c, "community_id", "Community not found")
+			return
+		}
+		post.Community = community.Name
+	}
+
+	var author models.User
+	h.db.First(&author, authorID)
+	if err := pickPostFlair(h.db, author, community, input.FlairID); err != nil {
+		apierr.Invalid(c, "flair_id", err.Error())
+		return
+	}
+	post.FlairID = input.FlairID
+
+	if err := resolveMediaItems(h.db, authorID, input.Media); err != nil {
+		apierr.Invalid(c, "media", err.Error())
+		return
 	}
 
-	if err := h.db.Create(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
+	err := applyPostKind(&post, models.CreatePostRequest{
+		Kind:  input.Kind,
+		URL:   input.URL,
+		Media: input.Media,
+		Poll:  input.Poll,
+	}, input.Image)
+	if err != nil {
+		apierr.BadRequest(c, err.Error())
 		return
 	}
 
-	// Reload with user information
-	h.db.Preload("User").First(&post, post.ID)
+	post, e := h.create(post, automod.Content{
+		Type:  automod.TypePost,
+		Kind:  post.Kind,
+		Title: post.Title,
+		Body:  post.Body,
+		URL:   post.URL,
+	})
+	if e != nil {
+		apierr.Write(c, e)
+		return
+	}
 
-	c.JSON(http.StatusCreated, post)
+	c.JSON(http.StatusCreated, postView(h.db, post, authorID))
+}
+
+// create saves a new post, with its gallery media and poll options, once
+// the community's rules and the spam filter have checked content: posting
+// requirements refuse it, and automod or a high spam score hold it for
+// review. It records the post's mentions, announces it and returns it
+// reloaded with its user. Crossposts pass the content they share.
+func (h *PostHandler) create(post models.Post, content automod.Content) (models.Post, *apierr.Error) {
+	decision, e := applyCommunityRules(h.db, post.CommunityID, post.UserID, content)
+	if e != nil {
+		return models.Post{}, e
+	}
+	post.ModStatus = modStatusFor(decision.Action)
+	post.ModReason = decision.Reason
+
+	score := holdSpam(h.spamFilter, spam.Content{Title: content.Title, Body: content.Body, URL: content.URL, AuthorID: post.UserID}, &post.ModStatus, &post.ModReason)
+	post.ContentHash = score.Hash
+	post.SpamScore = score.Score
+
+	post.PreviewURL = previewURLFor(post)
+	renderPost(&post)
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&post).Error; err != nil {
+			return err
+		}
+		return syncMentions(tx, models.RevisionPost, post.ID, post.ID, post.UserID, "", postMentionText(post))
+	})
+	if database.IsUniqueViolation(err) {
+		// Only crossposts have a unique index of their own
+		return models.Post{}, apierr.New(http.StatusConflict, apierr.CodeConflict, "Post has already been crossposted to this community")
+	}
+	if err != nil {
+		return models.Post{}, apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to create post")
+	}
+
+	// Unfurled in the background; the preview appears once it is fetched
+	h.previews.Enqueue(post.PreviewURL)
+
+	h.events.Publish(events.Event{
+		Type:        events.PostCreated,
+		CommunityID: post.CommunityID,
+		PostID:      post.ID,
+		UserID:      post.UserID,
+	})
+
+	h.db.Preload("User").Scopes(preloadPostKinds).First(&post, post.ID)
+	return post, nil
+}
+
+// updatePostInput holds the edited fields. Pointers distinguish omitted
+// fields from empty strings, so a body can be cleared.
+type updatePostInput struct {
+	Title   *string `json:"title"`
+	Body    *string `json:"body"`
+	Content *string `json:"content"`
 }
 
 // UpdatePost updates an existing post (PROTECTED - requires ownership)
@@ -158,25 +241,21 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	// Get user ID from auth middleware
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	var input struct {
-		Title   string `json:"title"`
-		Body    string `json:"body"`
-		Content string `json:"content"`
-	}
+	var input updatePostInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Find the post
 	var post models.Post
 	if err := h.db.First(&post, postID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		apierr.NotFound(c, "Post not found")
 		return
 	}
 
@@ -190,33 +269,92 @@ func (h *PostHandler) UpdatePost(c *gin.Context) {
 	case float64:
 		currentUserID = int(v)
 	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		apierr.Internal(c, "Invalid user ID type")
 		return
 	}
 
 	// Check ownership
 	if post.AuthorID != currentUserID && post.UserID != currentUserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own posts"})
+		apierr.Forbidden(c, "You can only edit your own posts")
 		return
 	}
-
-	// Update fields
-	if input.Title != "" {
-		post.Title = input.Title
+	if !checkParticipation(c, h.db, currentUserID, post.CommunityID) {
+		return
 	}
-	if input.Body != "" {
-		post.Body = input.Body
-		post.Content = input.Body
+
+	original := models.Revision{
+		Title:     post.Title,
+		Body:      post.Body,
+		EditorID:  post.UserID,
+		CreatedAt: post.CreatedAt,
 	}
-	if input.Content != "" {
-		post.Content = input.Content
-		post.Body = input.Content
+
+	// Update fields
+	if input.Title != nil {
+		if strings.TrimSpace(*input.Title) == "" {
+			apierr.BadRequest(c, "Title cannot be empty")
+			return
+		}
+		post.Title = *input.Title
+	}
+	if input.Body != nil {
+		post.Body = *input.Body
+		post.Content = *input.Body
+	}
+	if input.Content != nil {
+		post.Content = *input.Content
+		post.Body = *input.Content
+	}
+
+	if post.Title != original.Title || post.Body != original.Body {
+		e := reviewEdit(h.db, h.spamFilter, post.CommunityID, post.UserID, automod.Content{
+			Type:  automod.TypePost,
+			Kind:  post.Kind,
+			Title: post.Title,
+			Body:  post.Body,
+			URL:   post.URL,
+		}, &post.ModStatus, &post.ModReason, &post.ContentHash, &post.SpamScore)
+		if e != nil {
+			apierr.Write(c, e)
+			return
+		}
+
+		now := time.Now()
+		post.EditedAt = &now
+		post.PreviewURL = previewURLFor(post)
+		renderPost(&post)
+
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&post).Error; err != nil {
+				return err
+			}
+			oldText := postMentionText(models.Post{Title: original.Title, Body: original.Body})
+			if err := syncMentions(tx, models.RevisionPost, post.ID, post.ID, post.UserID, oldText, postMentionText(post)); err != nil {
+				return err
+			}
+			return recordRevision(tx, original, models.Revision{
+				ContentType: models.RevisionPost,
+				ContentID:   post.ID,
+				Title:       post.Title,
+				Body:        post.Body,
+				EditorID:    currentUserID,
+			})
+		})
+		if database.IsUniqueViolation(err) {
+			apierr.Conflict(c, "Post was edited at the same time, try again")
+			return
+		}
+		if err != nil {
+			apierr.Internal(c, "Failed to update post")
+			return
+		}
+
+		h.previews.Enqueue(post.PreviewURL)
 	}
 
-	h.db.Save(&post)
-	h.db.Preload("User").First(&post, post.ID)
+	h.db.Preload("User").Scopes(preloadPostKinds).First(&post, post.ID)
 
-	c.JSON(http.StatusOK, post)
+	c.JSON(http.StatusOK, postView(h.db, post, currentUserID))
 }
 
 // DeletePost deletes a post (PROTECTED - requires ownership)
@@ -226,14 +364,14 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	// Get user ID from auth middleware
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
 	// Find the post
 	var post models.Post
 	if err := h.db.First(&post, postID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		apierr.NotFound(c, "Post not found")
 		return
 	}
 
@@ -247,109 +385,121 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	case float64:
 		currentUserID = int(v)
 	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		apierr.Internal(c, "Invalid user ID type")
 		return
 	}
 
 	// Check ownership
 	if post.AuthorID != currentUserID && post.UserID != currentUserID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own posts"})
+		apierr.Forbidden(c, "You can only delete your own posts")
 		return
 	}
 
-	if err := h.db.Delete(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete post"})
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+
+		// The karma earned by votes on the post and its comments goes with it
+		if err := withdrawVotes(tx, karma.Post, post.CommunityID, map[int]int{post.ID: post.UserID}); err != nil {
+			return err
+		}
+		var comments []models.Comment
+		if err := tx.Select("id", "author_id").Where("post_id = ?", post.ID).Find(&comments).Error; err != nil {
+			return err
+		}
+		authors := make(map[int]int, len(comments))
+		for _, comment := range comments {
+			authors[comment.ID] = comment.AuthorID
+		}
+		if err := withdrawVotes(tx, karma.Comment, post.CommunityID, authors); err != nil {
+			return err
+		}
+
+		return tx.Delete(&post).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to delete post")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
 }
 
+// voteInput is an upvote (1) or downvote (-1)
+type voteInput struct {
+	VoteType int `json:"vote_type" binding:"required,oneof=-1 1"`
+}
+
 // VotePost handles upvoting/downvoting a post (PROTECTED - requires authentication)
 func (h *PostHandler) VotePost(c *gin.Context) {
-	postID := c.Param("id")
-
-	// Get user ID from auth middleware
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+	voterID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
 		return
 	}
 
-	var input struct {
-		VoteType int `json:"vote_type" binding:"required,oneof=-1 1"`
-	}
+	var input voteInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Vote type must be -1 or 1"})
+		apierr.Bind(c, err)
 		return
 	}
 
-	// Check if post exists
-	var post models.Post
-	if err := h.db.First(&post, postID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierr.NotFound(c, "Post not found")
 		return
 	}
 
-	// Convert userID to int
-	var voterID int
-	switch v := userID.(type) {
-	case int:
-		voterID = v
-	case uint:
-		voterID = int(v)
-	case float64:
-		voterID = int(v)
-	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+	vote := models.Vote{UserID: voterID, PostID: postID, VoteType: input.VoteType}
+	recordVoteSource(c, &vote)
+	message, e := h.vote(vote)
+	if e != nil {
+		apierr.Write(c, e)
 		return
 	}
 
-	// Check if user already voted
-	var existingVote models.Vote
-	err := h.db.Where("user_id = ? AND post_id = ?", voterID, postID).First(&existingVote).Error
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
 
-	if err == nil {
-		// User already voted
-		if existingVote.VoteType == input.VoteType {
-			// Same vote - remove it (toggle)
-			h.db.Delete(&existingVote)
-			c.JSON(http.StatusOK, gin.H{"message": "Vote removed"})
-			return
-		} else {
-			// Different vote - update it
-			existingVote.VoteType = input.VoteType
-			h.db.Save(&existingVote)
-			c.JSON(http.StatusOK, gin.H{"message": "Vote updated"})
-			return
-		}
+// vote casts vote on its post after the ban checks for the post's community
+func (h *PostHandler) vote(vote models.Vote) (string, *apierr.Error) {
+	var post models.Post
+	if err := h.db.First(&post, vote.PostID).Error; err != nil {
+		return "", apierr.New(http.StatusNotFound, apierr.CodeNotFound, "Post not found")
 	}
 
-	// Create new vote
-	vote := models.Vote{
-		UserID:   voterID,
-		PostID:   post.ID,
-		VoteType: input.VoteType,
+	if e := participationError(h.db, vote.UserID, post.CommunityID); e != nil {
+		return "", e
 	}
 
-	if err := h.db.Create(&vote).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote"})
-		return
+	message, delta, err := castVote(h.db, vote, karma.Target{Kind: karma.Post, AuthorID: post.UserID, CommunityID: post.CommunityID})
+	if err != nil {
+		return "", apierr.New(http.StatusInternalServerError, apierr.CodeInternal, "Failed to vote")
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Vote recorded"})
+	announceMilestone(h.db, h.events, events.Event{CommunityID: post.CommunityID, PostID: post.ID, UserID: vote.UserID}, delta)
+	return message, nil
 }
 
 // GetUserPosts returns all posts by a specific user
 func (h *PostHandler) GetUserPosts(c *gin.Context) {
-	userID := c.Param("id")
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierr.BadRequest(c, "Invalid user ID")
+		return
+	}
 	var posts []models.Post
 
-	if err := h.db.Preload("User").Where("user_id = ? OR author_id = ?", userID, userID).Order("created_at desc").Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user posts"})
+	viewerID, _ := extractUserID(c)
+	if !policy.CanViewProfile(policy.LoadPrivacy(h.db, userID), policy.AudienceOf(h.db, viewerID, userID)) {
+		apierr.Forbidden(c, "This profile is private")
+		return
+	}
+	if err := h.db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(viewerID)).Where("user_id = ? OR author_id = ?", userID, userID).Order("created_at desc").Find(&posts).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch user posts")
 		return
 	}
 
-	c.JSON(http.StatusOK, posts)
+	c.JSON(http.StatusOK, postViews(h.db, posts, viewerID))
 }
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/unfurl"
+)
+
+// previewURLFor picks the URL whose preview is shown with a post: the link
+// of a link post, or the first URL in the body of a text post
+func previewURLFor(post models.Post) string {
+	switch post.Kind {
+	case models.PostKindLink:
+		return post.URL
+	case models.PostKindText, "":
+		return unfurl.FirstURL(post.Body)
+	default:
+		return ""
+	}
+}
+
+// loadPreviews fetches the cached previews of posts in one query, keyed by URL
+func loadPreviews(db *gorm.DB, posts []models.Post) map[string]*models.LinkPreview {
+	previews := make(map[string]*models.LinkPreview)
+
+	var urls []string
+	for _, post := range posts {
+		if post.PreviewURL != "" {
+			urls = append(urls, post.PreviewURL)
+		}
+	}
+	if len(urls) == 0 {
+		return previews
+	}
+
+	var records []models.LinkPreview
+	db.Where("url IN ? AND status = ?", urls, models.PreviewOK).Find(&records)
+	for i := range records {
+		previews[records[i].URL] = &records[i]
+	}
+	return previews
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/markdown"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// renderBody renders Markdown, falling back to empty HTML on failure so a
+// rendering bug never blocks a write
+func renderBody(body string) (string, string) {
+	html, excerpt, err := markdown.Render(body)
+	if err != nil {
+		log.Printf("failed to render markdown: %v", err)
+		return "", ""
+	}
+	return html, excerpt
+}
+
+func renderPost(post *models.Post) {
+	post.BodyHTML, post.Excerpt = renderBody(post.Body)
+	post.RenderVersion = markdown.Version
+}
+
+func renderComment(comment *models.Comment) {
+	comment.BodyHTML, comment.Excerpt = renderBody(comment.Body)
+	comment.RenderVersion = markdown.Version
+}
+
+// ensurePostsRendered re-renders posts cached by an older renderer version
+// and persists the result
+func ensurePostsRendered(db *gorm.DB, posts []models.Post) {
+	for i := range posts {
+		if posts[i].RenderVersion == markdown.Version {
+			continue
+		}
+		renderPost(&posts[i])
+		db.Model(&models.Post{}).Where("id = ?", posts[i].ID).UpdateColumns(map[string]interface{}{
+			"body_html":      posts[i].BodyHTML,
+			"excerpt":        posts[i].Excerpt,
+			"render_version": posts[i].RenderVersion,
+		})
+	}
+}
+
+// ensureCommentsRendered re-renders comments cached by an older renderer
+// version and persists the result
+func ensureCommentsRendered(db *gorm.DB, comments []models.Comment) {
+	for i := range comments {
+		if comments[i].RenderVersion == markdown.Version {
+			continue
+		}
+		renderComment(&comments[i])
+		db.Model(&models.Comment{}).Where("id = ?", comments[i].ID).UpdateColumns(map[string]interface{}{
+			"body_html":      comments[i].BodyHTML,
+			"excerpt":        comments[i].Excerpt,
+			"render_version": comments[i].RenderVersion,
+		})
+	}
+}
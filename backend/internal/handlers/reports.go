@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// ReportPost reports a post to its community's moderators
+func (h *ModerationHandler) ReportPost(c *gin.Context) {
+	reporterID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input models.CreateReportRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var post models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(reporterID)).First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	report := models.Report{ReporterID: reporterID, PostID: post.ID, CommunityID: post.CommunityID, Reason: input.Reason}
+	h.fileReport(c, report, &post, post.ModStatus)
+}
+
+// ReportComment reports a comment to its community's moderators
+func (h *ModerationHandler) ReportComment(c *gin.Context) {
+	reporterID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input models.CreateReportRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	var comment models.Comment
+	if err := h.db.Scopes(policy.VisibleComments(reporterID)).First(&comment, c.Param("commentId")).Error; err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+	var post models.Post
+	if err := h.db.Select("id", "community_id").First(&post, comment.PostID).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	report := models.Report{ReporterID: reporterID, PostID: post.ID, CommentID: &comment.ID, CommunityID: post.CommunityID, Reason: input.Reason}
+	h.fileReport(c, report, &comment, comment.ModStatus)
+}
+
+// fileReport saves a report on target, a post or comment, and flags the
+// target for the moderation queue unless a moderator already reviewed it or
+// it is held anyway. Each user can report a post or comment once.
+func (h *ModerationHandler) fileReport(c *gin.Context, report models.Report, target any, modStatus string) {
+	if e := participationError(h.db, report.ReporterID, report.CommunityID); e != nil {
+		apierr.Write(c, e)
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		if modStatus != models.ModStatusNone {
+			return nil
+		}
+		return tx.Model(target).Updates(map[string]interface{}{"mod_status": models.ModStatusFlagged, "mod_reason": "Reported: " + report.Reason}).Error
+	})
+	if database.IsUniqueViolation(err) {
+		apierr.Conflict(c, "You already reported this")
+		return
+	}
+	if err != nil {
+		apierr.Internal(c, "Failed to file report")
+		return
+	}
+
+	e := events.Event{
+		Type:        events.ReportFiled,
+		CommunityID: report.CommunityID,
+		PostID:      report.PostID,
+		UserID:      report.ReporterID,
+		ReportID:    report.ID,
+	}
+	if report.CommentID != nil {
+		e.CommentID = *report.CommentID
+	}
+	h.events.Publish(e)
+
+	c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestReports(t *testing.T) {
+	db := testdb.New(t)
+	bus := events.NewBus()
+	feed, unsubscribe := bus.Subscribe(16)
+	defer unsubscribe()
+	h := NewModerationHandler(db, nil, bus)
+
+	author := createUser(t, db, "author", models.RoleUser)
+	reporter := createUser(t, db, "reporter", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+	post := createPost(t, db, author, gaming)
+	comment := models.Comment{Body: "a comment", AuthorID: author.ID, PostID: post.ID}
+	db.Create(&comment)
+
+	reportPost := func(userID int) int {
+		path := fmt.Sprintf("/posts/%d/report", post.ID)
+		return serve(t, http.MethodPost, "/posts/:id/report", path, userID, models.CreateReportRequest{Reason: "spam"}, h.ReportPost).Code
+	}
+	reportComment := func(userID, commentID int) int {
+		path := fmt.Sprintf("/comments/%d/report", commentID)
+		return serve(t, http.MethodPost, "/comments/:commentId/report", path, userID, models.CreateReportRequest{Reason: "rude"}, h.ReportComment).Code
+	}
+
+	if code := reportPost(reporter.ID); code != http.StatusCreated {
+		t.Fatalf("first post report = %d", code)
+	}
+	if code := reportPost(reporter.ID); code != http.StatusConflict {
+		t.Errorf("repeated post report = %d, want 409", code)
+	}
+	if code := reportComment(reporter.ID, comment.ID); code != http.StatusCreated {
+		t.Errorf("comment report on a reported post = %d, want 201", code)
+	}
+	if code := reportComment(reporter.ID, comment.ID); code != http.StatusConflict {
+		t.Errorf("repeated comment report = %d, want 409", code)
+	}
+	if code := reportComment(reporter.ID, 9999); code != http.StatusNotFound {
+		t.Errorf("report of a missing comment = %d, want 404", code)
+	}
+	if code := reportPost(0); code != http.StatusUnauthorized {
+		t.Errorf("anonymous report = %d, want 401", code)
+	}
+
+	var flagged models.Post
+	db.First(&flagged, post.ID)
+	if flagged.ModStatus != models.ModStatusFlagged {
+		t.Errorf("reported post mod status = %q, want flagged", flagged.ModStatus)
+	}
+	if n := len(feed); n != 2 {
+		t.Errorf("published %d report events, want 2", n)
+	}
+}
+
+func TestConcurrentReportsAreFiledOnce(t *testing.T) {
+	db := testdb.New(t)
+	bus := events.NewBus()
+	feed, unsubscribe := bus.Subscribe(16)
+	defer unsubscribe()
+	h := NewModerationHandler(db, nil, bus)
+
+	author := createUser(t, db, "author", models.RoleUser)
+	reporter := createUser(t, db, "reporter", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	const attempts = 8
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("/posts/%d/report", post.ID)
+			codes <- serve(t, http.MethodPost, "/posts/:id/report", path, reporter.ID, models.CreateReportRequest{Reason: "spam"}, h.ReportPost).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("concurrent report = %d, want 201 or 409", code)
+		}
+	}
+	var count int64
+	db.Model(&models.Report{}).Count(&count)
+	if created != 1 || count != 1 {
+		t.Errorf("%d reports created, %d stored; want 1", created, count)
+	}
+	if n := len(feed); n != 1 {
+		t.Errorf("published %d report events, want 1", n)
+	}
+}
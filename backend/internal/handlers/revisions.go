@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/diff"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+type RevisionHandler struct {
+	db *gorm.DB
+	// public exposes edit history to everyone, not just authors and moderators
+	public bool
+}
+
+func NewRevisionHandler(db *gorm.DB) *RevisionHandler {
+	return &RevisionHandler{
+		db:     db,
+		public: os.Getenv("PUBLIC_REVISION_HISTORY") == "true",
+	}
+}
+
+// recordRevision appends the edited version of a post or comment. The first
+// edit also stores the original content as version 1 so every version can be
+// diffed against its predecessor. Callers save the edited row first in the
+// same transaction; its row lock makes concurrent edits take turns, and the
+// unique index on versions refuses any that don't.
+func recordRevision(tx *gorm.DB, original, edited models.Revision) error {
+	var latest models.Revision
+	err := tx.Where("content_type = ? AND content_id = ?", edited.ContentType, edited.ContentID).
+		Order("version desc").Limit(1).Find(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	if latest.ID == 0 {
+		original.ContentType = edited.ContentType
+		original.ContentID = edited.ContentID
+		original.Version = 1
+		if err := tx.Create(&original).Error; err != nil {
+			return err
+		}
+		latest = original
+	}
+
+	edited.Version = latest.Version + 1
+	return tx.Create(&edited).Error
+}
+
+// GetPostRevisions returns the edit history of a post with diffs. Posts
+// hidden from the viewer are not found.
+func (h *RevisionHandler) GetPostRevisions(c *gin.Context) {
+	viewerID, _ := extractUserID(c)
+	var post models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(viewerID)).First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	if !h.canView(c, post.UserID, post.CommunityID) {
+		return
+	}
+
+	h.respondRevisions(c, models.RevisionPost, post.ID)
+}
+
+// GetCommentRevisions returns the edit history of a comment with diffs.
+// Comments hidden from the viewer, or on posts hidden from them, are not
+// found.
+func (h *RevisionHandler) GetCommentRevisions(c *gin.Context) {
+	viewerID, _ := extractUserID(c)
+	var comment models.Comment
+	if err := h.db.Scopes(policy.VisibleComments(viewerID)).First(&comment, c.Param("commentId")).Error; err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+
+	var post models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(viewerID)).Select("posts.id", "posts.community_id").First(&post, comment.PostID).Error; err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+
+	if !h.canView(c, comment.AuthorID, post.CommunityID) {
+		return
+	}
+
+	h.respondRevisions(c, models.RevisionComment, comment.ID)
+}
+
+// canView allows the author and moderators to see edit history, and
+// everyone when public history is enabled
+func (h *RevisionHandler) canView(c *gin.Context, authorID, communityID int) bool {
+	if h.public {
+		return true
+	}
+
+	viewerID, ok := extractUserID(c)
+	if ok && viewerID == authorID {
+		return true
+	}
+
+	var viewer models.User
+	if ok && h.db.First(&viewer, viewerID).Error == nil && policy.Moderates(h.db, viewer, communityID) {
+		return true
+	}
+
+	apierr.Forbidden(c, "Edit history is only visible to the author and moderators")
+	return false
+}
+
+// RevisionView is one version of a post or comment. Every version after the
+// first carries its diff against the one before.
+type RevisionView struct {
+	Version   int        `json:"version"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	Editor    PublicUser `json:"editor"`
+	TitleDiff *string    `json:"title_diff,omitempty"`
+	Diff      *string    `json:"diff,omitempty"`
+}
+
+// RevisionHistory is every version of a post or comment, oldest first
+type RevisionHistory struct {
+	ContentType string         `json:"content_type"`
+	ContentID   int            `json:"content_id"`
+	Revisions   []RevisionView `json:"revisions"`
+}
+
+// RevisionDiff compares two versions of a post or comment
+type RevisionDiff struct {
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	TitleDiff string `json:"title_diff"`
+	Diff      string `json:"diff"`
+}
+
+// respondRevisions lists every version with a diff against the previous one,
+// or a single diff between ?from= and ?to= versions when both are given
+func (h *RevisionHandler) respondRevisions(c *gin.Context, contentType string, contentID int) {
+	var revisions []models.Revision
+	if err := h.db.Where("content_type = ? AND content_id = ?", contentType, contentID).
+		Preload("Editor").Order("version asc").Find(&revisions).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch revisions")
+		return
+	}
+
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if fromParam != "" && toParam != "" {
+		from, errFrom := strconv.Atoi(fromParam)
+		to, errTo := strconv.Atoi(toParam)
+		if errFrom != nil || errTo != nil {
+			apierr.BadRequest(c, "from and to must be version numbers")
+			return
+		}
+
+		var older, newer *models.Revision
+		for i := range revisions {
+			switch revisions[i].Version {
+			case from:
+				older = &revisions[i]
+			case to:
+				newer = &revisions[i]
+			}
+		}
+		if older == nil || newer == nil {
+			apierr.NotFound(c, "Revision not found")
+			return
+		}
+
+		c.JSON(http.StatusOK, RevisionDiff{
+			From:      from,
+			To:        to,
+			TitleDiff: diff.Unified(diff.Lines(older.Title, newer.Title)),
+			Diff:      diff.Unified(diff.Lines(older.Body, newer.Body)),
+		})
+		return
+	}
+
+	responses := make([]RevisionView, 0, len(revisions))
+	for i, rev := range revisions {
+		response := RevisionView{
+			Version:   rev.Version,
+			Title:     rev.Title,
+			Body:      rev.Body,
+			CreatedAt: rev.CreatedAt,
+			Editor:    publicUser(rev.Editor),
+		}
+		if i > 0 {
+			prev := revisions[i-1]
+			titleDiff := diff.Unified(diff.Lines(prev.Title, rev.Title))
+			bodyDiff := diff.Unified(diff.Lines(prev.Body, rev.Body))
+			response.TitleDiff = &titleDiff
+			response.Diff = &bodyDiff
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, RevisionHistory{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Revisions:   responses,
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestPublicRevisionsOfHiddenContent(t *testing.T) {
+	db := testdb.New(t)
+	h := &RevisionHandler{db: db, public: true}
+	author := createUser(t, db, "author", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", author)
+
+	visible := createPost(t, db, author, gaming)
+	removed := createPost(t, db, author, gaming)
+	db.Model(&removed).Update("mod_status", models.ModStatusRemoved)
+	comment := models.Comment{Body: "a comment", AuthorID: author.ID, PostID: visible.ID, ModStatus: models.ModStatusPending}
+	db.Create(&comment)
+	onRemoved := models.Comment{Body: "a comment", AuthorID: author.ID, PostID: removed.ID}
+	db.Create(&onRemoved)
+
+	postRevisions := func(viewerID, postID int) int {
+		path := fmt.Sprintf("/posts/%d/revisions", postID)
+		return serve(t, http.MethodGet, "/posts/:id/revisions", path, viewerID, nil, h.GetPostRevisions).Code
+	}
+	commentRevisions := func(viewerID, commentID int) int {
+		path := fmt.Sprintf("/comments/%d/revisions", commentID)
+		return serve(t, http.MethodGet, "/comments/:commentId/revisions", path, viewerID, nil, h.GetCommentRevisions).Code
+	}
+
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"visible post", postRevisions(reader.ID, visible.ID), http.StatusOK},
+		{"removed post", postRevisions(reader.ID, removed.ID), http.StatusNotFound},
+		{"removed post, anonymous", postRevisions(0, removed.ID), http.StatusNotFound},
+		{"removed post, author", postRevisions(author.ID, removed.ID), http.StatusOK},
+		{"held comment", commentRevisions(reader.ID, comment.ID), http.StatusNotFound},
+		{"held comment, author", commentRevisions(author.ID, comment.ID), http.StatusOK},
+		{"comment on a removed post", commentRevisions(reader.ID, onRemoved.ID), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestConcurrentEditsGetTheirOwnVersions(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	const edits = 8
+	var n atomic.Int32
+	path := fmt.Sprintf("/posts/%d", post.ID)
+	codes := concurrently(edits, func() int {
+		body := fmt.Sprintf("Edited for the %dth time", n.Add(1))
+		return serve(t, http.MethodPut, "/posts/:id", path, author.ID, updatePostInput{Body: &body}, h.UpdatePost).Code
+	})
+	for _, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("concurrent edit = %d, want 200", code)
+		}
+	}
+
+	var versions []int
+	db.Model(&models.Revision{}).Where("content_type = ? AND content_id = ?", models.RevisionPost, post.ID).
+		Order("version").Pluck("version", &versions)
+	if len(versions) != edits+1 {
+		t.Fatalf("%d versions recorded, want the original and %d edits", len(versions), edits)
+	}
+	for i, version := range versions {
+		if version != i+1 {
+			t.Fatalf("versions %v, want 1 to %d", versions, edits+1)
+		}
+	}
+}
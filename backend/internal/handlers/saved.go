@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+const maxSavedFolderName = 50
+
+type SavedHandler struct {
+	db *gorm.DB
+}
+
+func NewSavedHandler(db *gorm.DB) *SavedHandler {
+	return &SavedHandler{db: db}
+}
+
+// saveInput picks the folder to save into
+type saveInput struct {
+	FolderID *int `json:"folder_id"`
+}
+
+// bindFolder reads an optional folder_id from the request body and checks it
+// belongs to the user
+func (h *SavedHandler) bindFolder(c *gin.Context, userID int) (*int, bool) {
+	var input saveInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			apierr.Bind(c, err)
+			return nil, false
+		}
+	}
+	if input.FolderID == nil {
+		return nil, true
+	}
+
+	var folder models.SavedFolder
+	if err := h.db.Where("id = ? AND user_id = ?", *input.FolderID, userID).First(&folder).Error; err != nil {
+		apierr.BadRequest(c, "Folder not found")
+		return nil, false
+	}
+	return &folder.ID, true
+}
+
+// save bookmarks an item, or moves it to another folder if already saved
+func (h *SavedHandler) save(c *gin.Context, userID int, item models.SavedItem) {
+	folderID, ok := h.bindFolder(c, userID)
+	if !ok {
+		return
+	}
+
+	if h.moveSaved(c, userID, item, folderID) {
+		return
+	}
+
+	item.UserID = userID
+	item.FolderID = folderID
+	err := h.db.Create(&item).Error
+	if database.IsUniqueViolation(err) && h.moveSaved(c, userID, item, folderID) {
+		// Saved by a concurrent request since we looked
+		return
+	}
+	if err != nil {
+		apierr.Internal(c, "Failed to save")
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// moveSaved moves an item the user already saved into folderID and responds
+// with it. It reports false, without responding, if the item isn't saved.
+func (h *SavedHandler) moveSaved(c *gin.Context, userID int, item models.SavedItem, folderID *int) bool {
+	var existing models.SavedItem
+	err := h.db.Where("user_id = ? AND post_id = ? AND comment_id = ?", userID, item.PostID, item.CommentID).Limit(1).Find(&existing).Error
+	if err != nil || existing.ID == 0 {
+		return false
+	}
+
+	existing.FolderID = folderID
+	if err := h.db.Model(&existing).Update("folder_id", folderID).Error; err != nil {
+		apierr.Internal(c, "Failed to save")
+		return true
+	}
+	c.JSON(http.StatusOK, existing)
+	return true
+}
+
+func (h *SavedHandler) unsave(c *gin.Context, userID int, item models.SavedItem) {
+	err := h.db.Where("user_id = ? AND post_id = ? AND comment_id = ?", userID, item.PostID, item.CommentID).
+		Delete(&models.SavedItem{}).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to unsave")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from saved"})
+}
+
+// SavePost bookmarks a post, optionally into {"folder_id": n}
+func (h *SavedHandler) SavePost(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var post models.Post
+	if err := h.db.Scopes(policy.VisiblePosts(userID)).First(&post, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "Post not found")
+		return
+	}
+
+	h.save(c, userID, models.SavedItem{PostID: post.ID})
+}
+
+// UnsavePost removes a post from the user's saved items
+func (h *SavedHandler) UnsavePost(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierr.BadRequest(c, "Invalid post ID")
+		return
+	}
+
+	h.unsave(c, userID, models.SavedItem{PostID: postID})
+}
+
+// SaveComment bookmarks a comment, optionally into {"folder_id": n}
+func (h *SavedHandler) SaveComment(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var comment models.Comment
+	if err := h.db.Scopes(policy.VisibleComments(userID)).First(&comment, c.Param("commentId")).Error; err != nil {
+		apierr.NotFound(c, "Comment not found")
+		return
+	}
+
+	h.save(c, userID, models.SavedItem{CommentID: comment.ID})
+}
+
+// UnsaveComment removes a comment from the user's saved items
+func (h *SavedHandler) UnsaveComment(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		apierr.BadRequest(c, "Invalid comment ID")
+		return
+	}
+
+	h.unsave(c, userID, models.SavedItem{CommentID: commentID})
+}
+
+// SavedItemView is a saved post or comment. Only the field named by Type is
+// set.
+type SavedItemView struct {
+	ID       int          `json:"id"`
+	Type     string       `json:"type"`
+	FolderID *int         `json:"folder_id"`
+	SavedAt  time.Time    `json:"saved_at"`
+	Post     *PostView    `json:"post,omitempty"`
+	Comment  *CommentView `json:"comment,omitempty"`
+}
+
+// GetSaved lists the user's saved items, newest first. ?type=post|comment
+// and ?folder_id= filter the list; ?folder_id=none lists unfiled items.
+func (h *SavedHandler) GetSaved(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	query := h.db.Where("user_id = ?", userID)
+	switch c.Query("type") {
+	case "":
+	case "post":
+		query = query.Where("post_id <> 0")
+	case "comment":
+		query = query.Where("comment_id <> 0")
+	default:
+		apierr.BadRequest(c, "type must be post or comment")
+		return
+	}
+	switch folder := c.Query("folder_id"); folder {
+	case "":
+	case "none":
+		query = query.Where("folder_id IS NULL")
+	default:
+		folderID, err := strconv.Atoi(folder)
+		if err != nil {
+			apierr.BadRequest(c, "Invalid folder ID")
+			return
+		}
+		query = query.Where("folder_id = ?", folderID)
+	}
+
+	limit, offset := pageParams(c)
+	var items []models.SavedItem
+	if err := query.Order("created_at desc").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch saved items")
+		return
+	}
+
+	var postIDs, commentIDs []int
+	for _, item := range items {
+		if item.PostID != 0 {
+			postIDs = append(postIDs, item.PostID)
+		} else {
+			commentIDs = append(commentIDs, item.CommentID)
+		}
+	}
+
+	posts := make(map[int]PostView)
+	if len(postIDs) > 0 {
+		var found []models.Post
+		h.db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(userID)).Where("id IN ?", postIDs).Find(&found)
+		for _, p := range postViews(h.db, found, userID) {
+			posts[p.ID] = p
+		}
+	}
+	comments := make(map[int]CommentView)
+	if len(commentIDs) > 0 {
+		var found []models.Comment
+		h.db.Preload("User").Scopes(policy.VisibleComments(userID)).Where("id IN ?", commentIDs).Find(&found)
+		for _, cm := range commentViews(h.db, found, userID) {
+			comments[cm.ID] = cm
+		}
+	}
+
+	// Items whose content was deleted or hidden are left out
+	responses := make([]SavedItemView, 0, len(items))
+	for _, item := range items {
+		entry := SavedItemView{ID: item.ID, FolderID: item.FolderID, SavedAt: item.CreatedAt}
+		if item.PostID != 0 {
+			post, ok := posts[item.PostID]
+			if !ok {
+				continue
+			}
+			entry.Type = "post"
+			entry.Post = &post
+		} else {
+			comment, ok := comments[item.CommentID]
+			if !ok {
+				continue
+			}
+			entry.Type = "comment"
+			entry.Comment = &comment
+		}
+		responses = append(responses, entry)
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetFolders lists the user's saved folders
+func (h *SavedHandler) GetFolders(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var folders []models.SavedFolder
+	if err := h.db.Where("user_id = ?", userID).Order("name asc").Find(&folders).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch folders")
+		return
+	}
+
+	c.JSON(http.StatusOK, folders)
+}
+
+// folderInput names a new saved folder
+type folderInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateFolder adds a named folder for saved items
+func (h *SavedHandler) CreateFolder(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input folderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxSavedFolderName {
+		apierr.BadRequest(c, "Folder name must be 1-50 characters")
+		return
+	}
+
+	var count int64
+	h.db.Model(&models.SavedFolder{}).Where("user_id = ? AND name = ?", userID, name).Count(&count)
+	if count > 0 {
+		apierr.Conflict(c, "Folder already exists")
+		return
+	}
+
+	folder := models.SavedFolder{UserID: userID, Name: name}
+	if err := h.db.Create(&folder).Error; err != nil {
+		apierr.Internal(c, "Failed to create folder")
+		return
+	}
+
+	c.JSON(http.StatusCreated, folder)
+}
+
+// DeleteFolder removes a folder; its items stay saved but become unfiled
+func (h *SavedHandler) DeleteFolder(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var folder models.SavedFolder
+	if err := h.db.Where("id = ? AND user_id = ?", c.Param("folderId"), userID).First(&folder).Error; err != nil {
+		apierr.NotFound(c, "Folder not found")
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SavedItem{}).Where("folder_id = ?", folder.ID).Update("folder_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&folder).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to delete folder")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestSavePost(t *testing.T) {
+	db := testdb.New(t)
+	h := NewSavedHandler(db)
+	author := createUser(t, db, "author", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+	folder := models.SavedFolder{UserID: reader.ID, Name: "later"}
+	db.Create(&folder)
+
+	save := func(input any) int {
+		path := fmt.Sprintf("/posts/%d/save", post.ID)
+		return serve(t, http.MethodPost, "/posts/:id/save", path, reader.ID, input, h.SavePost).Code
+	}
+
+	if got := save(nil); got != http.StatusCreated {
+		t.Errorf("save = %d, want 201", got)
+	}
+	if got := save(nil); got != http.StatusOK {
+		t.Errorf("saving again = %d, want 200", got)
+	}
+	if got := save(saveInput{FolderID: &folder.ID}); got != http.StatusOK {
+		t.Errorf("moving into a folder = %d, want 200", got)
+	}
+
+	var items []models.SavedItem
+	db.Where("user_id = ?", reader.ID).Find(&items)
+	if len(items) != 1 {
+		t.Fatalf("%d saved items, want 1", len(items))
+	}
+	if items[0].FolderID == nil || *items[0].FolderID != folder.ID {
+		t.Errorf("saved item is in folder %v, want %d", items[0].FolderID, folder.ID)
+	}
+}
+
+func TestConcurrentSavesAreStoredOnce(t *testing.T) {
+	db := testdb.New(t)
+	h := NewSavedHandler(db)
+	author := createUser(t, db, "author", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	const attempts = 8
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			path := fmt.Sprintf("/posts/%d/save", post.ID)
+			codes <- serve(t, http.MethodPost, "/posts/:id/save", path, reader.ID, nil, h.SavePost).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusOK:
+		default:
+			t.Errorf("concurrent save = %d, want 201 or 200", code)
+		}
+	}
+	var count int64
+	db.Model(&models.SavedItem{}).Count(&count)
+	if created != 1 || count != 1 {
+		t.Errorf("%d saves created, %d stored; want 1", created, count)
+	}
+}
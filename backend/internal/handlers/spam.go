@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"log"
+	"time"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/spam"
+)
+
+// holdSpam scores new content and holds it for review if it looks like
+// spam, unless automod already held or removed it. The result carries the
+// hash and score to store with the content.
+func holdSpam(filter *spam.Filter, content spam.Content, status, reason *string) spam.Result {
+	result := filter.Score(content, time.Now())
+	if result.Spam && *status != models.ModStatusPending && *status != models.ModStatusRemoved {
+		*status = models.ModStatusPending
+		*reason = result.Reason()
+	}
+	return result
+}
+
+// learnFromReview trains the spam classifier on a moderator's decision.
+// Removals are spam; approving content that was held or flagged is not.
+func learnFromReview(filter *spam.Filter, content spam.Content, previous, status string) {
+	var isSpam bool
+	switch {
+	case status == previous:
+		return
+	case status == models.ModStatusRemoved:
+		isSpam = true
+	case previous == models.ModStatusPending || previous == models.ModStatusFlagged:
+		isSpam = false
+	default:
+		return
+	}
+	if err := filter.Learn(content, isSpam); err != nil {
+		log.Printf("failed to save spam classifier: %v", err)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
 )
 
 type UserHandler struct {
@@ -18,78 +21,98 @@ func NewUserHandler(db *gorm.DB) *UserHandler {
 	return &UserHandler{db: db}
 }
 
-// GetUserProfile returns a user's profile
-func (h *UserHandler) GetUserProfile(c *gin.Context) {
-	userID := c.Param("id")
+// profileOwner loads the :id user with their privacy settings and how the
+// caller relates to them, responding with 404 if there is no such user
+func (h *UserHandler) profileOwner(c *gin.Context) (models.User, models.PrivacySettings, policy.Audience, bool) {
 	var user models.User
+	if err := h.db.First(&user, c.Param("id")).Error; err != nil {
+		apierr.NotFound(c, "User not found")
+		return user, models.PrivacySettings{}, policy.Audience{}, false
+	}
 
-	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	viewerID, _ := extractUserID(c)
+	return user, policy.LoadPrivacy(h.db, user.ID), policy.AudienceOf(h.db, viewerID, user.ID), true
+}
+
+// GetUserProfile returns a user's profile. A private profile shows only the
+// user and "private": true to callers outside its audience.
+func (h *UserHandler) GetUserProfile(c *gin.Context) {
+	user, privacy, audience, ok := h.profileOwner(c)
+	if !ok {
+		return
+	}
+
+	if !policy.CanViewProfile(privacy, audience) {
+		c.JSON(http.StatusOK, gin.H{
+			"user":         publicUser(user),
+			"private":      true,
+			"is_following": audience.Follower,
+		})
 		return
 	}
 
 	// Get user's posts
 	var posts []models.Post
-	h.db.Where("user_id = ?", userID).Preload("User").Order("created_at desc").Find(&posts)
+	viewerID, _ := extractUserID(c)
+	h.db.Where("user_id = ?", user.ID).Scopes(preloadPostKinds, policy.VisiblePosts(viewerID)).Preload("User").Order("created_at desc").Find(&posts)
 
 	// Get follower/following counts
 	var followerCount, followingCount int64
-	h.db.Model(&models.Follow{}).Where("following_id = ?", userID).Count(&followerCount)
-	h.db.Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&followingCount)
+	h.db.Model(&models.Follow{}).Where("following_id = ?", user.ID).Count(&followerCount)
+	h.db.Model(&models.Follow{}).Where("follower_id = ?", user.ID).Count(&followingCount)
 
-	// Check if current user follows this user
-	isFollowing := false
-	if currentUserID, exists := c.Get("user_id"); exists {
-		var follow models.Follow
-		err := h.db.Where("follower_id = ? AND following_id = ?", currentUserID, userID).First(&follow).Error
-		isFollowing = err == nil
+	// Karma is maintained as votes change, so this is a single small query
+	totals, err := karma.Load(h.db, user.ID)
+	if err != nil {
+		apierr.Internal(c, "Failed to load karma")
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
-			"bio":      user.Bio,
-			"avatar":   user.Avatar,
-		},
-		"posts":           posts,
+		"user":            publicUser(user),
+		"private":         false,
+		"posts":           postViews(h.db, posts, viewerID),
 		"follower_count":  followerCount,
 		"following_count": followingCount,
-		"is_following":    isFollowing,
+		"is_following":    audience.Follower,
+		"karma":           totals,
 	})
 }
 
+// profileInput holds the editable profile fields
+type profileInput struct {
+	Bio           string `json:"bio"`
+	Avatar        string `json:"avatar"`
+	AvatarMediaID *int   `json:"avatar_media_id"`
+}
+
 func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
 	userID := c.Param("id")
 
 	// Get authenticated user ID from middleware
 	authUserID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierr.Unauthorized(c, "Unauthorized")
 		return
 	}
 
 	// Check if user is updating their own profile
 	if fmt.Sprintf("%v", authUserID) != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own profile"})
+		apierr.Forbidden(c, "You can only update your own profile")
 		return
 	}
 
-	var input struct {
-		Bio    string `json:"bio"`
-		Avatar string `json:"avatar"`
-	}
+	var input profileInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierr.Bind(c, err)
 		return
 	}
 
 	// Find user
 	var user models.User
 	if err := h.db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierr.NotFound(c, "User not found")
 		return
 	}
 
@@ -99,55 +122,68 @@ func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
 	}
 	if input.Avatar != "" {
 		user.Avatar = input.Avatar
+		user.AvatarMediaID = nil
+	}
+	if input.AvatarMediaID != nil {
+		avatar, err := findOwnedMedia(h.db, user.ID, *input.AvatarMediaID)
+		if err != nil {
+			apierr.Invalid(c, "avatar_media_id", err.Error())
+			return
+		}
+		user.Avatar = avatar.URL
+		user.AvatarMediaID = &avatar.ID
 	}
 
 	// Save to database
 	if err := h.db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		apierr.Internal(c, "Failed to update profile")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"id":       user.ID,
-		"username": user.Username,
-		"email":    user.Email,
-		"bio":      user.Bio,
-		"avatar":   user.Avatar,
-	})
+	c.JSON(http.StatusOK, meView(user))
 }
 
 // FollowUser follows a user
 func (h *UserHandler) FollowUser(c *gin.Context) {
 	followingID := c.Param("id")
-	followerID, _ := c.Get("user_id")
+	followerID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
 
 	// Can't follow yourself
 	var followingUser models.User
 	if err := h.db.First(&followingUser, followingID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierr.NotFound(c, "User not found")
+		return
+	}
+
+	if followingUser.ID == followerID {
+		apierr.BadRequest(c, "You cannot follow yourself")
 		return
 	}
 
-	if followingUser.ID == followerID.(int) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+	if policy.IsBlocked(h.db, followingUser.ID, followerID) {
+		apierr.Forbidden(c, "You cannot follow this user")
 		return
 	}
 
 	// Check if already following
 	var existingFollow models.Follow
-	err := h.db.Where("follower_id = ? AND following_id = ?", followerID, followingID).First(&existingFollow).Error
+	err := h.db.Where("follower_id = ? AND following_id = ?", followerID, followingUser.ID).First(&existingFollow).Error
 	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Already following this user"})
+		apierr.BadRequest(c, "Already following this user")
 		return
 	}
 
 	follow := models.Follow{
-		FollowerID:  followerID.(int),
+		FollowerID:  followerID,
 		FollowingID: followingUser.ID,
 	}
 
 	if err := h.db.Create(&follow).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		apierr.Internal(c, "Failed to follow user")
 		return
 	}
 
@@ -160,47 +196,134 @@ func (h *UserHandler) UnfollowUser(c *gin.Context) {
 	followerID, _ := c.Get("user_id")
 
 	if err := h.db.Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&models.Follow{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow"})
+		apierr.Internal(c, "Failed to unfollow")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully unfollowed user"})
 }
 
-// GetFollowers returns a user's followers
+// GetFollowers returns a user's followers, unless they hide them
 func (h *UserHandler) GetFollowers(c *gin.Context) {
-	userID := c.Param("id")
-	var follows []models.Follow
+	user, privacy, audience, ok := h.profileOwner(c)
+	if !ok {
+		return
+	}
+	if !policy.CanViewFollowers(privacy, audience) {
+		apierr.Forbidden(c, "This user's followers are private")
+		return
+	}
 
-	h.db.Where("following_id = ?", userID).Preload("Follower").Find(&follows)
+	var follows []models.Follow
+	h.db.Where("following_id = ?", user.ID).Preload("Follower").Find(&follows)
 
-	var followers []gin.H
+	followers := make([]PublicUser, 0, len(follows))
 	for _, follow := range follows {
-		followers = append(followers, gin.H{
-			"id":       follow.Follower.ID,
-			"username": follow.Follower.Username,
-			"avatar":   follow.Follower.Avatar,
-		})
+		followers = append(followers, publicUser(follow.Follower))
 	}
 
 	c.JSON(http.StatusOK, followers)
 }
 
-// GetFollowing returns users that a user is following
+// GetFollowing returns users that a user is following, unless they hide them
 func (h *UserHandler) GetFollowing(c *gin.Context) {
-	userID := c.Param("id")
-	var follows []models.Follow
+	user, privacy, audience, ok := h.profileOwner(c)
+	if !ok {
+		return
+	}
+	if !policy.CanViewFollowers(privacy, audience) {
+		apierr.Forbidden(c, "This user's follows are private")
+		return
+	}
 
-	h.db.Where("follower_id = ?", userID).Preload("Following").Find(&follows)
+	var follows []models.Follow
+	h.db.Where("follower_id = ?", user.ID).Preload("Following").Find(&follows)
 
-	var following []gin.H
+	following := make([]PublicUser, 0, len(follows))
 	for _, follow := range follows {
-		following = append(following, gin.H{
-			"id":       follow.Following.ID,
-			"username": follow.Following.Username,
-			"avatar":   follow.Following.Avatar,
-		})
+		following = append(following, publicUser(follow.Following))
 	}
 
 	c.JSON(http.StatusOK, following)
 }
+
+// GetUpvotedPosts lists the posts a user upvoted, most recent vote first.
+// Votes are private unless the user chose to show them.
+func (h *UserHandler) GetUpvotedPosts(c *gin.Context) {
+	user, privacy, audience, ok := h.profileOwner(c)
+	if !ok {
+		return
+	}
+	if !policy.CanViewVotes(privacy, audience) {
+		apierr.Forbidden(c, "This user's votes are private")
+		return
+	}
+
+	viewerID, _ := extractUserID(c)
+	limit, offset := pageParams(c)
+	var posts []models.Post
+	err := h.db.Preload("User").
+		Joins("JOIN votes ON votes.post_id = posts.id AND votes.user_id = ? AND votes.vote_type = 1", user.ID).
+		Scopes(preloadPostKinds, policy.VisiblePosts(viewerID), policy.UnmutedPosts(viewerID)).
+		Order("votes.created_at desc").
+		Limit(limit).Offset(offset).
+		Find(&posts).Error
+	if err != nil {
+		apierr.Internal(c, "Failed to fetch upvoted posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, postViews(h.db, posts, viewerID))
+}
+
+// GetPrivacy returns the caller's privacy settings
+func (h *UserHandler) GetPrivacy(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy.LoadPrivacy(h.db, userID))
+}
+
+// privacyInput changes some of the caller's privacy settings; omitted
+// fields keep their current value
+type privacyInput struct {
+	PrivateProfile *bool `json:"private_profile"`
+	HideFollowers  *bool `json:"hide_followers"`
+	HideVotes      *bool `json:"hide_votes"`
+}
+
+// UpdatePrivacy changes the caller's privacy settings
+func (h *UserHandler) UpdatePrivacy(c *gin.Context) {
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var input privacyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+
+	settings := policy.LoadPrivacy(h.db, userID)
+	if input.PrivateProfile != nil {
+		settings.PrivateProfile = *input.PrivateProfile
+	}
+	if input.HideFollowers != nil {
+		settings.HideFollowers = *input.HideFollowers
+	}
+	if input.HideVotes != nil {
+		settings.HideVotes = *input.HideVotes
+	}
+
+	if err := h.db.Save(&settings).Error; err != nil {
+		apierr.Internal(c, "Failed to update privacy settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// viewerState is the authenticated caller's vote and saved state for a page
+// of posts or comments, keyed by their IDs
+type viewerState struct {
+	votes map[int]int
+	saved map[int]bool
+}
+
+func (s viewerState) vote(id int) int     { return s.votes[id] }
+func (s viewerState) isSaved(id int) bool { return s.saved[id] }
+
+// loadViewerState fetches the viewer's votes and saves for the given posts
+// (column "post_id") or comments (column "comment_id") in two queries
+func loadViewerState(db *gorm.DB, viewerID int, column string, ids []int) viewerState {
+	state := viewerState{votes: make(map[int]int), saved: make(map[int]bool)}
+	if viewerID == 0 || len(ids) == 0 {
+		return state
+	}
+
+	var votes []models.Vote
+	db.Where("user_id = ? AND "+column+" IN ?", viewerID, ids).Find(&votes)
+	for _, v := range votes {
+		if column == "post_id" {
+			state.votes[v.PostID] = v.VoteType
+		} else {
+			state.votes[v.CommentID] = v.VoteType
+		}
+	}
+
+	var saved []models.SavedItem
+	db.Where("user_id = ? AND "+column+" IN ?", viewerID, ids).Find(&saved)
+	for _, s := range saved {
+		if column == "post_id" {
+			state.saved[s.PostID] = true
+		} else {
+			state.saved[s.CommentID] = true
+		}
+	}
+	return state
+}
+
+func postIDsOf(posts []models.Post) []int {
+	ids := make([]int, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func commentIDsOf(comments []models.Comment) []int {
+	ids := make([]int, len(comments))
+	for i, cm := range comments {
+		ids[i] = cm.ID
+	}
+	return ids
+}
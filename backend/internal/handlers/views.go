@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// Response types shared by every endpoint that returns users, posts or
+// comments. Build them with the mappers below rather than returning models,
+// so private fields such as email stay out of public responses.
+
+// PublicUser is the part of an account anyone may see
+type PublicUser struct {
+	ID            int       `json:"id"`
+	Username      string    `json:"username"`
+	Bio           string    `json:"bio"`
+	Avatar        string    `json:"avatar"`
+	AvatarMediaID *int      `json:"avatar_media_id,omitempty"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Me is the caller's own account, including its private fields
+type Me struct {
+	PublicUser
+	Email        string `json:"email"`
+	AuthProvider string `json:"auth_provider"`
+}
+
+// AuthResponse is returned by register and every login method
+type AuthResponse struct {
+	Message string `json:"message"`
+	Token   string `json:"token"`
+	User    Me     `json:"user"`
+}
+
+// PollView is a poll with its tallies and the viewer's choice
+type PollView struct {
+	ID            int                 `json:"id"`
+	Options       []models.PollOption `json:"options"`
+	TotalVotes    int                 `json:"total_votes"`
+	ClosesAt      time.Time           `json:"closes_at"`
+	Closed        bool                `json:"closed"`
+	VotedOptionID *int                `json:"voted_option_id,omitempty"`
+}
+
+// CrosspostParent is the part of the original post embedded in a crosspost
+type CrosspostParent struct {
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Kind        string     `json:"kind"`
+	Excerpt     string     `json:"excerpt"`
+	Image       string     `json:"image"`
+	URL         string     `json:"url"`
+	Domain      string     `json:"domain"`
+	Community   string     `json:"community"`
+	CommunityID int        `json:"community_id"`
+	User        PublicUser `json:"user"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PostView is a post as every endpoint returns it
+type PostView struct {
+	ID          int                 `json:"id"`
+	Title       string              `json:"title"`
+	Kind        string              `json:"kind"`
+	Body        string              `json:"body"`
+	BodyHTML    string              `json:"body_html"`
+	Excerpt     string              `json:"excerpt"`
+	Content     string              `json:"content"`
+	Image       string              `json:"image"`
+	URL         string              `json:"url"`
+	Domain      string              `json:"domain"`
+	Media       []models.PostMedia  `json:"media"`
+	Poll        *PollView           `json:"poll"`
+	Preview     *models.LinkPreview `json:"preview"`
+	UserID      int                 `json:"user_id"`
+	AuthorID    int                 `json:"author_id"`
+	User        PublicUser          `json:"user"`
+	Community   string              `json:"community"`
+	CommunityID int                 `json:"community_id"`
+	Upvotes     int                 `json:"upvotes"`
+	Downvotes   int                 `json:"downvotes"`
+	Comments    int                 `json:"comments"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	EditedAt    *time.Time          `json:"edited_at"`
+
+	// The viewer's own state
+	UserVote int  `json:"user_vote"`
+	Saved    bool `json:"saved"`
+
+	CrosspostParentID *int             `json:"crosspost_parent_id"`
+	CrosspostParent   *CrosspostParent `json:"crosspost_parent"`
+	CrosspostCount    int              `json:"crosspost_count"`
+
+	Flair     *models.FlairTemplate `json:"flair"`
+	UserFlair *models.UserFlair     `json:"user_flair"`
+	ModStatus string                `json:"mod_status"`
+}
+
+// CommentView is a comment as every endpoint returns it
+type CommentView struct {
+	ID              int        `json:"id"`
+	Body            string     `json:"body"`
+	BodyHTML        string     `json:"body_html"`
+	Excerpt         string     `json:"excerpt"`
+	AuthorID        int        `json:"author_id"`
+	PostID          int        `json:"post_id"`
+	ParentCommentID *int       `json:"parent_comment_id"`
+	User            PublicUser `json:"user"`
+	Upvotes         int        `json:"upvotes"`
+	Downvotes       int        `json:"downvotes"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	EditedAt        *time.Time `json:"edited_at"`
+
+	// The viewer's own state
+	UserVote int  `json:"user_vote"`
+	Saved    bool `json:"saved"`
+	// Collapsed is set on comments by users the viewer blocked
+	Collapsed bool `json:"collapsed"`
+
+	UserFlair *models.UserFlair `json:"user_flair"`
+	ModStatus string            `json:"mod_status"`
+}
+
+func publicUser(u models.User) PublicUser {
+	return PublicUser{
+		ID:            u.ID,
+		Username:      u.Username,
+		Bio:           u.Bio,
+		Avatar:        u.Avatar,
+		AvatarMediaID: u.AvatarMediaID,
+		Role:          u.Role,
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+func meView(u models.User) Me {
+	return Me{PublicUser: publicUser(u), Email: u.Email, AuthProvider: u.AuthProvider}
+}
+
+// pollView renders a poll with its tallies and the viewer's choice
+func pollView(poll *models.Poll, viewerVotes map[int]int) *PollView {
+	if poll == nil {
+		return nil
+	}
+
+	view := &PollView{ID: poll.ID, Options: poll.Options, ClosesAt: poll.ClosesAt, Closed: poll.Closed(time.Now())}
+	for _, opt := range poll.Options {
+		view.TotalVotes += opt.Votes
+	}
+	if optionID, ok := viewerVotes[poll.ID]; ok {
+		view.VotedOptionID = &optionID
+	}
+	return view
+}
+
+// voteCounts are the counted up- and downvotes of a post or comment
+type voteCounts struct{ up, down int }
+
+// loadVoteCounts tallies the votes on posts (column "post_id") or comments
+// (column "comment_id") in one query, leaving out flagged votes
+func loadVoteCounts(db *gorm.DB, column string, ids []int) map[int]voteCounts {
+	counts := make(map[int]voteCounts)
+	if len(ids) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		ID   int
+		Up   int
+		Down int
+	}
+	db.Model(&models.Vote{}).Scopes(countedVotes).
+		Select(column+" AS id, COUNT(*) FILTER (WHERE vote_type = 1) AS up, COUNT(*) FILTER (WHERE vote_type = -1) AS down").
+		Where(column+" IN ?", ids).
+		Group(column).
+		Scan(&rows)
+	for _, r := range rows {
+		counts[r.ID] = voteCounts{r.Up, r.Down}
+	}
+	return counts
+}
+
+// loadCommentCounts counts the comments on each post that the viewer can
+// see, in one query
+func loadCommentCounts(db *gorm.DB, postIDs []int, viewerID int) map[int]int {
+	counts := make(map[int]int)
+	if len(postIDs) == 0 {
+		return counts
+	}
+
+	var rows []struct {
+		ID    int
+		Count int
+	}
+	db.Model(&models.Comment{}).Scopes(policy.VisibleComments(viewerID)).
+		Select("comments.post_id AS id, COUNT(*) AS count").
+		Where("comments.post_id IN ?", postIDs).
+		Group("comments.post_id").
+		Scan(&rows)
+	for _, r := range rows {
+		counts[r.ID] = r.Count
+	}
+	return counts
+}
+
+// postViews builds the response for posts loaded with their User and kinds
+// (see preloadPostKinds), as seen by the viewer (0 if anonymous)
+func postViews(db *gorm.DB, posts []models.Post, viewerID int) []PostView {
+	ensurePostsRendered(db, posts)
+	ids := postIDsOf(posts)
+	votes := loadVoteCounts(db, "post_id", ids)
+	comments := loadCommentCounts(db, ids, viewerID)
+	pollVotes := loadPollVotes(db, posts, viewerID)
+	previews := loadPreviews(db, posts)
+	viewer := loadViewerState(db, viewerID, "post_id", ids)
+	crossposts := loadCrosspostInfo(db, posts, viewerID)
+	userFlair := loadUserFlair(db, postFlairKeys(posts))
+
+	views := make([]PostView, len(posts))
+	for i, post := range posts {
+		views[i] = PostView{
+			ID:                post.ID,
+			Title:             post.Title,
+			Kind:              post.Kind,
+			Body:              post.Body,
+			BodyHTML:          post.BodyHTML,
+			Excerpt:           post.Excerpt,
+			Content:           post.Content,
+			Image:             post.Image,
+			URL:               post.URL,
+			Domain:            post.Domain,
+			Media:             post.Media,
+			Poll:              pollView(post.Poll, pollVotes),
+			Preview:           previews[post.PreviewURL],
+			UserID:            post.UserID,
+			AuthorID:          post.AuthorID,
+			User:              publicUser(post.User),
+			Community:         post.Community,
+			CommunityID:       post.CommunityID,
+			Upvotes:           votes[post.ID].up,
+			Downvotes:         votes[post.ID].down,
+			Comments:          comments[post.ID],
+			CreatedAt:         post.CreatedAt,
+			UpdatedAt:         post.UpdatedAt,
+			EditedAt:          post.EditedAt,
+			UserVote:          viewer.vote(post.ID),
+			Saved:             viewer.isSaved(post.ID),
+			CrosspostParentID: post.CrosspostParentID,
+			CrosspostParent:   crossposts.parent(post),
+			CrosspostCount:    crossposts.counts[post.ID],
+			Flair:             post.Flair,
+			UserFlair:         userFlair[flairKey{post.CommunityID, post.UserID}],
+			ModStatus:         post.ModStatus,
+		}
+		if views[i].Media == nil {
+			views[i].Media = []models.PostMedia{}
+		}
+	}
+	return views
+}
+
+// postView builds the response for a single post
+func postView(db *gorm.DB, post models.Post, viewerID int) PostView {
+	return postViews(db, []models.Post{post}, viewerID)[0]
+}
+
+// commentViews builds the response for comments loaded with their User, as
+// seen by the viewer (0 if anonymous)
+func commentViews(db *gorm.DB, comments []models.Comment, viewerID int) []CommentView {
+	ensureCommentsRendered(db, comments)
+	ids := commentIDsOf(comments)
+	votes := loadVoteCounts(db, "comment_id", ids)
+	viewer := loadViewerState(db, viewerID, "comment_id", ids)
+	blocked := policy.BlockedIDs(db, viewerID)
+
+	// User flair belongs to the community of each comment's post
+	postIDs := make([]int, 0, len(comments))
+	for _, cm := range comments {
+		postIDs = append(postIDs, cm.PostID)
+	}
+	communityOf := make(map[int]int)
+	if len(postIDs) > 0 {
+		var posts []models.Post
+		db.Select("id", "community_id").Where("id IN ?", postIDs).Find(&posts)
+		for _, p := range posts {
+			communityOf[p.ID] = p.CommunityID
+		}
+	}
+	flairKeys := make([]flairKey, len(comments))
+	for i, cm := range comments {
+		flairKeys[i] = flairKey{communityOf[cm.PostID], cm.AuthorID}
+	}
+	userFlair := loadUserFlair(db, flairKeys)
+
+	views := make([]CommentView, len(comments))
+	for i, cm := range comments {
+		view := CommentView{
+			ID:              cm.ID,
+			Body:            cm.Body,
+			BodyHTML:        cm.BodyHTML,
+			Excerpt:         cm.Excerpt,
+			AuthorID:        cm.AuthorID,
+			PostID:          cm.PostID,
+			ParentCommentID: cm.ParentCommentID,
+			User:            publicUser(cm.User),
+			Upvotes:         votes[cm.ID].up,
+			Downvotes:       votes[cm.ID].down,
+			CreatedAt:       cm.CreatedAt,
+			UpdatedAt:       cm.UpdatedAt,
+			EditedAt:        cm.EditedAt,
+			UserVote:        viewer.vote(cm.ID),
+			Saved:           viewer.isSaved(cm.ID),
+			UserFlair:       userFlair[flairKeys[i]],
+			ModStatus:       cm.ModStatus,
+		}
+		// Comments by blocked users stay in place so threads keep their
+		// shape, but their content is withheld
+		if blocked[cm.AuthorID] {
+			view.Collapsed = true
+			view.Body, view.BodyHTML, view.Excerpt = "", "", ""
+		}
+		views[i] = view
+	}
+	return views
+}
+
+// commentView builds the response for a single comment
+func commentView(db *gorm.DB, comment models.Comment, viewerID int) CommentView {
+	return commentViews(db, []models.Comment{comment}, viewerID)[0]
+}
+
+// LoadPosts returns the posts with the given IDs that the viewer (0 if
+// anonymous) may see, in the order of ids. It backs the batched lookups of
+// the GraphQL and gRPC APIs.
+func LoadPosts(db *gorm.DB, ids []int, viewerID int) ([]PostView, error) {
+	var posts []models.Post
+	if len(ids) > 0 {
+		err := db.Preload("User").Scopes(preloadPostKinds, policy.VisiblePosts(viewerID)).Where("posts.id IN ?", ids).Find(&posts).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inOrder(ids, postViews(db, posts, viewerID), func(v PostView) int { return v.ID }), nil
+}
+
+// LoadComments returns the comments with the given IDs that the viewer may
+// see, in the order of ids
+func LoadComments(db *gorm.DB, ids []int, viewerID int) ([]CommentView, error) {
+	var comments []models.Comment
+	if len(ids) > 0 {
+		err := db.Preload("User").Scopes(policy.VisibleComments(viewerID)).Where("comments.id IN ?", ids).Find(&comments).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inOrder(ids, commentViews(db, comments, viewerID), func(v CommentView) int { return v.ID }), nil
+}
+
+// LoadUsers returns the users with the given IDs or usernames
+// (case-insensitive), those found by ID first, each in request order
+func LoadUsers(db *gorm.DB, ids []int, usernames []string) ([]PublicUser, error) {
+	var byID, byName []models.User
+	if len(ids) > 0 {
+		if err := db.Where("id IN ?", ids).Find(&byID).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(usernames) > 0 {
+		lower := make([]string, len(usernames))
+		for i, name := range usernames {
+			lower[i] = strings.ToLower(name)
+		}
+		if err := db.Where("LOWER(username) IN ?", lower).Find(&byName).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	users := inOrder(ids, byID, func(u models.User) int { return u.ID })
+	seen := make(map[int]bool, len(users))
+	for _, u := range users {
+		seen[u.ID] = true
+	}
+	for _, name := range usernames {
+		for _, u := range byName {
+			if strings.EqualFold(u.Username, name) && !seen[u.ID] {
+				seen[u.ID] = true
+				users = append(users, u)
+			}
+		}
+	}
+
+	out := make([]PublicUser, len(users))
+	for i, u := range users {
+		out[i] = publicUser(u)
+	}
+	return out, nil
+}
+
+// inOrder arranges items in the order of ids, leaving out missing and
+// repeated IDs
+func inOrder[T any](ids []int, items []T, id func(T) int) []T {
+	byID := make(map[int]T, len(items))
+	for _, item := range items {
+		byID[id(item)] = item
+	}
+	out := make([]T, 0, len(items))
+	for _, i := range ids {
+		if item, ok := byID[i]; ok {
+			out = append(out, item)
+			delete(byID, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestPublicUserOmitsPrivateFields(t *testing.T) {
+	user := models.User{ID: 1, Username: "alice", Email: "alice@example.com", AuthProvider: "google"}
+
+	public, err := json.Marshal(publicUser(user))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, private := range []string{"email", "auth_provider", "alice@example.com"} {
+		if strings.Contains(string(public), private) {
+			t.Errorf("public user %s contains %q", public, private)
+		}
+	}
+
+	me, err := json.Marshal(meView(user))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(me, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["email"] != "alice@example.com" || fields["username"] != "alice" {
+		t.Errorf("me = %s", me)
+	}
+}
+
+func TestPollView(t *testing.T) {
+	poll := &models.Poll{
+		ID:       7,
+		ClosesAt: time.Now().Add(time.Hour),
+		Options:  []models.PollOption{{ID: 1, Votes: 2}, {ID: 2, Votes: 3}},
+	}
+
+	view := pollView(poll, map[int]int{7: 2})
+	if view.TotalVotes != 5 || view.Closed {
+		t.Errorf("view = %+v", view)
+	}
+	if view.VotedOptionID == nil || *view.VotedOptionID != 2 {
+		t.Errorf("voted option = %v", view.VotedOptionID)
+	}
+
+	if v := pollView(poll, nil); v.VotedOptionID != nil {
+		t.Errorf("anonymous viewer has a voted option: %v", *v.VotedOptionID)
+	}
+	if pollView(nil, nil) != nil {
+		t.Error("expected nil for a post without a poll")
+	}
+}
+
+func TestPostViewsCountComments(t *testing.T) {
+	db := testdb.New(t)
+	h := NewCommentHandler(db, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	commenter := createUser(t, db, "commenter", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	count := func(viewerID int) int {
+		return postView(db, post, viewerID).Comments
+	}
+	if got := count(0); got != 0 {
+		t.Fatalf("new post has %d comments", got)
+	}
+
+	if _, e := h.create(post, commenter.ID, "First comment on this post"); e != nil {
+		t.Fatal(e)
+	}
+	if got := count(0); got != 1 {
+		t.Errorf("after commenting, post has %d comments, want 1", got)
+	}
+
+	held := models.Comment{Body: "held", AuthorID: commenter.ID, PostID: post.ID, ModStatus: models.ModStatusPending}
+	db.Create(&held)
+	if got := count(0); got != 1 {
+		t.Errorf("held comments are counted for other viewers: %d", got)
+	}
+	if got := count(commenter.ID); got != 2 {
+		t.Errorf("author of a held comment sees %d comments, want 2", got)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// castVote records a user's vote on a post or comment: repeating a vote
+// removes it and the opposite vote replaces it. The author's karma moves by
+// the difference in the same transaction; flagged votes already don't count
+// towards it. It returns the response message and the change in the target's
+// counted score.
+func castVote(db *gorm.DB, vote models.Vote, target karma.Target) (string, int, error) {
+	message, delta, err := castVoteOnce(db, vote, target)
+	if database.IsUniqueViolation(err) {
+		// The same vote was recorded concurrently after we looked for one;
+		// this one now finds it and toggles it
+		message, delta, err = castVoteOnce(db, vote, target)
+	}
+	return message, delta, err
+}
+
+func castVoteOnce(db *gorm.DB, vote models.Vote, target karma.Target) (string, int, error) {
+	var message string
+	var delta int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Locking the vote makes concurrent votes by the same user take
+		// turns, so each sees the last one's result
+		var existing models.Vote
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", vote.UserID)
+		if vote.CommentID != 0 {
+			query = query.Where("comment_id = ?", vote.CommentID)
+		} else {
+			query = query.Where("post_id = ?", vote.PostID)
+		}
+		found := query.Limit(1).Find(&existing).RowsAffected > 0
+
+		prev := 0
+		if found {
+			prev = existing.VoteType
+		}
+		next := karma.Next(prev, vote.VoteType)
+
+		var err error
+		switch {
+		case next == 0:
+			message = "Vote removed"
+			err = tx.Delete(&existing).Error
+		case found:
+			message = "Vote updated"
+			existing.VoteType = next
+			err = tx.Save(&existing).Error
+		default:
+			message = "Vote recorded"
+			err = tx.Create(&vote).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if found && existing.FlaggedAt != nil {
+			return nil
+		}
+		delta = next - prev
+		return karma.Apply(tx, target, karma.Delta(target, vote.UserID, prev, next))
+	})
+	return message, delta, err
+}
+
+// withdrawVotes deletes the votes on posts or comments that are being
+// deleted and takes back the karma they earned, matching karma.Rebuild,
+// which leaves out votes on deleted content. authors maps each post or
+// comment ID to its author.
+func withdrawVotes(tx *gorm.DB, kind karma.Kind, communityID int, authors map[int]int) error {
+	if len(authors) == 0 {
+		return nil
+	}
+	column := "post_id"
+	if kind == karma.Comment {
+		column = "comment_id"
+	}
+	ids := make([]int, 0, len(authors))
+	for id := range authors {
+		ids = append(ids, id)
+	}
+
+	// Returning the deleted rows takes back exactly the votes removed, even
+	// if more were cast since the content was loaded
+	var deleted []models.Vote
+	if err := tx.Clauses(clause.Returning{}).Where(column+" IN ?", ids).Delete(&deleted).Error; err != nil {
+		return err
+	}
+
+	earned := make(map[int]int)
+	for _, v := range deleted {
+		if v.FlaggedAt != nil {
+			continue
+		}
+		id := v.PostID
+		if kind == karma.Comment {
+			id = v.CommentID
+		}
+		target := karma.Target{Kind: kind, AuthorID: authors[id], CommunityID: communityID}
+		earned[target.AuthorID] += karma.Delta(target, v.UserID, v.VoteType, 0)
+	}
+	for authorID, delta := range earned {
+		if err := karma.Apply(tx, karma.Target{Kind: kind, AuthorID: authorID, CommunityID: communityID}, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// voteMilestones are the scores at which a vote.milestone event is published
+var voteMilestones = []int{10, 100, 1000, 10000}
+
+// crossedMilestone returns the highest milestone a score change from before
+// to after reached, or 0
+func crossedMilestone(before, after int) int {
+	reached := 0
+	for _, m := range voteMilestones {
+		if before < m && after >= m {
+			reached = m
+		}
+	}
+	return reached
+}
+
+// announceMilestone publishes a vote.milestone event if a vote that changed
+// a post's or comment's score by delta lifted it to a milestone. e names the
+// post or comment.
+func announceMilestone(db *gorm.DB, bus *events.Bus, e events.Event, delta int) {
+	if delta <= 0 {
+		return
+	}
+	column, id := "post_id", e.PostID
+	if e.CommentID != 0 {
+		column, id = "comment_id", e.CommentID
+	}
+	counts := loadVoteCounts(db, column, []int{id})[id]
+	score := counts.up - counts.down
+	if m := crossedMilestone(score-delta, score); m > 0 {
+		e.Type = events.VoteMilestone
+		e.Milestone = m
+		bus.Publish(e)
+	}
+}
+
+// fingerprintHeader carries a client-generated device fingerprint
+const fingerprintHeader = "X-Device-Fingerprint"
+
+// recordVoteSource notes the network and device a vote came from
+func recordVoteSource(c *gin.Context, vote *models.Vote) {
+	vote.IP = c.ClientIP()
+	if fp := c.GetHeader(fingerprintHeader); len(fp) <= 128 {
+		vote.Fingerprint = fp
+	}
+}
+
+// countedVotes leaves out votes flagged as manipulated
+func countedVotes(db *gorm.DB) *gorm.DB {
+	return db.Where("flagged_at IS NULL")
+}
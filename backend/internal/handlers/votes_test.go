@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestCrossedMilestone(t *testing.T) {
+	tests := []struct {
+		before, after, want int
+	}{
+		{8, 9, 0},
+		{9, 10, 10},
+		{9, 11, 10}, // a downvote turned into an upvote
+		{10, 11, 0},
+		{99, 100, 100},
+		{-1, 1, 0},
+		{9999, 10000, 10000},
+	}
+	for _, tt := range tests {
+		if got := crossedMilestone(tt.before, tt.after); got != tt.want {
+			t.Errorf("crossedMilestone(%d, %d) = %d, want %d", tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCastVote(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, nil, nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	voter := createUser(t, db, "voter", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	steps := []struct {
+		voteType int
+		message  string
+		karma    int
+	}{
+		{1, "Vote recorded", 1},
+		{1, "Vote removed", 0},
+		{-1, "Vote recorded", -1},
+		{1, "Vote updated", 1},
+	}
+	for i, step := range steps {
+		message, e := h.vote(models.Vote{UserID: voter.ID, PostID: post.ID, VoteType: step.voteType})
+		if e != nil {
+			t.Fatalf("step %d: %v", i, e)
+		}
+		if message != step.message {
+			t.Errorf("step %d: message %q, want %q", i, message, step.message)
+		}
+		if got := karma.Of(db, author.ID); got != step.karma {
+			t.Errorf("step %d: karma %d, want %d", i, got, step.karma)
+		}
+	}
+}
+
+func TestConcurrentVotesKeepKarmaInStep(t *testing.T) {
+	db := testdb.New(t)
+	h := NewPostHandler(db, nil, nil, nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	const voters, attempts = 4, 5
+	var wg sync.WaitGroup
+	for i := 0; i < voters; i++ {
+		voter := createUser(t, db, fmt.Sprintf("voter%d", i), models.RoleUser)
+		for j := 0; j < attempts; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, e := h.vote(models.Vote{UserID: voter.ID, PostID: post.ID, VoteType: 1}); e != nil {
+					t.Errorf("vote: %v", e)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	var votes []models.Vote
+	db.Where("post_id = ?", post.ID).Find(&votes)
+	// An odd number of identical votes from each voter leaves one upvote
+	if len(votes) != voters {
+		t.Errorf("%d votes stored, want %d", len(votes), voters)
+	}
+	if got := karma.Of(db, author.ID); got != len(votes) {
+		t.Errorf("karma %d, want %d from the stored votes", got, len(votes))
+	}
+}
+
+func TestDeletingContentTakesBackKarma(t *testing.T) {
+	db := testdb.New(t)
+	posts := NewPostHandler(db, nil, nil, nil)
+	comments := NewCommentHandler(db, newSpamFilter(db), nil)
+	author := createUser(t, db, "author", models.RoleUser)
+	commenter := createUser(t, db, "commenter", models.RoleUser)
+	voter := createUser(t, db, "voter", models.RoleUser)
+	post := createPost(t, db, author, createCommunity(t, db, "gaming", author))
+
+	newComment := func() models.Comment {
+		comment, e := comments.create(post, commenter.ID, "A comment worth voting on")
+		if e != nil {
+			t.Fatal(e)
+		}
+		if _, e := comments.vote(models.Vote{UserID: voter.ID, CommentID: comment.ID, VoteType: 1}); e != nil {
+			t.Fatal(e)
+		}
+		return comment
+	}
+
+	first := newComment()
+	newComment()
+	if got := karma.Of(db, commenter.ID); got != 2 {
+		t.Fatalf("commenter karma %d, want 2", got)
+	}
+
+	path := fmt.Sprintf("/comments/%d", first.ID)
+	if w := serve(t, http.MethodDelete, "/comments/:commentId", path, commenter.ID, nil, comments.DeleteComment); w.Code != http.StatusOK {
+		t.Fatalf("delete comment: status %d, body %s", w.Code, w.Body)
+	}
+	if got := karma.Of(db, commenter.ID); got != 1 {
+		t.Errorf("after deleting a comment, commenter karma %d, want 1", got)
+	}
+
+	if _, e := posts.vote(models.Vote{UserID: voter.ID, PostID: post.ID, VoteType: 1}); e != nil {
+		t.Fatal(e)
+	}
+	path = fmt.Sprintf("/posts/%d", post.ID)
+	if w := serve(t, http.MethodDelete, "/posts/:id", path, author.ID, nil, posts.DeletePost); w.Code != http.StatusOK {
+		t.Fatalf("delete post: status %d, body %s", w.Code, w.Body)
+	}
+	if got := karma.Of(db, author.ID); got != 0 {
+		t.Errorf("after deleting the post, author karma %d, want 0", got)
+	}
+	if got := karma.Of(db, commenter.ID); got != 0 {
+		t.Errorf("after deleting the post, commenter karma %d, want 0", got)
+	}
+
+	var left int64
+	db.Model(&models.Vote{}).Count(&left)
+	if left != 0 {
+		t.Errorf("%d votes left on deleted content", left)
+	}
+
+	// Karma kept in step agrees with karma recomputed from the votes
+	if err := karma.Rebuild(db); err != nil {
+		t.Fatal(err)
+	}
+	if got := karma.Of(db, commenter.ID) + karma.Of(db, author.ID); got != 0 {
+		t.Errorf("rebuilt karma %d, want 0", got)
+	}
+}
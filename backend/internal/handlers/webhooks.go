@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/webhook"
+)
+
+// WebhookHandler manages community and app webhooks and their deliveries
+type WebhookHandler struct {
+	db         *gorm.DB
+	dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookHandler(db *gorm.DB, dispatcher *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{db: db, dispatcher: dispatcher}
+}
+
+// webhookInput registers a webhook
+type webhookInput struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	URL    string   `json:"url" binding:"required,url,max=2000"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+// updateWebhookInput holds the changed fields of a webhook
+type updateWebhookInput struct {
+	Name   *string   `json:"name" binding:"omitempty,max=100"`
+	URL    *string   `json:"url" binding:"omitempty,url,max=2000"`
+	Events *[]string `json:"events" binding:"omitempty,min=1"`
+	Active *bool     `json:"active"`
+}
+
+// createdWebhook is a new webhook with its signing secret, which is not
+// shown again
+type createdWebhook struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// checkWebhook writes a validation error and returns false if the URL is
+// not http(s) or an event is unknown
+func checkWebhook(c *gin.Context, rawURL string, subscribed []string) bool {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		apierr.Invalid(c, "url", "url must be an http or https URL")
+		return false
+	}
+	for _, event := range subscribed {
+		if !webhook.Known(event) {
+			apierr.Invalid(c, "events", fmt.Sprintf("unknown event %q; expected one of %v", event, webhook.Events))
+			return false
+		}
+	}
+	return true
+}
+
+// caller loads the authenticated user, writing an error response if there
+// is none
+func (h *WebhookHandler) caller(c *gin.Context) (models.User, bool) {
+	var user models.User
+	userID, ok := extractUserID(c)
+	if !ok {
+		apierr.Unauthorized(c, "User not authenticated")
+		return user, false
+	}
+	if err := h.db.First(&user, userID).Error; err != nil {
+		apierr.Unauthorized(c, "User not found")
+		return user, false
+	}
+	return user, true
+}
+
+// loadCommunity resolves the :name community if the caller moderates it
+func (h *WebhookHandler) loadCommunity(c *gin.Context) (*models.Community, models.User, bool) {
+	user, ok := h.caller(c)
+	if !ok {
+		return nil, user, false
+	}
+	community, err := findCommunity(h.db, c.Param("name"))
+	if err != nil {
+		apierr.NotFound(c, "Community not found")
+		return nil, user, false
+	}
+	if !policy.IsModerator(h.db, user, *community) {
+		apierr.Forbidden(c, "Only moderators can manage a community's webhooks")
+		return nil, user, false
+	}
+	return community, user, true
+}
+
+// loadAdmin returns the caller if they are an admin; app webhooks see every
+// community, so only admins manage them
+func (h *WebhookHandler) loadAdmin(c *gin.Context) (models.User, bool) {
+	user, ok := h.caller(c)
+	if !ok {
+		return user, false
+	}
+	if user.Role != models.RoleAdmin {
+		apierr.Forbidden(c, "Only admins can manage app webhooks")
+		return user, false
+	}
+	return user, true
+}
+
+// loadWebhook resolves the :webhookId webhook if the caller may manage it
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (models.Webhook, bool) {
+	var hook models.Webhook
+	user, ok := h.caller(c)
+	if !ok {
+		return hook, false
+	}
+	if err := h.db.First(&hook, c.Param("webhookId")).Error; err != nil {
+		apierr.NotFound(c, "Webhook not found")
+		return hook, false
+	}
+
+	allowed := user.Role == models.RoleAdmin
+	if !allowed && hook.CommunityID != nil {
+		var community models.Community
+		allowed = h.db.First(&community, *hook.CommunityID).Error == nil && policy.IsModerator(h.db, user, community)
+	}
+	if !allowed {
+		// Webhooks the caller cannot manage are not revealed
+		apierr.NotFound(c, "Webhook not found")
+		return hook, false
+	}
+	return hook, true
+}
+
+// create registers a webhook for a community, or an app webhook if
+// communityID is nil
+func (h *WebhookHandler) create(c *gin.Context, communityID *int, creatorID int) {
+	var input webhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+	if !checkWebhook(c, input.URL, input.Events) {
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		apierr.Internal(c, "Failed to create webhook")
+		return
+	}
+	slices.Sort(input.Events)
+	hook := models.Webhook{
+		CommunityID: communityID,
+		CreatedByID: creatorID,
+		Name:        input.Name,
+		URL:         input.URL,
+		Events:      slices.Compact(input.Events),
+		Secret:      secret,
+		Active:      true,
+	}
+	if err := h.db.Create(&hook).Error; err != nil {
+		apierr.Internal(c, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdWebhook{Webhook: hook, Secret: secret})
+}
+
+// CreateCommunityWebhook registers a webhook for a community's events
+// (moderators only). The response includes the signing secret, which is not
+// shown again.
+func (h *WebhookHandler) CreateCommunityWebhook(c *gin.Context) {
+	community, user, ok := h.loadCommunity(c)
+	if !ok {
+		return
+	}
+	h.create(c, &community.ID, user.ID)
+}
+
+// GetCommunityWebhooks lists a community's webhooks (moderators only)
+func (h *WebhookHandler) GetCommunityWebhooks(c *gin.Context) {
+	community, _, ok := h.loadCommunity(c)
+	if !ok {
+		return
+	}
+
+	hooks := []models.Webhook{}
+	if err := h.db.Where("community_id = ?", community.ID).Order("id asc").Find(&hooks).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch webhooks")
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// CreateAppWebhook registers a webhook for events from every community
+// (admins only)
+func (h *WebhookHandler) CreateAppWebhook(c *gin.Context) {
+	user, ok := h.loadAdmin(c)
+	if !ok {
+		return
+	}
+	h.create(c, nil, user.ID)
+}
+
+// GetAppWebhooks lists the app webhooks (admins only)
+func (h *WebhookHandler) GetAppWebhooks(c *gin.Context) {
+	if _, ok := h.loadAdmin(c); !ok {
+		return
+	}
+
+	hooks := []models.Webhook{}
+	if err := h.db.Where("community_id IS NULL").Order("id asc").Find(&hooks).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch webhooks")
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// UpdateWebhook changes a webhook's name, URL, events or whether it is
+// active
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var input updateWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierr.Bind(c, err)
+		return
+	}
+	if input.Name != nil {
+		hook.Name = *input.Name
+	}
+	if input.URL != nil {
+		hook.URL = *input.URL
+	}
+	if input.Events != nil {
+		subscribed := slices.Clone(*input.Events)
+		slices.Sort(subscribed)
+		hook.Events = slices.Compact(subscribed)
+	}
+	if input.Active != nil {
+		hook.Active = *input.Active
+	}
+	if !checkWebhook(c, hook.URL, hook.Events) {
+		return
+	}
+
+	if err := h.db.Select("name", "url", "events", "active").Updates(&hook).Error; err != nil {
+		apierr.Internal(c, "Failed to update webhook")
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook removes a webhook and its delivery history
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&hook).Error
+	})
+	if err != nil {
+		apierr.Internal(c, "Failed to delete webhook")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetDeliveries lists a webhook's deliveries, newest first. ?status=dead
+// shows the dead-letter log: deliveries that ran out of attempts.
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	query := h.db.Where("webhook_id = ?", hook.ID)
+	switch status := c.Query("status"); status {
+	case "":
+	case models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+		query = query.Where("status = ?", status)
+	default:
+		apierr.BadRequest(c, "status must be pending, delivered or dead")
+		return
+	}
+
+	limit, offset := pageParams(c)
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Order("id desc").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		apierr.Internal(c, "Failed to fetch deliveries")
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver queues a delivered or dead delivery again, with a fresh set of
+// attempts
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := h.db.Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), hook.ID).First(&delivery).Error; err != nil {
+		apierr.NotFound(c, "Delivery not found")
+		return
+	}
+	if delivery.Status == models.DeliveryPending {
+		apierr.Conflict(c, "Delivery is already queued")
+		return
+	}
+	if !hook.Active {
+		apierr.Conflict(c, "Enable the webhook before redelivering")
+		return
+	}
+
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	if err := h.db.Select("status", "attempts", "next_attempt_at", "last_error").Updates(&delivery).Error; err != nil {
+		apierr.Internal(c, "Failed to queue delivery")
+		return
+	}
+	h.dispatcher.Notify()
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// webhookData builds the data of webhook payloads. Content is included as an
+// anonymous visitor sees it, so events about content that is not visible,
+// such as a post held for review, are not delivered; reports and bans are
+// delivered regardless.
+func webhookData(db *gorm.DB) webhook.DataFunc {
+	return func(e events.Event) (any, error) {
+		data := gin.H{}
+		if e.CommentID != 0 {
+			comments, err := LoadComments(db, []int{e.CommentID}, 0)
+			if err != nil {
+				return nil, err
+			}
+			if len(comments) > 0 {
+				data["comment"] = comments[0]
+			}
+		} else if e.PostID != 0 {
+			posts, err := LoadPosts(db, []int{e.PostID}, 0)
+			if err != nil {
+				return nil, err
+			}
+			if len(posts) > 0 {
+				data["post"] = posts[0]
+			}
+		}
+
+		switch e.Type {
+		case events.PostCreated, events.CommentCreated:
+			if len(data) == 0 {
+				return nil, nil
+			}
+		case events.VoteMilestone:
+			if len(data) == 0 {
+				return nil, nil
+			}
+			data["milestone"] = e.Milestone
+		case events.ReportFiled:
+			var report models.Report
+			if err := db.First(&report, e.ReportID).Error; err != nil {
+				return nil, err
+			}
+			data["report"] = report
+		case events.UserBanned:
+			var ban models.Ban
+			if err := db.First(&ban, e.BanID).Error; err != nil {
+				return nil, err
+			}
+			users, err := LoadUsers(db, []int{ban.UserID}, nil)
+			if err != nil || len(users) == 0 {
+				return nil, err
+			}
+			data["ban"] = ban
+			data["user"] = users[0]
+		}
+		return data, nil
+	}
+}
@@ -0,0 +1,137 @@
+// Package karma keeps each user's reputation up to date as votes change.
+// Karma is stored per user and community and adjusted by the difference a
+// vote makes, so reading it never scans the votes table.
+package karma
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// Kind is the type of content a vote was cast on
+type Kind int
+
+const (
+	Post Kind = iota
+	Comment
+)
+
+func (k Kind) column() string {
+	if k == Comment {
+		return "comment_karma"
+	}
+	return "post_karma"
+}
+
+// Target identifies who earns the karma for a piece of content
+type Target struct {
+	Kind        Kind
+	AuthorID    int
+	CommunityID int
+}
+
+// Next is the vote a user holds after requesting a vote of requested while
+// holding prev. Repeating a vote removes it (0); the opposite vote replaces it.
+func Next(prev, requested int) int {
+	if prev == requested {
+		return 0
+	}
+	return requested
+}
+
+// Delta is the change in the author's karma when a voter's vote goes from
+// prev to next. Votes on your own content never count.
+func Delta(t Target, voterID, prev, next int) int {
+	if voterID == t.AuthorID {
+		return 0
+	}
+	return next - prev
+}
+
+// Apply adds delta to the author's karma in the target's community
+func Apply(tx *gorm.DB, t Target, delta int) error {
+	if delta == 0 || t.AuthorID == 0 {
+		return nil
+	}
+
+	row := models.Karma{UserID: t.AuthorID, CommunityID: t.CommunityID}
+	if t.Kind == Comment {
+		row.CommentKarma = delta
+	} else {
+		row.PostKarma = delta
+	}
+
+	column := t.Kind.column()
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "community_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: column}, Value: gorm.Expr("karmas."+column+" + ?", delta)},
+			{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("CURRENT_TIMESTAMP")},
+		},
+	}).Create(&row).Error
+}
+
+// Totals is a user's karma overall and per community
+type Totals struct {
+	PostKarma    int `json:"post_karma"`
+	CommentKarma int `json:"comment_karma"`
+	Total        int `json:"total"`
+	// Communities breaks karma down by community, excluding karma earned
+	// outside communities
+	Communities []models.Karma `json:"communities"`
+}
+
+// Sum totals a user's karma rows
+func Sum(rows []models.Karma) Totals {
+	t := Totals{Communities: []models.Karma{}}
+	for _, r := range rows {
+		t.PostKarma += r.PostKarma
+		t.CommentKarma += r.CommentKarma
+		if r.CommunityID != 0 {
+			t.Communities = append(t.Communities, r)
+		}
+	}
+	t.Total = t.PostKarma + t.CommentKarma
+	return t
+}
+
+// Load returns a user's karma, with communities ordered by total karma
+func Load(db *gorm.DB, userID int) (Totals, error) {
+	var rows []models.Karma
+	err := db.Where("user_id = ?", userID).
+		Order("post_karma + comment_karma desc, community_id asc").
+		Find(&rows).Error
+	return Sum(rows), err
+}
+
+// Of returns a user's total karma
+func Of(db *gorm.DB, userID int) int {
+	var total int
+	db.Model(&models.Karma{}).
+		Select("COALESCE(SUM(post_karma + comment_karma), 0)").
+		Where("user_id = ?", userID).Scan(&total)
+	return total
+}
+
+// Rebuild recomputes every user's karma from the votes table. It is run
+// once when the karma table is first created so existing votes count.
+func Rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.Karma{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO karmas (user_id, community_id, post_karma, comment_karma, updated_at)
+			SELECT author_id, community_id, SUM(post_karma), SUM(comment_karma), CURRENT_TIMESTAMP FROM (
+				SELECT posts.user_id AS author_id, posts.community_id, votes.vote_type AS post_karma, 0 AS comment_karma
+				FROM votes JOIN posts ON posts.id = votes.post_id
+				WHERE votes.post_id <> 0 AND votes.user_id <> posts.user_id AND votes.flagged_at IS NULL
+				UNION ALL
+				SELECT comments.author_id, posts.community_id, 0, votes.vote_type
+				FROM votes JOIN comments ON comments.id = votes.comment_id JOIN posts ON posts.id = comments.post_id
+				WHERE votes.comment_id <> 0 AND votes.user_id <> comments.author_id AND votes.flagged_at IS NULL
+			) AS earned
+			GROUP BY author_id, community_id`).Error
+	})
+}
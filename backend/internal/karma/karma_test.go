@@ -0,0 +1,70 @@
+package karma
+
+import (
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		prev, requested, want int
+	}{
+		{0, 1, 1},
+		{0, -1, -1},
+		{1, 1, 0},
+		{-1, -1, 0},
+		{1, -1, -1},
+		{-1, 1, 1},
+	}
+	for _, tc := range cases {
+		if got := Next(tc.prev, tc.requested); got != tc.want {
+			t.Errorf("Next(%d, %d) = %d, want %d", tc.prev, tc.requested, got, tc.want)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	target := Target{Kind: Post, AuthorID: 1, CommunityID: 7}
+
+	cases := []struct {
+		voterID, prev, next, want int
+	}{
+		{2, 0, 1, 1},
+		{2, 1, 0, -1},
+		{2, 1, -1, -2},
+		{2, -1, 1, 2},
+		{2, -1, 0, 1},
+		{1, 0, 1, 0},  // self-vote
+		{1, 1, -1, 0}, // self-vote switched
+	}
+	for _, tc := range cases {
+		if got := Delta(target, tc.voterID, tc.prev, tc.next); got != tc.want {
+			t.Errorf("Delta(voter %d, %d -> %d) = %d, want %d", tc.voterID, tc.prev, tc.next, got, tc.want)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	totals := Sum([]models.Karma{
+		{UserID: 1, CommunityID: 3, PostKarma: 10, CommentKarma: 4},
+		{UserID: 1, CommunityID: 0, PostKarma: 2, CommentKarma: -1},
+		{UserID: 1, CommunityID: 5, PostKarma: -3, CommentKarma: 6},
+	})
+
+	if totals.PostKarma != 9 || totals.CommentKarma != 9 || totals.Total != 18 {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+	if len(totals.Communities) != 2 {
+		t.Fatalf("expected 2 community breakdowns, got %d", len(totals.Communities))
+	}
+	for _, c := range totals.Communities {
+		if c.CommunityID == 0 {
+			t.Error("karma outside communities should not be broken down")
+		}
+	}
+
+	if empty := Sum(nil); empty.Communities == nil || empty.Total != 0 {
+		t.Errorf("empty totals should be zero with an empty breakdown, got %+v", empty)
+	}
+}
@@ -0,0 +1,124 @@
+// Package markdown renders Reddit-flavored Markdown to sanitized HTML and
+// plain-text excerpts.
+//
+// On top of GitHub-flavored Markdown (tables, strikethrough, bare URLs) it
+// supports >!spoilers!<, ^superscript and ^(longer superscript), and
+// u/username and r/community autolinks.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Version identifies the renderer output. Bump it whenever rendering
+// changes so cached HTML is regenerated.
+const Version = 1
+
+// ExcerptLength is the maximum length of an excerpt in runes
+const ExcerptLength = 200
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, redditExtension{}),
+	)
+	policy = newPolicy()
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^md-spoiler$`)).OnElements("span")
+	p.AllowElements("sup")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// Render converts Markdown source into sanitized HTML and a plain-text
+// excerpt. Spoiler contents are hidden from the excerpt.
+func Render(source string) (html string, excerpt string, err error) {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return "", "", err
+	}
+
+	return policy.Sanitize(buf.String()), Excerpt(doc, src), nil
+}
+
+// Excerpt extracts plain text from a parsed document, truncated on a word
+// boundary to ExcerptLength runes
+func Excerpt(doc ast.Node, source []byte) string {
+	var sb strings.Builder
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				sb.WriteByte(' ')
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *Spoiler:
+			sb.WriteString("[spoiler]")
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			sb.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	plain := strings.Join(strings.Fields(sb.String()), " ")
+	if utf8.RuneCountInString(plain) <= ExcerptLength {
+		return plain
+	}
+
+	runes := []rune(plain)[:ExcerptLength]
+	cut := len(runes)
+	for i := len(runes) - 1; i > ExcerptLength/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsPunct) + "…"
+}
+
+// redditExtension registers the Reddit-specific syntax
+type redditExtension struct{}
+
+func (redditExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithInlineParsers(
+			util.Prioritized(spoilerParser{}, 150),
+			util.Prioritized(superscriptParser{}, 150),
+			util.Prioritized(mentionParser{}, 150),
+		),
+		parser.WithASTTransformers(
+			util.Prioritized(spoilerTransformer{}, 100),
+		),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(
+		util.Prioritized(redditRenderer{}, 500),
+	))
+}
@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, src string) (string, string) {
+	t.Helper()
+	html, excerpt, err := Render(src)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	return html, excerpt
+}
+
+func TestRenderSpoilers(t *testing.T) {
+	html, excerpt := render(t, "the killer is >!the *butler*!< obviously")
+	if !strings.Contains(html, `<span class="md-spoiler">the <em>butler</em></span>`) {
+		t.Fatalf("expected inline spoiler, got %s", html)
+	}
+	if strings.Contains(excerpt, "butler") || !strings.Contains(excerpt, "[spoiler]") {
+		t.Fatalf("expected spoiler hidden from excerpt, got %q", excerpt)
+	}
+
+	html, _ = render(t, ">!whole line spoiler!<")
+	if strings.Contains(html, "blockquote") || !strings.Contains(html, `<span class="md-spoiler">whole line spoiler</span>`) {
+		t.Fatalf("expected line-start spoiler, got %s", html)
+	}
+
+	html, _ = render(t, "> just a quote")
+	if !strings.Contains(html, "<blockquote>") {
+		t.Fatalf("expected regular blockquote, got %s", html)
+	}
+
+	html, _ = render(t, "unmatched !< marker")
+	if !strings.Contains(html, "unmatched !&lt; marker") {
+		t.Fatalf("expected unmatched marker as text, got %s", html)
+	}
+}
+
+func TestRenderSuperscript(t *testing.T) {
+	html, _ := render(t, "E=mc^2 and ^(two words)")
+	if !strings.Contains(html, "mc<sup>2</sup>") || !strings.Contains(html, "<sup>two words</sup>") {
+		t.Fatalf("expected superscripts, got %s", html)
+	}
+}
+
+func TestRenderTables(t *testing.T) {
+	html, _ := render(t, "| a | b |\n|---|---|\n| 1 | 2 |")
+	if !strings.Contains(html, "<table>") || !strings.Contains(html, "<td>2</td>") {
+		t.Fatalf("expected table, got %s", html)
+	}
+}
+
+func TestRenderAutolinks(t *testing.T) {
+	html, _ := render(t, "thanks u/alice, see /r/golang and r/go_lang. not a/u/bob")
+	for _, want := range []string{`href="/u/alice"`, `href="/r/golang"`, `href="/r/go_lang"`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %s in %s", want, html)
+		}
+	}
+	if strings.Contains(html, `href="/u/bob"`) {
+		t.Fatalf("did not expect a link mid-word, got %s", html)
+	}
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	html, _ := render(t, "<script>alert(1)</script>\n\n[click](javascript:alert(1)) <img src=x onerror=alert(1)>")
+	for _, bad := range []string{"<script", "javascript:", "onerror"} {
+		if strings.Contains(html, bad) {
+			t.Fatalf("expected %q to be sanitized, got %s", bad, html)
+		}
+	}
+}
+
+func TestExcerptTruncates(t *testing.T) {
+	_, excerpt := render(t, "# Title\n\n"+strings.Repeat("word ", 100))
+	if !strings.HasPrefix(excerpt, "Title word") || !strings.HasSuffix(excerpt, "…") {
+		t.Fatalf("unexpected excerpt: %q", excerpt)
+	}
+	if n := len([]rune(excerpt)); n > ExcerptLength+1 {
+		t.Fatalf("excerpt too long: %d runes", n)
+	}
+}
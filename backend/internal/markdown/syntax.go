@@ -0,0 +1,289 @@
+package markdown
+
+import (
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	KindSpoiler     = ast.NewNodeKind("Spoiler")
+	KindSuperscript = ast.NewNodeKind("Superscript")
+	kindMarker      = ast.NewNodeKind("SpoilerMarker")
+)
+
+// Spoiler hides its children until revealed
+type Spoiler struct {
+	ast.BaseInline
+}
+
+func (n *Spoiler) Kind() ast.NodeKind { return KindSpoiler }
+
+func (n *Spoiler) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// Superscript raises its children
+type Superscript struct {
+	ast.BaseInline
+}
+
+func (n *Superscript) Kind() ast.NodeKind { return KindSuperscript }
+
+func (n *Superscript) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+// spoilerMarker is a ">!" or "!<" token. The transformer pairs markers into
+// Spoiler nodes and turns unmatched ones back into text.
+type spoilerMarker struct {
+	ast.BaseInline
+	open    bool
+	segment text.Segment
+}
+
+func (n *spoilerMarker) Kind() ast.NodeKind { return kindMarker }
+
+func (n *spoilerMarker) Dump(source []byte, level int) { ast.DumpHelper(n, source, level, nil, nil) }
+
+type spoilerParser struct{}
+
+func (spoilerParser) Trigger() []byte { return []byte{'>', '!'} }
+
+func (spoilerParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if len(line) < 2 {
+		return nil
+	}
+
+	var open bool
+	switch {
+	case line[0] == '>' && line[1] == '!':
+		open = true
+	case line[0] == '!' && line[1] == '<':
+		open = false
+	default:
+		return nil
+	}
+
+	block.Advance(2)
+	return &spoilerMarker{open: open, segment: segment.WithStop(segment.Start + 2)}
+}
+
+type superscriptParser struct{}
+
+func (superscriptParser) Trigger() []byte { return []byte{'^'} }
+
+// Parse handles ^word and ^(several words)
+func (superscriptParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if len(line) < 2 || unicode.IsSpace(rune(line[1])) {
+		return nil
+	}
+
+	node := &Superscript{}
+	if line[1] == '(' {
+		end := -1
+		for i := 2; i < len(line); i++ {
+			if line[i] == ')' {
+				end = i
+				break
+			}
+		}
+		if end <= 2 {
+			return nil
+		}
+		node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+2, segment.Start+end)))
+		block.Advance(end + 1)
+		return node
+	}
+
+	end := 1
+	for end < len(line) && !unicode.IsSpace(rune(line[end])) && line[end] != '^' {
+		end++
+	}
+	node.AppendChild(node, ast.NewTextSegment(text.NewSegment(segment.Start+1, segment.Start+end)))
+	block.Advance(end)
+	return node
+}
+
+// mentionParser links u/username and r/community, with or without a
+// leading slash. Letters only trigger inline parsers at a line head or after
+// whitespace, which goldmark reports as ' '.
+type mentionParser struct{}
+
+func (mentionParser) Trigger() []byte { return []byte{' ', '(', '/'} }
+
+func isNameChar(b byte) bool {
+	return b == '_' || b == '-' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func (mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if pc.IsInLinkLabel() {
+		return nil
+	}
+
+	line, segment := block.PeekLine()
+	if len(line) == 0 {
+		return nil
+	}
+
+	lead := 0
+	switch line[0] {
+	case ' ', '(':
+		lead = 1
+	case '/':
+		before := block.PrecendingCharacter()
+		if before == '/' || before == '_' || unicode.IsLetter(before) || unicode.IsDigit(before) {
+			return nil
+		}
+	}
+
+	i := lead
+	if len(line) > i && line[i] == '/' {
+		i++
+	}
+	if len(line) < i+3 || (line[i] != 'u' && line[i] != 'r') || line[i+1] != '/' {
+		return nil
+	}
+
+	prefix := line[i]
+	end := i + 2
+	for end < len(line) && isNameChar(line[end]) {
+		end++
+	}
+	if end == i+2 {
+		return nil
+	}
+
+	// Keep the whitespace or parenthesis that triggered the parser as text
+	if lead > 0 {
+		ast.MergeOrAppendTextSegment(parent, segment.WithStop(segment.Start+lead))
+	}
+
+	link := ast.NewLink()
+	link.Destination = []byte("/" + string(prefix) + "/" + string(line[i+2:end]))
+	link.AppendChild(link, ast.NewTextSegment(text.NewSegment(segment.Start+lead, segment.Start+end)))
+	block.Advance(end)
+	return link
+}
+
+// spoilerTransformer pairs spoiler markers into Spoiler nodes
+type spoilerTransformer struct{}
+
+func (spoilerTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+
+	// A spoiler at the start of a line parses as a blockquote starting with
+	// "!"; turn those back into plain paragraphs opened by a marker
+	var quotes []*ast.Blockquote
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if q, ok := n.(*ast.Blockquote); ok && entering && isQuotedSpoiler(q, source) {
+			quotes = append(quotes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, q := range quotes {
+		para := q.FirstChild()
+		first := para.FirstChild().(*ast.Text)
+		open := &spoilerMarker{open: true, segment: first.Segment.WithStop(first.Segment.Start + 1)}
+		first.Segment = first.Segment.WithStart(first.Segment.Start + 1)
+		para.InsertBefore(para, first, open)
+		q.RemoveChild(q, para)
+		q.Parent().ReplaceChild(q.Parent(), q, para)
+	}
+
+	var parents []ast.Node
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if _, ok := n.(*spoilerMarker); ok && entering {
+			if p := n.Parent(); len(parents) == 0 || parents[len(parents)-1] != p {
+				parents = append(parents, p)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, p := range parents {
+		pairMarkers(p)
+	}
+}
+
+func isQuotedSpoiler(q *ast.Blockquote, source []byte) bool {
+	para, ok := q.FirstChild().(*ast.Paragraph)
+	if !ok || q.ChildCount() != 1 {
+		return false
+	}
+	first, ok := para.FirstChild().(*ast.Text)
+	if !ok {
+		return false
+	}
+	if v := first.Segment.Value(source); len(v) == 0 || v[0] != '!' {
+		return false
+	}
+	for c := para.FirstChild(); c != nil; c = c.NextSibling() {
+		if m, ok := c.(*spoilerMarker); ok && !m.open {
+			return true
+		}
+	}
+	return false
+}
+
+// pairMarkers wraps the nodes between each opening and closing marker of a
+// parent in a Spoiler, and turns leftover markers into text
+func pairMarkers(parent ast.Node) {
+	var open *spoilerMarker
+	for c := parent.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		m, ok := c.(*spoilerMarker)
+		if !ok {
+			c = next
+			continue
+		}
+
+		if m.open {
+			if open != nil {
+				parent.ReplaceChild(parent, open, ast.NewTextSegment(open.segment))
+			}
+			open = m
+		} else if open != nil {
+			spoiler := &Spoiler{}
+			for n := open.NextSibling(); n != m; {
+				following := n.NextSibling()
+				spoiler.AppendChild(spoiler, n)
+				n = following
+			}
+			parent.ReplaceChild(parent, open, spoiler)
+			parent.RemoveChild(parent, m)
+			open = nil
+		} else {
+			parent.ReplaceChild(parent, m, ast.NewTextSegment(m.segment))
+		}
+		c = next
+	}
+	if open != nil {
+		parent.ReplaceChild(parent, open, ast.NewTextSegment(open.segment))
+	}
+}
+
+type redditRenderer struct{}
+
+func (redditRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindSpoiler, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			w.WriteString(`<span class="md-spoiler">`)
+		} else {
+			w.WriteString("</span>")
+		}
+		return ast.WalkContinue, nil
+	})
+	reg.Register(KindSuperscript, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering {
+			w.WriteString("<sup>")
+		} else {
+			w.WriteString("</sup>")
+		}
+		return ast.WalkContinue, nil
+	})
+	reg.Register(kindMarker, func(w util.BufWriter, source []byte, n ast.Node, entering bool) (ast.WalkStatus, error) {
+		return ast.WalkContinue, nil
+	})
+}
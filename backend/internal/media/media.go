@@ -0,0 +1,210 @@
+// Package media validates and normalizes uploaded images.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// ThumbnailSize is the maximum width and height of generated thumbnails
+	ThumbnailSize = 320
+	// maxPixels guards against decompression bombs; for a GIF it covers
+	// all of its frames
+	maxPixels = 40_000_000
+	// maxFrames caps the frames of an animated GIF
+	maxFrames = 500
+)
+
+var ErrUnsupportedType = errors.New("unsupported media type")
+
+// Processed is an upload after sanitization
+type Processed struct {
+	ContentType string
+	Ext         string
+	Data        []byte
+	Thumbnail   []byte // always JPEG
+	Width       int
+	Height      int
+}
+
+// Process sniffs the real type of an upload, re-encodes it to drop EXIF and
+// other metadata, and generates a JPEG thumbnail. The client-supplied
+// content type is never trusted.
+func Process(data []byte) (*Processed, error) {
+	contentType := http.DetectContentType(data)
+
+	var ext string
+	switch contentType {
+	case "image/jpeg":
+		ext = "jpg"
+	case "image/png":
+		ext = "png"
+	case "image/gif":
+		ext = "gif"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("image dimensions too large: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	var out bytes.Buffer
+	var first image.Image
+
+	// Decoding and re-encoding keeps only pixel data, stripping metadata
+	switch contentType {
+	case "image/gif":
+		frames, pixels, err := gifFrames(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image: %w", err)
+		}
+		if frames > maxFrames {
+			return nil, fmt.Errorf("too many frames: %d", frames)
+		}
+		if pixels > maxPixels {
+			return nil, fmt.Errorf("animation too large: %d pixels across %d frames", pixels, frames)
+		}
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image: %w", err)
+		}
+		first = g.Image[0]
+		if err := gif.EncodeAll(&out, g); err != nil {
+			return nil, err
+		}
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid image: %w", err)
+		}
+		if contentType == "image/png" {
+			err = png.Encode(&out, img)
+		} else {
+			// Re-encoding drops the EXIF orientation, so apply it to the
+			// pixels first
+			img = orient(img, exifOrientation(data))
+			cfg.Width, cfg.Height = img.Bounds().Dx(), img.Bounds().Dy()
+			err = jpeg.Encode(&out, img, &jpeg.Options{Quality: 90})
+		}
+		first = img
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	thumb, err := thumbnail(first, ThumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Processed{
+		ContentType: contentType,
+		Ext:         ext,
+		Data:        out.Bytes(),
+		Thumbnail:   thumb,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+// gifFrames walks the blocks of a GIF without decoding it and returns the
+// number of frames and their total pixels, which is what decoding every
+// frame allocates
+func gifFrames(data []byte) (frames, pixels int, err error) {
+	errTruncated := errors.New("truncated GIF")
+	if len(data) < 13 {
+		return 0, 0, errTruncated
+	}
+	i := 13
+	if packed := data[10]; packed&0x80 != 0 {
+		i += 3 << ((packed & 7) + 1)
+	}
+
+	// skipSubBlocks moves i past a chain of data sub-blocks
+	skipSubBlocks := func() error {
+		for {
+			if i >= len(data) {
+				return errTruncated
+			}
+			size := int(data[i])
+			i += 1 + size
+			if size == 0 {
+				return nil
+			}
+		}
+	}
+
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // extension
+			i += 2
+			if err := skipSubBlocks(); err != nil {
+				return frames, pixels, err
+			}
+		case 0x2C: // image descriptor
+			if i+10 > len(data) {
+				return frames, pixels, errTruncated
+			}
+			w := int(data[i+5]) | int(data[i+6])<<8
+			h := int(data[i+7]) | int(data[i+8])<<8
+			packed := data[i+9]
+			i += 10
+			if packed&0x80 != 0 {
+				i += 3 << ((packed & 7) + 1)
+			}
+			// LZW minimum code size, then the image data
+			i++
+			if err := skipSubBlocks(); err != nil {
+				return frames, pixels, err
+			}
+			frames++
+			pixels += w * h
+		case 0x3B: // trailer
+			return frames, pixels, nil
+		default:
+			return frames, pixels, fmt.Errorf("unknown GIF block 0x%02x", data[i])
+		}
+	}
+	return frames, pixels, nil
+}
+
+// thumbnail scales img to fit within size x size and encodes it as JPEG,
+// flattening transparency onto white
+func thumbnail(img image.Image, size int) ([]byte, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			h = max(1, h*size/w)
+			w = size
+		} else {
+			w = max(1, w*size/h)
+			h = size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
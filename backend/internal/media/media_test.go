@@ -0,0 +1,199 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPNG(t *testing.T) {
+	p, err := Process(encodePNG(t, 800, 400))
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+
+	if p.ContentType != "image/png" || p.Ext != "png" {
+		t.Fatalf("expected png, got %s (%s)", p.ContentType, p.Ext)
+	}
+	if p.Width != 800 || p.Height != 400 {
+		t.Fatalf("expected 800x400, got %dx%d", p.Width, p.Height)
+	}
+
+	thumb, err := jpeg.DecodeConfig(bytes.NewReader(p.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if thumb.Width != ThumbnailSize || thumb.Height != ThumbnailSize/2 {
+		t.Fatalf("expected %dx%d thumbnail, got %dx%d", ThumbnailSize, ThumbnailSize/2, thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcessStripsJPEGMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Splice a fake EXIF APP1 segment in right after the SOI marker
+	exif := append([]byte{0xFF, 0xE1, 0x00, 0x10}, []byte("Exif\x00\x00GPSDATA!")...)
+	data := append([]byte{0xFF, 0xD8}, append(exif, buf.Bytes()[2:]...)...)
+
+	p, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if bytes.Contains(p.Data, []byte("Exif")) || bytes.Contains(p.Data, []byte("GPSDATA")) {
+		t.Fatal("expected EXIF data to be stripped")
+	}
+}
+
+func TestProcessRejectsNonImages(t *testing.T) {
+	_, err := Process([]byte("<html><script>alert(1)</script></html>"))
+	if !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+}
+
+// exifSegment is an APP1 segment whose little-endian TIFF header holds just
+// an orientation
+func exifSegment(orientation byte) []byte {
+	tiff := []byte{
+		'I', 'I', 42, 0, 8, 0, 0, 0, // header, IFD at offset 8
+		1, 0, // one entry
+		0x12, 0x01, 3, 0, 1, 0, 0, 0, orientation, 0, 0, 0, // orientation, SHORT
+		0, 0, 0, 0, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	return append([]byte{0xFF, 0xE1, 0, byte(len(payload) + 2)}, payload...)
+}
+
+func TestProcessAppliesJPEGOrientation(t *testing.T) {
+	// Left half red, right half blue
+	img := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 64; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 32 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	// A camera held upright stores the image on its side, orientation 6
+	data := append([]byte{0xFF, 0xD8}, append(exifSegment(6), buf.Bytes()[2:]...)...)
+
+	p, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if p.Width != 32 || p.Height != 64 {
+		t.Fatalf("expected 32x64, got %dx%d", p.Width, p.Height)
+	}
+	out, err := jpeg.Decode(bytes.NewReader(p.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := out.Bounds(); b.Dx() != 32 || b.Dy() != 64 {
+		t.Fatalf("stored image is %v", b)
+	}
+	// Rotated clockwise, the left half is now on top
+	if r, _, b, _ := out.At(16, 8).RGBA(); r < b {
+		t.Errorf("expected red on top, got %v", out.At(16, 8))
+	}
+	if r, _, b, _ := out.At(16, 56).RGBA(); b < r {
+		t.Errorf("expected blue at the bottom, got %v", out.At(16, 56))
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3x2 with distinct pixels, read row by row
+	src := image.NewGray(image.Rect(0, 0, 3, 2))
+	copy(src.Pix, []byte{1, 2, 3, 4, 5, 6})
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		want        []byte
+	}{
+		{1, 3, 2, []byte{1, 2, 3, 4, 5, 6}},
+		{2, 3, 2, []byte{3, 2, 1, 6, 5, 4}},
+		{3, 3, 2, []byte{6, 5, 4, 3, 2, 1}},
+		{4, 3, 2, []byte{4, 5, 6, 1, 2, 3}},
+		{5, 2, 3, []byte{1, 4, 2, 5, 3, 6}},
+		{6, 2, 3, []byte{4, 1, 5, 2, 6, 3}},
+		{7, 2, 3, []byte{6, 3, 5, 2, 4, 1}},
+		{8, 2, 3, []byte{3, 6, 2, 5, 1, 4}},
+	}
+	for _, tt := range tests {
+		out := orient(src, tt.orientation)
+		b := out.Bounds()
+		if b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		var got []byte
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				got = append(got, color.GrayModel.Convert(out.At(x, y)).(color.Gray).Y)
+			}
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("orientation %d: got %v, want %v", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func encodeGIF(t *testing.T, w, h, frames int) []byte {
+	t.Helper()
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9))
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessGIFLimits(t *testing.T) {
+	p, err := Process(encodeGIF(t, 40, 20, 3))
+	if err != nil {
+		t.Fatalf("Process returned error: %v", err)
+	}
+	if g, err := gif.DecodeAll(bytes.NewReader(p.Data)); err != nil || len(g.Image) != 3 {
+		t.Fatalf("expected 3 frames to be kept, got %v", err)
+	}
+
+	// Each frame is within the limits, the animation is not
+	if _, err := Process(encodeGIF(t, 1, 1, maxFrames+1)); err == nil {
+		t.Error("expected too many frames to be rejected")
+	}
+	if _, err := Process(encodeGIF(t, 2000, 2000, maxPixels/(2000*2000)+1)); err == nil {
+		t.Error("expected too many pixels across frames to be rejected")
+	}
+}
@@ -0,0 +1,59 @@
+// Package mentions extracts u/username, @username and r/community
+// references from post and comment bodies.
+package mentions
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	userPattern      = regexp.MustCompile(`(?:^|[^\w/@])(?:/?u/|@)([A-Za-z0-9_-]+)`)
+	communityPattern = regexp.MustCompile(`(?:^|[^\w/])/?r/([A-Za-z0-9_-]+)`)
+)
+
+// Extract returns the distinct usernames and community names referenced in
+// text, in order of first appearance. Matches inside code are ignored and
+// names are compared case-insensitively.
+func Extract(text string) (users []string, communities []string) {
+	text = codeBlockPattern.ReplaceAllString(text, " ")
+	return collect(userPattern, text), collect(communityPattern, text)
+}
+
+func collect(pattern *regexp.Regexp, text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+		key := strings.ToLower(m[1])
+		if !seen[key] {
+			seen[key] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Diff compares the names referenced before and after an edit
+func Diff(before, after []string) (added, removed []string) {
+	in := func(names []string) map[string]bool {
+		set := make(map[string]bool, len(names))
+		for _, n := range names {
+			set[strings.ToLower(n)] = true
+		}
+		return set
+	}
+	beforeSet, afterSet := in(before), in(after)
+
+	for _, n := range after {
+		if !beforeSet[strings.ToLower(n)] {
+			added = append(added, n)
+		}
+	}
+	for _, n := range before {
+		if !afterSet[strings.ToLower(n)] {
+			removed = append(removed, n)
+		}
+	}
+	return added, removed
+}
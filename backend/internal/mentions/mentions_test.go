@@ -0,0 +1,36 @@
+package mentions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	users, communities := Extract("hey u/alice and @Bob, also /u/carol. see r/golang and /r/Go_Lang\n" +
+		"ping @alice again, mail me at dave@example.com, not a/u/eve or `u/frank`\n" +
+		"```\nr/inside_code\n```")
+
+	if want := []string{"alice", "Bob", "carol"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("users = %v, want %v", users, want)
+	}
+	if want := []string{"golang", "Go_Lang"}; !reflect.DeepEqual(communities, want) {
+		t.Errorf("communities = %v, want %v", communities, want)
+	}
+}
+
+func TestExtractNone(t *testing.T) {
+	users, communities := Extract("nothing to see here")
+	if users != nil || communities != nil {
+		t.Fatalf("expected no mentions, got %v %v", users, communities)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	added, removed := Diff([]string{"alice", "bob"}, []string{"Bob", "carol"})
+	if want := []string{"carol"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %v, want %v", added, want)
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+}
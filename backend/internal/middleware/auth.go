@@ -1,12 +1,13 @@
 package middleware
 
 import (
-	"net/http"
 	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
 )
 
 var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
@@ -17,16 +18,14 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			apierr.Unauthorized(c, "Authorization header required")
 			return
 		}
 
 		// Extract token from "Bearer <token>" format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			apierr.Unauthorized(c, "Invalid authorization header format")
 			return
 		}
 
@@ -42,23 +41,16 @@ func AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+			apierr.Unauthorized(c, "Invalid or expired token")
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Set user information in context for use in handlers
-			c.Set("user_id", uint(claims["user_id"].(float64)))
-			c.Set("username", claims["username"].(string))
-			c.Set("email", claims["email"].(string))
-
+		// Extract claims; service tokens don't identify a user
+		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid && setUserClaims(c, claims) {
 			// Continue to next handler
 			c.Next()
 		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+			apierr.Unauthorized(c, "Invalid token claims")
 			return
 		}
 	}
@@ -91,12 +83,29 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 
 		if err == nil {
 			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				c.Set("user_id", uint(claims["user_id"].(float64)))
-				c.Set("username", claims["username"].(string))
-				c.Set("email", claims["email"].(string))
+				setUserClaims(c, claims)
 			}
 		}
 
 		c.Next()
 	}
 }
+
+// setUserClaims puts the user information of a user token in the context. It
+// returns false for service tokens and tokens without a user.
+func setUserClaims(c *gin.Context, claims jwt.MapClaims) bool {
+	if _, isService := claims[ServiceClaim]; isService {
+		return false
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok || userID <= 0 {
+		return false
+	}
+	username, _ := claims["username"].(string)
+	email, _ := claims["email"].(string)
+
+	c.Set("user_id", uint(userID))
+	c.Set("username", username)
+	c.Set("email", email)
+	return true
+}
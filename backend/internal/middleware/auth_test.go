@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signed(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthMiddlewareRejectsServiceTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/required", AuthMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "%v", c.GetUint("user_id"))
+	})
+	r.GET("/optional", OptionalAuthMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "%v", c.GetUint("user_id"))
+	})
+
+	serviceToken, err := NewServiceToken("recommendations", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	userToken := signed(t, jwt.MapClaims{"user_id": 7, "username": "alice", "email": "alice@example.com", "exp": exp})
+
+	tests := []struct {
+		name     string
+		token    string
+		required int
+		user     string
+	}{
+		{"user token", userToken, http.StatusOK, "7"},
+		{"service token", serviceToken, http.StatusUnauthorized, "0"},
+		{"service token with a user", signed(t, jwt.MapClaims{ServiceClaim: "x", "user_id": 7, "exp": exp}), http.StatusUnauthorized, "0"},
+		{"no user", signed(t, jwt.MapClaims{"exp": exp}), http.StatusUnauthorized, "0"},
+		{"wrong claim types", signed(t, jwt.MapClaims{"user_id": "7", "username": 1, "exp": exp}), http.StatusUnauthorized, "0"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/required", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		r.ServeHTTP(w, req)
+		if w.Code != tt.required {
+			t.Errorf("%s: required auth = %d, want %d", tt.name, w.Code, tt.required)
+		}
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/optional", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.token)
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != tt.user {
+			t.Errorf("%s: optional auth = %d %q, want user %s", tt.name, w.Code, w.Body.String(), tt.user)
+		}
+	}
+}
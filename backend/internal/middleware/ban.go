@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/policy"
+)
+
+// RequireNotBanned rejects users with an active site-wide ban. It must run
+// after AuthMiddleware. Community bans are checked by the handlers once the
+// target community is known.
+func RequireNotBanned(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			apierr.Unauthorized(c, "User not authenticated")
+			return
+		}
+
+		err := policy.CheckParticipation(db, int(userID.(uint)), 0)
+		var banErr *policy.BanError
+		if errors.As(err, &banErr) {
+			apierr.Write(c, apierr.New(http.StatusForbidden, apierr.CodeBanned, banErr.Error()).With("ban", banErr.Ban))
+			return
+		}
+		if err != nil {
+			apierr.Internal(c, "Failed to check ban status")
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.4.2", "1.4.2", 0},
+		{"1.4", "1.4.0", 0},
+		{"1.10.0", "1.9.9", 1},
+		{"1.3.9", "1.4.0", -1},
+		{"v2.0.0-beta.1", "2.0.0", 0},
+		{"2", "1.99", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMinClientVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MinClientVersion("1.2.0"))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		version string
+		want    int
+	}{
+		{"", http.StatusOK},
+		{"1.2.0", http.StatusOK},
+		{"1.3.1", http.StatusOK},
+		{"1.1.9", http.StatusUpgradeRequired},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.version != "" {
+			req.Header.Set(ClientVersionHeader, tt.version)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("version %q: status %d, want %d", tt.version, w.Code, tt.want)
+		}
+		if w.Code == http.StatusUpgradeRequired && !strings.Contains(w.Body.String(), `"code":"upgrade_required"`) {
+			t.Errorf("body = %s", w.Body)
+		}
+	}
+}
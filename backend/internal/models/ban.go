@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Ban restricts a user site-wide (CommunityID nil) or within one community.
+// A nil ExpiresAt means the ban is permanent. Shadow bans do not block the
+// user; instead their content is only visible to themselves.
+type Ban struct {
+	ID          int        `gorm:"primaryKey" json:"id"`
+	UserID      int        `gorm:"index" json:"user_id"`
+	CommunityID *int       `gorm:"index" json:"community_id,omitempty"`
+	Reason      string     `json:"reason"`
+	Shadow      bool       `gorm:"default:false" json:"shadow"`
+	BannedByID  int        `json:"banned_by_id"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Active reports whether the ban is still in effect at the given time
+func (b Ban) Active(now time.Time) bool {
+	return b.ExpiresAt == nil || b.ExpiresAt.After(now)
+}
+
+type CreateBanRequest struct {
+	CommunityID *int       `json:"community_id,omitempty"`
+	Reason      string     `json:"reason"`
+	Shadow      bool       `json:"shadow"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Block hides a user's content from the blocker and stops the blocked user
+// from following or mentioning them
+type Block struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	BlockerID int       `gorm:"uniqueIndex:idx_block_pair;not null" json:"blocker_id"`
+	BlockedID int       `gorm:"uniqueIndex:idx_block_pair;not null" json:"blocked_id"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"blocked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HiddenPost removes a post from a user's feeds
+type HiddenPost struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    int       `gorm:"uniqueIndex:idx_hidden_post;not null" json:"user_id"`
+	PostID    int       `gorm:"uniqueIndex:idx_hidden_post;not null" json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
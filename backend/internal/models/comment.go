@@ -3,17 +3,31 @@ package models
 import "time"
 
 type Comment struct {
-	ID              int       `gorm:"primaryKey" json:"id"`
-	Body            string    `gorm:"not null" json:"body"`
-	AuthorID        int       `json:"author_id"`
-	Author          string    `json:"author"`
-	User            User      `gorm:"foreignKey:AuthorID" json:"user"`
-	PostID          int       `json:"post_id"`
-	ParentCommentID *int      `json:"parent_comment_id,omitempty"`
-	Upvotes         int       `json:"upvotes"`
-	Downvotes       int       `json:"downvotes"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              int        `gorm:"primaryKey" json:"id"`
+	Body            string     `gorm:"not null" json:"body"`
+	AuthorID        int        `json:"author_id"`
+	Author          string     `json:"author"`
+	User            User       `gorm:"foreignKey:AuthorID" json:"user"`
+	PostID          int        `json:"post_id"`
+	ParentCommentID *int       `json:"parent_comment_id,omitempty"`
+	Upvotes         int        `json:"upvotes"`
+	Downvotes       int        `json:"downvotes"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	EditedAt        *time.Time `json:"edited_at,omitempty"`
+
+	// Moderation state set by automod or moderators
+	ModStatus string `gorm:"index;default:''" json:"mod_status,omitempty"`
+	ModReason string `json:"mod_reason,omitempty"`
+
+	// Spam scoring when the content was created
+	ContentHash string  `gorm:"size:64;index" json:"-"`
+	SpamScore   float64 `json:"-"`
+
+	// Rendered Markdown, cached until the body or the renderer changes
+	BodyHTML      string `json:"body_html"`
+	Excerpt       string `json:"excerpt"`
+	RenderVersion int    `json:"-"`
 }
 
 type CreateCommentRequest struct {
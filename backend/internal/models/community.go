@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Community is a named group that posts belong to
+type Community struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null;size:50" json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   int       `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CommunityMember records that a user joined a community
+type CommunityMember struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	CommunityID int       `gorm:"uniqueIndex:idx_community_member;not null" json:"community_id"`
+	UserID      int       `gorm:"uniqueIndex:idx_community_member;not null" json:"user_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CommunityModerator appoints a user to moderate a community alongside its
+// creator
+type CommunityModerator struct {
+	ID          int       `gorm:"primaryKey" json:"-"`
+	CommunityID int       `gorm:"uniqueIndex:idx_community_moderator;not null" json:"community_id"`
+	UserID      int       `gorm:"uniqueIndex:idx_community_moderator;not null" json:"user_id"`
+	AddedByID   int       `json:"added_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Moderation states of posts and comments. Removed and pending content is
+// only visible to its author.
+const (
+	ModStatusNone     = ""
+	ModStatusFlagged  = "flagged"
+	ModStatusPending  = "pending"
+	ModStatusRemoved  = "removed"
+	ModStatusApproved = "approved"
+)
+
+// CommunityRules holds a community's posting requirements and automod rules
+type CommunityRules struct {
+	ID          int `gorm:"primaryKey" json:"-"`
+	CommunityID int `gorm:"uniqueIndex;not null" json:"community_id"`
+	// Requirements is automod.Requirements encoded as JSON
+	Requirements string `json:"-"`
+	// Automod is the rule set source as written by moderators, YAML or JSON
+	Automod   string    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CommunityStats is the periodically aggregated activity of a community.
+// Activity counts posts plus comments; each window is compared with the
+// window of the same length just before it.
+type CommunityStats struct {
+	ID              int       `gorm:"primaryKey" json:"-"`
+	CommunityID     int       `gorm:"uniqueIndex;not null" json:"community_id"`
+	Members         int       `json:"members"`
+	Posts24h        int       `json:"posts_24h"`
+	Comments24h     int       `json:"comments_24h"`
+	Activity24h     int       `json:"activity_24h"`
+	ActivityPrev24h int       `json:"activity_prev_24h"`
+	Activity7d      int       `json:"activity_7d"`
+	ActivityPrev7d  int       `json:"activity_prev_7d"`
+	Growth24h       float64   `json:"growth_24h"`
+	Growth7d        float64   `json:"growth_7d"`
+	TrendScore      float64   `gorm:"index" json:"trend_score"`
+	ComputedAt      time.Time `json:"computed_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// FlairTemplate is a post flair a community offers its authors
+type FlairTemplate struct {
+	ID              int       `gorm:"primaryKey" json:"id"`
+	CommunityID     int       `gorm:"index;not null" json:"community_id"`
+	Text            string    `gorm:"not null;size:64" json:"text"`
+	TextColor       string    `json:"text_color"`
+	BackgroundColor string    `json:"background_color"`
+	ModOnly         bool      `json:"mod_only"` // only moderators may pick it
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// UserFlair is the flair shown next to a user's name within one community
+type UserFlair struct {
+	ID              int       `gorm:"primaryKey" json:"-"`
+	CommunityID     int       `gorm:"uniqueIndex:idx_user_flair;not null" json:"community_id"`
+	UserID          int       `gorm:"uniqueIndex:idx_user_flair;not null" json:"user_id"`
+	Text            string    `gorm:"not null;size:64" json:"text"`
+	TextColor       string    `json:"text_color"`
+	BackgroundColor string    `json:"background_color"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
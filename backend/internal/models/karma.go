@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Karma is the net score a user has earned from other users' votes in one
+// community. CommunityID 0 holds karma earned outside any community.
+type Karma struct {
+	ID           int       `gorm:"primaryKey" json:"-"`
+	UserID       int       `gorm:"uniqueIndex:idx_karma_user_community;not null" json:"user_id"`
+	CommunityID  int       `gorm:"uniqueIndex:idx_karma_user_community;not null;default:0" json:"community_id"`
+	PostKarma    int       `gorm:"not null;default:0" json:"post_karma"`
+	CommentKarma int       `gorm:"not null;default:0" json:"comment_karma"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
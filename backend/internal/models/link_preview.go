@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Link preview fetch states
+const (
+	PreviewPending = "pending"
+	PreviewOK      = "ok"
+	PreviewFailed  = "failed"
+)
+
+// LinkPreview caches the Open Graph metadata of a URL
+type LinkPreview struct {
+	ID          int       `gorm:"primaryKey" json:"-"`
+	URL         string    `gorm:"uniqueIndex;not null" json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Image       string    `json:"image"`
+	SiteName    string    `json:"site_name"`
+	Status      string    `gorm:"default:pending" json:"-"`
+	Error       string    `json:"-"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
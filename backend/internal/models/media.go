@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Media is an uploaded file owned by the backend. Posts and avatars
+// reference media by ID rather than trusting client-supplied URLs.
+type Media struct {
+	ID           int       `gorm:"primaryKey" json:"id"`
+	OwnerID      int       `gorm:"index" json:"owner_id"`
+	StorageKey   string    `gorm:"not null" json:"-"`
+	ThumbnailKey string    `json:"-"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Size         int       `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
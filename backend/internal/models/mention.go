@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Mention records a u/username, @username or r/community reference in a post
+// or comment. Exactly one of UserID and CommunityID is set.
+type Mention struct {
+	ID          int        `gorm:"primaryKey" json:"id"`
+	ContentType string     `gorm:"index:idx_mention_content;not null" json:"content_type"`
+	ContentID   int        `gorm:"index:idx_mention_content;not null" json:"content_id"`
+	PostID      int        `json:"post_id"`
+	AuthorID    int        `json:"author_id"`
+	Author      User       `gorm:"foreignKey:AuthorID" json:"author"`
+	UserID      *int       `gorm:"index" json:"user_id,omitempty"`
+	CommunityID *int       `gorm:"index" json:"community_id,omitempty"`
+	ReadAt      *time.Time `json:"read_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
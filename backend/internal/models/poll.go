@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Poll belongs to a poll post. Votes are tallied on each option.
+type Poll struct {
+	ID        int          `gorm:"primaryKey" json:"id"`
+	PostID    int          `gorm:"uniqueIndex" json:"post_id"`
+	ClosesAt  time.Time    `json:"closes_at"`
+	Options   []PollOption `gorm:"foreignKey:PollID" json:"options"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Closed reports whether the poll no longer accepts votes
+func (p Poll) Closed(now time.Time) bool {
+	return !now.Before(p.ClosesAt)
+}
+
+type PollOption struct {
+	ID       int    `gorm:"primaryKey" json:"id"`
+	PollID   int    `gorm:"index" json:"poll_id"`
+	Position int    `json:"position"`
+	Text     string `gorm:"not null" json:"text"`
+	Votes    int    `gorm:"default:0" json:"votes"`
+}
+
+// PollVote records a user's single vote on a poll
+type PollVote struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	PollID    int       `gorm:"uniqueIndex:idx_poll_vote_user" json:"poll_id"`
+	UserID    int       `gorm:"uniqueIndex:idx_poll_vote_user" json:"user_id"`
+	OptionID  int       `json:"option_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreatePollRequest struct {
+	Options  []string   `json:"options"`
+	ClosesAt *time.Time `json:"closes_at,omitempty"`
+}
@@ -2,27 +2,91 @@ package models
 
 import "time"
 
+// Post kinds
+const (
+	PostKindText    = "text"
+	PostKindLink    = "link"
+	PostKindGallery = "gallery"
+	PostKindVideo   = "video"
+	PostKindPoll    = "poll"
+	// PostKindCrosspost shares another post into a different community
+	PostKindCrosspost = "crosspost"
+)
+
 type Post struct {
-	ID          int       `gorm:"primaryKey" json:"id"`
-	Title       string    `gorm:"not null" json:"title"`
-	Body        string    `json:"body,omitempty"`
-	Content     string    `json:"content"`
-	Image       string    `json:"image"`
-	UserID      int       `json:"user_id"`
-	AuthorID    int       `json:"author_id"`
-	Author      string    `json:"author"`
-	CommunityID int       `json:"community_id"`
-	Community   string    `json:"community"`
-	Comments    int       `json:"comments"`
-	CreatedAt   time.Time `json:"created_at"`
-	User        User      `gorm:"foreignKey:UserID" json:"user"`
-	Upvotes     int       `gorm:"default:0" json:"upvotes"`
-	Downvotes   int       `gorm:"default:0" json:"downvotes"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int         `gorm:"primaryKey" json:"id"`
+	Title       string      `gorm:"not null" json:"title"`
+	Kind        string      `gorm:"default:text" json:"kind"`
+	URL         string      `json:"url,omitempty"`    // link and video posts
+	Domain      string      `json:"domain,omitempty"` // link posts
+	Body        string      `json:"body,omitempty"`
+	Content     string      `json:"content"`
+	Image       string      `json:"image"`
+	UserID      int         `json:"user_id"`
+	AuthorID    int         `json:"author_id"`
+	Author      string      `json:"author"`
+	CommunityID int         `gorm:"uniqueIndex:idx_crosspost_community,priority:2" json:"community_id"`
+	Community   string      `json:"community"`
+	Comments    int         `json:"comments"`
+	CreatedAt   time.Time   `json:"created_at"`
+	User        User        `gorm:"foreignKey:UserID" json:"user"`
+	Upvotes     int         `gorm:"default:0" json:"upvotes"`
+	Downvotes   int         `gorm:"default:0" json:"downvotes"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	EditedAt    *time.Time  `json:"edited_at,omitempty"`
+	PreviewURL  string      `json:"-"` // URL whose link preview is attached to the post
+	Media       []PostMedia `gorm:"foreignKey:PostID" json:"media,omitempty"`
+	Poll        *Poll       `gorm:"foreignKey:PostID" json:"poll,omitempty"`
+
+	// Rendered Markdown, cached until the body or the renderer changes
+	BodyHTML      string `json:"body_html"`
+	Excerpt       string `json:"excerpt"`
+	RenderVersion int    `json:"-"`
+
+	// Flair picked from the community's templates
+	FlairID *int           `gorm:"index" json:"flair_id,omitempty"`
+	Flair   *FlairTemplate `gorm:"foreignKey:FlairID" json:"flair,omitempty"`
+
+	// Original post this post was crossposted from. A post is crossposted to
+	// each community once.
+	CrosspostParentID *int `gorm:"index;uniqueIndex:idx_crosspost_community,priority:1" json:"crosspost_parent_id,omitempty"`
+
+	// Moderation state set by automod or moderators
+	ModStatus string `gorm:"index;default:''" json:"mod_status,omitempty"`
+	ModReason string `json:"mod_reason,omitempty"`
+
+	// Spam scoring when the content was created
+	ContentHash string  `gorm:"size:64;index" json:"-"`
+	SpamScore   float64 `json:"-"`
+
+	// Ranking value computed by feed queries, never stored
+	FeedRank float64 `gorm:"->;-:migration" json:"-"`
+}
+
+// PostMedia is one ordered item of a gallery post
+type PostMedia struct {
+	ID       int    `gorm:"primaryKey" json:"id"`
+	PostID   int    `gorm:"index" json:"post_id"`
+	Position int    `json:"position"`
+	MediaID  *int   `json:"media_id,omitempty"` // set when the file was uploaded through /api/media
+	URL      string `gorm:"not null" json:"url"`
+	Caption  string `json:"caption"`
+}
+
+// PostMediaInput references either an uploaded media ID or an external URL
+type PostMediaInput struct {
+	MediaID *int   `json:"media_id,omitempty"`
+	URL     string `json:"url"`
+	Caption string `json:"caption"`
 }
 
 type CreatePostRequest struct {
-	Title       string `json:"title"`
-	Body        string `json:"body"`
-	CommunityID int    `json:"community_id"`
+	Title       string             `json:"title"`
+	Kind        string             `json:"kind"`
+	Body        string             `json:"body"`
+	URL         string             `json:"url"`
+	Media       []PostMediaInput   `json:"media"`
+	Poll        *CreatePollRequest `json:"poll"`
+	CommunityID int                `json:"community_id"`
+	FlairID     *int               `json:"flair_id"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// PrivacySettings are a user's choices about who sees their profile and
+// activity. Users without a row have the defaults from DefaultPrivacy.
+type PrivacySettings struct {
+	UserID int `gorm:"primaryKey" json:"-"`
+	// PrivateProfile limits the user's posts, karma and follow lists to
+	// themselves and the people they follow
+	PrivateProfile bool      `gorm:"not null" json:"private_profile"`
+	HideFollowers  bool      `gorm:"not null" json:"hide_followers"`
+	HideVotes      bool      `gorm:"not null" json:"hide_votes"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// DefaultPrivacy is the settings of a user who never changed them: a public
+// profile and follow lists, and private votes
+func DefaultPrivacy(userID int) PrivacySettings {
+	return PrivacySettings{UserID: userID, HideVotes: true}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Report is a user's complaint about a post or, if CommentID is set, one of
+// its comments. Reported content that no moderator has reviewed yet is
+// flagged for the moderation queue. A user reports each comment once
+// (idx_report_once) and each post once (idx_report_post_once, as the comment
+// IDs of post reports are NULL).
+type Report struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	ReporterID  int       `gorm:"index;not null;uniqueIndex:idx_report_once;uniqueIndex:idx_report_post_once,where:comment_id IS NULL" json:"-"`
+	PostID      int       `gorm:"index;not null;uniqueIndex:idx_report_once;uniqueIndex:idx_report_post_once" json:"post_id"`
+	CommentID   *int      `gorm:"index;uniqueIndex:idx_report_once" json:"comment_id,omitempty"`
+	CommunityID int       `gorm:"index" json:"community_id"`
+	Reason      string    `gorm:"not null" json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateReportRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Revision content types
+const (
+	RevisionPost    = "post"
+	RevisionComment = "comment"
+)
+
+// Revision stores one version of a post or comment. Version 1 is the
+// original content; each edit appends the next version, once.
+type Revision struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	ContentType string    `gorm:"uniqueIndex:idx_revision_version;not null" json:"content_type"`
+	ContentID   int       `gorm:"uniqueIndex:idx_revision_version;not null" json:"content_id"`
+	Version     int       `gorm:"uniqueIndex:idx_revision_version;not null" json:"version"`
+	Title       string    `json:"title,omitempty"`
+	Body        string    `json:"body"`
+	EditorID    int       `json:"editor_id"`
+	Editor      User      `gorm:"foreignKey:EditorID" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedItem is a post or comment bookmarked by a user. Like Vote, exactly one
+// of PostID and CommentID is non-zero.
+type SavedItem struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    int       `gorm:"uniqueIndex:idx_saved_item;not null" json:"user_id"`
+	PostID    int       `gorm:"uniqueIndex:idx_saved_item" json:"post_id"`
+	CommentID int       `gorm:"uniqueIndex:idx_saved_item" json:"comment_id"`
+	FolderID  *int      `gorm:"index" json:"folder_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SavedFolder groups a user's saved items
+type SavedFolder struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    int       `gorm:"uniqueIndex:idx_saved_folder_name;not null" json:"user_id"`
+	Name      string    `gorm:"uniqueIndex:idx_saved_folder_name;not null;size:50" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
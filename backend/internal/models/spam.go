@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SpamModel is a saved spam classifier, retrained as moderators review
+// content
+type SpamModel struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"uniqueIndex;not null" json:"name"`
+	Data      string    `gorm:"type:text" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
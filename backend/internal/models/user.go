@@ -8,7 +8,11 @@ type User struct {
 	Email    string `gorm:"unique;not null" json:"email"`
 	Password string `gorm:"not null" json:"-"` // For email/password auth
 	Bio      string `json:"bio"`
-	Avatar   string `json:"avatar"` // Stores avatar ID (1-6) or URL
+	Avatar   string `json:"avatar"`                   // Stores avatar ID (1-6) or URL
+	Role     string `gorm:"default:user" json:"role"` // "user", "moderator", "admin"
+
+	// Uploaded avatar, if any; Avatar then holds its URL
+	AvatarMediaID *int `json:"avatar_media_id,omitempty"`
 
 	// OAuth fields
 	GoogleID     string `gorm:"index" json:"-"` // Google user ID
@@ -19,6 +23,13 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type RegisterRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
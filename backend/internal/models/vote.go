@@ -2,13 +2,24 @@ package models
 
 import "time"
 
-// Vote model - tracks individual user votes on posts
+// Vote model - tracks individual user votes on posts. A user has at most one
+// vote on each post or comment.
 type Vote struct {
 	ID        int       `gorm:"primaryKey" json:"id"`
-	UserID    int       `json:"user_id"`
-	PostID    int       `json:"post_id"`    // non-zero for post votes
-	CommentID int       `json:"comment_id"` // non-zero for comment votes
+	UserID    int       `gorm:"uniqueIndex:idx_vote_once" json:"user_id"`
+	PostID    int       `gorm:"uniqueIndex:idx_vote_once" json:"post_id"`    // non-zero for post votes
+	CommentID int       `gorm:"uniqueIndex:idx_vote_once" json:"comment_id"` // non-zero for comment votes
 	VoteType  int       `json:"vote_type"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Where the vote came from, for manipulation detection
+	IP          string `gorm:"size:64" json:"-"`
+	Fingerprint string `gorm:"size:128" json:"-"`
+
+	// Flagged votes are left out of scores and karma. Reviewed votes were
+	// checked by a moderator and are never flagged again.
+	FlaggedAt    *time.Time `gorm:"index" json:"-"`
+	FlagReason   string     `json:"-"`
+	FlagReviewed bool       `gorm:"not null;default:false" json:"-"`
 }
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Webhook posts signed event notifications to an external URL. A community
+// webhook receives the events of one community; an app webhook (CommunityID
+// nil, registered by admins) receives them from everywhere.
+type Webhook struct {
+	ID          int      `gorm:"primaryKey" json:"id"`
+	CommunityID *int     `gorm:"index" json:"community_id"`
+	CreatedByID int      `gorm:"not null" json:"created_by_id"`
+	Name        string   `gorm:"not null" json:"name"`
+	URL         string   `gorm:"not null" json:"url"`
+	Events      []string `gorm:"serializer:json;type:text" json:"events"`
+	// Secret signs deliveries; it is only shown when the webhook is created
+	Secret    string    `gorm:"not null" json:"-"`
+	Active    bool      `gorm:"default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants events of the given type
+func (w Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery states. Dead deliveries ran out of attempts; they form the
+// dead-letter log and can be redelivered by hand.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookDelivery is one event queued for, or delivered to, one webhook
+type WebhookDelivery struct {
+	ID        int `gorm:"primaryKey" json:"id"`
+	WebhookID int `gorm:"uniqueIndex:idx_webhook_event;not null" json:"webhook_id"`
+	// EventID identifies the event across webhooks and retries, so that
+	// receivers can discard duplicates
+	EventID       string     `gorm:"uniqueIndex:idx_webhook_event;not null" json:"event_id"`
+	Event         string     `gorm:"not null" json:"event"`
+	Payload       string     `gorm:"type:text" json:"payload"`
+	Status        string     `gorm:"index;not null" json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
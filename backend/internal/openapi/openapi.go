@@ -0,0 +1,267 @@
+// Package openapi builds the API's OpenAPI 3.1 document from a list of
+// operations. Request and response schemas are derived from Go types by
+// reflection, honoring json tags and gin binding rules, so they follow the
+// structs handlers actually bind and return.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.1.0"
+
+// Auth is how an operation treats the Authorization header
+type Auth int
+
+const (
+	// Public operations ignore the token
+	Public Auth = iota
+	// Optional operations personalize the response for a logged-in caller
+	Optional
+	// Required operations reject callers without a valid token
+	Required
+)
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Description string
+	// Type is a JSON Schema type; string if empty
+	Type string
+	Enum []string
+}
+
+// Operation documents one route
+type Operation struct {
+	// Method and Path are as registered with gin, e.g. "/api/posts/:id"
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+	Query       []Param
+
+	// Body is a zero value of the JSON request body, if any
+	Body any
+	// Upload names the file field of a multipart request body
+	Upload string
+
+	// Response is a zero value of the success response; a message object if nil
+	Response any
+	// Status is the success status, 200 if zero
+	Status int
+	// ContentType is the success response's media type, JSON if empty
+	ContentType string
+	// Errors lists error statuses beyond the ones implied by the operation
+	Errors []int
+}
+
+// Info describes the API as a whole
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+type message struct {
+	Message string `json:"message"`
+}
+
+// Build generates the OpenAPI document. errorBody is a zero value of the
+// struct every error response is encoded as; it becomes the Error schema.
+// Build fails if two operations share a method and path.
+func Build(info Info, ops []Operation, errorBody any) ([]byte, error) {
+	reg := newRegistry()
+	errorSchema := reg.named("Error", errorBody)
+
+	paths := make(map[string]map[string]any)
+	for _, op := range ops {
+		path := templatePath(op.Path)
+		method := strings.ToLower(op.Method)
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		if _, dup := paths[path][method]; dup {
+			return nil, fmt.Errorf("openapi: %s %s documented twice", op.Method, op.Path)
+		}
+		paths[path][method] = op.build(reg, errorSchema)
+	}
+
+	doc := map[string]any{
+		"openapi": Version,
+		"info": map[string]any{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": reg.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (op Operation) build(reg *registry, errorSchema Schema) map[string]any {
+	out := map[string]any{
+		"operationId": operationID(op.Method, op.Path),
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+	}
+	if op.Description != "" {
+		out["description"] = op.Description
+	}
+
+	switch op.Auth {
+	case Optional:
+		out["security"] = []map[string][]string{{}, {"bearerAuth": {}}}
+	case Required:
+		out["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	var params []map[string]any
+	for _, name := range pathParams(op.Path) {
+		params = append(params, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": pathParamSchema(name),
+		})
+	}
+	for _, q := range op.Query {
+		s := Schema{"type": q.Type}
+		if q.Type == "" {
+			s["type"] = "string"
+		}
+		if len(q.Enum) > 0 {
+			s["enum"] = q.Enum
+		}
+		p := map[string]any{"name": q.Name, "in": "query", "schema": s}
+		if q.Description != "" {
+			p["description"] = q.Description
+		}
+		params = append(params, p)
+	}
+	if len(params) > 0 {
+		out["parameters"] = params
+	}
+
+	switch {
+	case op.Body != nil:
+		out["requestBody"] = map[string]any{
+			"content": map[string]any{"application/json": map[string]any{"schema": reg.of(op.Body)}},
+		}
+	case op.Upload != "":
+		upload := Schema{
+			"type":       "object",
+			"properties": map[string]Schema{op.Upload: {"type": "string", "contentMediaType": "application/octet-stream"}},
+			"required":   []string{op.Upload},
+		}
+		out["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{"multipart/form-data": map[string]any{"schema": upload}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := op.Response
+	if response == nil {
+		response = message{}
+	}
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	responses := map[string]any{
+		fmt.Sprint(status): map[string]any{
+			"description": http.StatusText(status),
+			"content":     map[string]any{contentType: map[string]any{"schema": reg.of(response)}},
+		},
+	}
+	for _, code := range op.errorStatuses() {
+		responses[fmt.Sprint(code)] = map[string]any{
+			"description": http.StatusText(code),
+			"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+		}
+	}
+	out["responses"] = responses
+	return out
+}
+
+// errorStatuses are the operation's documented errors: bad input for
+// requests with a body or query, 401 when a login is required, 404 when
+// the path names a resource, 500 always, and any listed explicitly
+func (op Operation) errorStatuses() []int {
+	set := map[int]bool{http.StatusInternalServerError: true}
+	if op.Body != nil || op.Upload != "" || len(op.Query) > 0 {
+		set[http.StatusBadRequest] = true
+	}
+	if op.Auth == Required {
+		set[http.StatusUnauthorized] = true
+	}
+	if len(pathParams(op.Path)) > 0 {
+		set[http.StatusNotFound] = true
+	}
+	for _, code := range op.Errors {
+		set[code] = true
+	}
+
+	codes := make([]int, 0, len(set))
+	for code := range set {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// templatePath converts gin's ":id" segments to OpenAPI's "{id}"
+func templatePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			names = append(names, s[1:])
+		}
+	}
+	return names
+}
+
+// pathParamSchema types IDs as integers and everything else, such as
+// community names, as strings
+func pathParamSchema(name string) Schema {
+	if name == "id" || strings.HasSuffix(name, "Id") {
+		return Schema{"type": "integer"}
+	}
+	return Schema{"type": "string"}
+}
+
+// operationID derives a stable ID such as "get_api_posts_id"
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, s := range strings.Split(path, "/") {
+		s = strings.TrimLeft(s, ":*")
+		if s == "" {
+			continue
+		}
+		id += "_" + strings.NewReplacer("-", "_", ".", "_").Replace(s)
+	}
+	return id
+}
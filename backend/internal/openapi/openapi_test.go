@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type author struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type base struct {
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type article struct {
+	base
+	Title    string     `json:"title" binding:"required,max=300"`
+	Kind     string     `json:"kind" binding:"oneof=text link"`
+	Vote     int        `json:"vote" binding:"oneof=-1 1"`
+	Tags     []string   `json:"tags" binding:"max=5"`
+	Author   author     `json:"author"`
+	Editor   *author    `json:"editor,omitempty"`
+	EditedAt *time.Time `json:"edited_at"`
+	Secret   string     `json:"-"`
+	internal string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	reg := newRegistry()
+	ref := reg.of(article{})
+	if ref["$ref"] != "#/components/schemas/Article" {
+		t.Fatalf("ref = %v", ref)
+	}
+
+	s := reg.components["Article"]
+	props := s["properties"].(map[string]Schema)
+
+	want := map[string]Schema{
+		"created_at": {"type": "string", "format": "date-time"},
+		"title":      {"type": "string", "maxLength": 300},
+		"kind":       {"type": "string", "enum": []any{"text", "link"}},
+		"vote":       {"type": "integer", "enum": []any{-1, 1}},
+		"tags":       {"type": "array", "items": Schema{"type": "string"}, "maxItems": 5},
+		"author":     {"$ref": "#/components/schemas/Author"},
+		"editor":     {"$ref": "#/components/schemas/Author"},
+		"edited_at":  {"type": []string{"string", "null"}, "format": "date-time"},
+	}
+	if len(props) != len(want) {
+		t.Errorf("properties = %v", props)
+	}
+	for name, w := range want {
+		if !reflect.DeepEqual(props[name], w) {
+			t.Errorf("%s = %v, want %v", name, props[name], w)
+		}
+	}
+	if !reflect.DeepEqual(s["required"], []string{"title"}) {
+		t.Errorf("required = %v", s["required"])
+	}
+	if _, ok := reg.components["Author"]; !ok {
+		t.Error("nested struct was not registered as a component")
+	}
+}
+
+func TestFieldsDescribeAdHocObjects(t *testing.T) {
+	reg := newRegistry()
+	s := reg.of(Fields{"items": []Fields{{"id": 0}}, "next": ""})
+
+	props := s["properties"].(map[string]Schema)
+	items := props["items"]["items"].(Schema)
+	if items["properties"].(map[string]Schema)["id"]["type"] != "integer" {
+		t.Errorf("items = %v", items)
+	}
+	if props["next"]["type"] != "string" {
+		t.Errorf("next = %v", props["next"])
+	}
+}
+
+func TestBuild(t *testing.T) {
+	ops := []Operation{
+		{Method: http.MethodGet, Path: "/api/articles/:id", Tag: "Articles", Summary: "Get", Auth: Optional, Response: article{}},
+		{Method: http.MethodPost, Path: "/api/articles", Tag: "Articles", Summary: "Create", Auth: Required, Body: article{}, Status: http.StatusCreated},
+	}
+	data, err := Build(Info{Title: "Test", Version: "1"}, ops, struct {
+		Error string `json:"error"`
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name, In string
+				Schema   map[string]any
+			}
+			Security  []map[string][]string
+			Responses map[string]any
+		}
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	get := doc.Paths["/api/articles/{id}"]["get"]
+	if len(get.Parameters) != 1 || get.Parameters[0].In != "path" || get.Parameters[0].Schema["type"] != "integer" {
+		t.Errorf("parameters = %+v", get.Parameters)
+	}
+	if len(get.Security) != 2 {
+		t.Errorf("optional auth should allow anonymous callers, got %v", get.Security)
+	}
+	for _, status := range []string{"200", "404", "500"} {
+		if _, ok := get.Responses[status]; !ok {
+			t.Errorf("GET is missing a %s response", status)
+		}
+	}
+
+	create := doc.Paths["/api/articles"]["post"]
+	for _, status := range []string{"201", "400", "401", "500"} {
+		if _, ok := create.Responses[status]; !ok {
+			t.Errorf("POST is missing a %s response", status)
+		}
+	}
+}
+
+func TestBuildRejectsDuplicates(t *testing.T) {
+	op := Operation{Method: http.MethodGet, Path: "/api/articles"}
+	if _, err := Build(Info{}, []Operation{op, op}, struct{}{}); err == nil {
+		t.Error("expected an error for a route documented twice")
+	}
+}
@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is a JSON Schema object as used by OpenAPI 3.1
+type Schema map[string]any
+
+// Fields describes an ad-hoc JSON object by example: each value is a zero
+// value of the field's type, e.g. Fields{"posts": []models.Post{}, "unread": 0}.
+// A list whose items are themselves ad-hoc is written with one example item,
+// e.g. []Fields{{"id": 0}}.
+type Fields map[string]any
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// registry turns Go types into schemas, collecting named structs as
+// reusable components
+type registry struct {
+	components map[string]Schema
+	names      map[reflect.Type]string
+}
+
+func newRegistry() *registry {
+	return &registry{components: make(map[string]Schema), names: make(map[reflect.Type]string)}
+}
+
+// of returns the schema for an example value
+func (r *registry) of(v any) Schema {
+	if fields, ok := v.(Fields); ok {
+		props := make(map[string]Schema, len(fields))
+		for name, field := range fields {
+			props[name] = r.of(field)
+		}
+		return Schema{"type": "object", "properties": props}
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Len() > 0 {
+		return Schema{"type": "array", "items": r.of(rv.Index(0).Interface())}
+	}
+	return r.schema(reflect.TypeOf(v))
+}
+
+func (r *registry) schema(t reflect.Type) Schema {
+	if t == nil {
+		return Schema{}
+	}
+	if t.Kind() == reflect.Pointer {
+		return nullable(r.schema(t.Elem()))
+	}
+
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		// Custom JSON encodings can't be described by reflection
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": r.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		return r.ref(t)
+	}
+	return Schema{}
+}
+
+// ref registers a named struct as a component and points to it
+func (r *registry) ref(t reflect.Type) Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = r.componentName(t)
+		r.names[t] = name
+		// Reserve the name first so recursive types terminate
+		r.components[name] = Schema{}
+		r.components[name] = r.object(t)
+	}
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// named registers a struct type as a component under the given name
+func (r *registry) named(name string, v any) Schema {
+	t := reflect.TypeOf(v)
+	if _, ok := r.names[t]; !ok {
+		r.names[t] = name
+		r.components[name] = Schema{}
+		r.components[name] = r.object(t)
+	}
+	return Schema{"$ref": "#/components/schemas/" + r.names[t]}
+}
+
+// componentName is the exported form of the type's name, qualified by its
+// package if another package already uses it
+func (r *registry) componentName(t reflect.Type) string {
+	name := exported(t.Name())
+	if _, taken := r.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return exported(pkg) + name
+}
+
+func exported(name string) string {
+	runes := []rune(name)
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+	return string(runes)
+}
+
+// object describes a struct's JSON fields, flattening embedded structs the
+// way encoding/json does
+func (r *registry) object(t reflect.Type) Schema {
+	props := make(map[string]Schema)
+	var required []string
+	r.collect(t, props, &required)
+
+	s := Schema{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func (r *registry) collect(t reflect.Type, props map[string]Schema, required *[]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.collect(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := r.schema(f.Type)
+		if f.Type.Kind() == reflect.Pointer && strings.Contains(opts, "omitempty") {
+			// Omitted rather than null
+			s = r.schema(f.Type.Elem())
+		}
+		if applyBinding(s, f.Type, f.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		props[name] = s
+	}
+}
+
+// applyBinding adds the constraints of a gin binding tag and reports
+// whether the field is required
+func applyBinding(s Schema, t reflect.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s["format"] = "email"
+		case "url":
+			s["format"] = "uri"
+		case "oneof":
+			var values []any
+			for _, v := range strings.Fields(param) {
+				values = append(values, enumValue(t, v))
+			}
+			s["enum"] = values
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			s[boundKeyword(t, key)] = n
+		}
+	}
+	return required
+}
+
+func enumValue(t reflect.Type, v string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// boundKeyword is the JSON Schema keyword for a min or max rule on t
+func boundKeyword(t reflect.Type, rule string) string {
+	suffix := "imum"
+	switch t.Kind() {
+	case reflect.String:
+		suffix = "Length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		suffix = "Items"
+	}
+	if rule == "min" {
+		return "min" + suffix
+	}
+	return "max" + suffix
+}
+
+// nullable allows null alongside the schema
+func nullable(s Schema) Schema {
+	if typ, ok := s["type"].(string); ok {
+		out := Schema{}
+		for k, v := range s {
+			out[k] = v
+		}
+		out["type"] = []string{typ, "null"}
+		return out
+	}
+	if len(s) == 0 {
+		return s
+	}
+	return Schema{"anyOf": []Schema{s, {"type": "null"}}}
+}
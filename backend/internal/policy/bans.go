@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// BanError is returned when a banned user attempts to participate
+type BanError struct {
+	Ban models.Ban
+}
+
+func (e *BanError) Error() string {
+	scope := "this site"
+	if e.Ban.CommunityID != nil {
+		scope = "this community"
+	}
+	if e.Ban.Reason == "" {
+		return fmt.Sprintf("You are banned from %s", scope)
+	}
+	return fmt.Sprintf("You are banned from %s: %s", scope, e.Ban.Reason)
+}
+
+// activeBans restricts a query to bans that have not expired
+func activeBans(db *gorm.DB) *gorm.DB {
+	return db.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+}
+
+// CheckParticipation returns a *BanError if the user holds an active,
+// non-shadow ban that applies site-wide or to the given community.
+// Pass communityID 0 to check site-wide bans only.
+func CheckParticipation(db *gorm.DB, userID, communityID int) error {
+	query := db.Model(&models.Ban{}).
+		Scopes(activeBans).
+		Where("user_id = ? AND shadow = ?", userID, false)
+	if communityID > 0 {
+		query = query.Where("community_id IS NULL OR community_id = ?", communityID)
+	} else {
+		query = query.Where("community_id IS NULL")
+	}
+
+	var ban models.Ban
+	err := query.Order("community_id NULLS FIRST").First(&ban).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &BanError{Ban: ban}
+}
+
+// hiddenModStatuses are the moderation states only the author can see
+var hiddenModStatuses = []string{models.ModStatusPending, models.ModStatusRemoved}
+
+// VisiblePosts hides posts by shadow-banned authors, and posts removed or
+// awaiting approval, from everyone except the author. viewerID is 0 for
+// anonymous callers.
+func VisiblePosts(viewerID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("posts.user_id = ? OR COALESCE(posts.mod_status, '') NOT IN ?", viewerID, hiddenModStatuses)
+		return db.Where(`posts.user_id = ? OR NOT EXISTS (
+			SELECT 1 FROM bans
+			WHERE bans.user_id = posts.user_id AND bans.shadow
+			AND (bans.expires_at IS NULL OR bans.expires_at > ?)
+			AND (bans.community_id IS NULL OR bans.community_id = posts.community_id))`,
+			viewerID, time.Now())
+	}
+}
+
+// VisibleComments hides comments by shadow-banned authors, and comments
+// removed or awaiting approval, from everyone except the author. viewerID is
+// 0 for anonymous callers.
+func VisibleComments(viewerID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("comments.author_id = ? OR COALESCE(comments.mod_status, '') NOT IN ?", viewerID, hiddenModStatuses)
+		return db.Where(`comments.author_id = ? OR NOT EXISTS (
+			SELECT 1 FROM bans
+			WHERE bans.user_id = comments.author_id AND bans.shadow
+			AND (bans.expires_at IS NULL OR bans.expires_at > ?)
+			AND (bans.community_id IS NULL OR bans.community_id =
+				(SELECT posts.community_id FROM posts WHERE posts.id = comments.post_id)))`,
+			viewerID, time.Now())
+	}
+}
+
+// Moderates reports whether the user may moderate the given community.
+// Site-wide actions (communityID 0) are reserved for admins.
+func Moderates(db *gorm.DB, user models.User, communityID int) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	if communityID <= 0 {
+		return false
+	}
+	var community models.Community
+	if err := db.Select("id", "created_by").First(&community, communityID).Error; err != nil {
+		return false
+	}
+	return IsModerator(db, user, community)
+}
+
+// IsModerator reports whether the user may moderate a community: admins, its
+// creator and the moderators appointed to it. Site moderators only moderate
+// the communities they were appointed to.
+func IsModerator(db *gorm.DB, user models.User, community models.Community) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	if user.ID == 0 {
+		return false
+	}
+	if community.CreatedBy == user.ID {
+		return true
+	}
+	var count int64
+	db.Model(&models.CommunityModerator{}).Where("community_id = ? AND user_id = ?", community.ID, user.ID).Count(&count)
+	return count > 0
+}
+
+// ModeratedCommunities returns the IDs of the communities the user created
+// or was appointed to moderate
+func ModeratedCommunities(db *gorm.DB, userID int) ([]int, error) {
+	var ids []int
+	err := db.Model(&models.Community{}).
+		Where("created_by = ? OR id IN (?)", userID, db.Model(&models.CommunityModerator{}).Select("community_id").Where("user_id = ?", userID)).
+		Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func createUser(t *testing.T, db *gorm.DB, name, role string) models.User {
+	t.Helper()
+	user := models.User{Username: name, Email: name + "@example.com", Password: "x", Role: role}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func createCommunity(t *testing.T, db *gorm.DB, name string, creator models.User) models.Community {
+	t.Helper()
+	community := models.Community{Name: name, CreatedBy: creator.ID}
+	if err := db.Create(&community).Error; err != nil {
+		t.Fatal(err)
+	}
+	return community
+}
+
+func TestCheckParticipation(t *testing.T) {
+	db := testdb.New(t)
+	mod := createUser(t, db, "mod", models.RoleAdmin)
+	gaming := createCommunity(t, db, "gaming", mod)
+	cooking := createCommunity(t, db, "cooking", mod)
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name        string
+		ban         *models.Ban
+		communityID int
+		banned      bool
+	}{
+		{"no ban", nil, gaming.ID, false},
+		{"site-wide ban", &models.Ban{}, gaming.ID, true},
+		{"site-wide ban, site-wide check", &models.Ban{}, 0, true},
+		{"community ban", &models.Ban{CommunityID: &gaming.ID}, gaming.ID, true},
+		{"community ban elsewhere", &models.Ban{CommunityID: &gaming.ID}, cooking.ID, false},
+		{"community ban, site-wide check", &models.Ban{CommunityID: &gaming.ID}, 0, false},
+		{"expired ban", &models.Ban{ExpiresAt: &past}, gaming.ID, false},
+		{"temporary ban", &models.Ban{ExpiresAt: &future}, gaming.ID, true},
+		{"shadow ban", &models.Ban{Shadow: true}, gaming.ID, false},
+	}
+	for i, tt := range tests {
+		user := createUser(t, db, fmt.Sprintf("user%d", i), models.RoleUser)
+		if tt.ban != nil {
+			tt.ban.UserID = user.ID
+			tt.ban.BannedByID = mod.ID
+			if err := db.Create(tt.ban).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := CheckParticipation(db, user.ID, tt.communityID)
+		var banErr *BanError
+		if got := errors.As(err, &banErr); got != tt.banned {
+			t.Errorf("%s: got %v, want banned %v", tt.name, err, tt.banned)
+		}
+	}
+}
+
+func TestShadowBannedContentIsHidden(t *testing.T) {
+	db := testdb.New(t)
+	mod := createUser(t, db, "mod", models.RoleAdmin)
+	shadowed := createUser(t, db, "shadowed", models.RoleUser)
+	reader := createUser(t, db, "reader", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", mod)
+	cooking := createCommunity(t, db, "cooking", mod)
+
+	db.Create(&models.Ban{UserID: shadowed.ID, CommunityID: &gaming.ID, Shadow: true, BannedByID: mod.ID})
+	inGaming := models.Post{Title: "in gaming", UserID: shadowed.ID, CommunityID: gaming.ID}
+	inCooking := models.Post{Title: "in cooking", UserID: shadowed.ID, CommunityID: cooking.ID}
+	db.Create(&inGaming)
+	db.Create(&inCooking)
+
+	visible := func(viewerID int) []int {
+		var ids []int
+		db.Model(&models.Post{}).Scopes(VisiblePosts(viewerID)).Order("id").Pluck("id", &ids)
+		return ids
+	}
+	if got := visible(reader.ID); len(got) != 1 || got[0] != inCooking.ID {
+		t.Errorf("reader sees posts %v, want only %d", got, inCooking.ID)
+	}
+	if got := visible(0); len(got) != 1 {
+		t.Errorf("anonymous viewer sees posts %v", got)
+	}
+	if got := visible(shadowed.ID); len(got) != 2 {
+		t.Errorf("shadow-banned author sees posts %v, want both", got)
+	}
+}
+
+func TestModerates(t *testing.T) {
+	db := testdb.New(t)
+	admin := createUser(t, db, "admin", models.RoleAdmin)
+	creator := createUser(t, db, "creator", models.RoleUser)
+	appointed := createUser(t, db, "appointed", models.RoleUser)
+	siteMod := createUser(t, db, "sitemod", models.RoleModerator)
+	member := createUser(t, db, "member", models.RoleUser)
+	gaming := createCommunity(t, db, "gaming", creator)
+	cooking := createCommunity(t, db, "cooking", admin)
+
+	db.Create(&models.CommunityModerator{CommunityID: gaming.ID, UserID: appointed.ID, AddedByID: creator.ID})
+	db.Create(&models.CommunityModerator{CommunityID: cooking.ID, UserID: siteMod.ID, AddedByID: admin.ID})
+
+	tests := []struct {
+		name        string
+		user        models.User
+		communityID int
+		want        bool
+	}{
+		{"admin, community", admin, gaming.ID, true},
+		{"admin, site-wide", admin, 0, true},
+		{"creator", creator, gaming.ID, true},
+		{"creator, other community", creator, cooking.ID, false},
+		{"creator, site-wide", creator, 0, false},
+		{"appointed moderator", appointed, gaming.ID, true},
+		{"appointed moderator, other community", appointed, cooking.ID, false},
+		{"site moderator, appointed community", siteMod, cooking.ID, true},
+		{"site moderator, other community", siteMod, gaming.ID, false},
+		{"site moderator, site-wide", siteMod, 0, false},
+		{"member", member, gaming.ID, false},
+		{"missing community", creator, 9999, false},
+	}
+	for _, tt := range tests {
+		if got := Moderates(db, tt.user, tt.communityID); got != tt.want {
+			t.Errorf("%s: Moderates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	ids, err := ModeratedCommunities(db, siteMod.ID)
+	if err != nil || len(ids) != 1 || ids[0] != cooking.ID {
+		t.Errorf("ModeratedCommunities(siteMod) = %v %v", ids, err)
+	}
+	ids, _ = ModeratedCommunities(db, creator.ID)
+	if len(ids) != 1 || ids[0] != gaming.ID {
+		t.Errorf("ModeratedCommunities(creator) = %v", ids)
+	}
+}
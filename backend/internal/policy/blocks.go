@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// UnmutedPosts drops posts the viewer has hidden or whose author the viewer
+// has blocked. Anonymous viewers (viewerID 0) see everything.
+func UnmutedPosts(viewerID int) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		return db.Where(`NOT EXISTS (
+			SELECT 1 FROM hidden_posts
+			WHERE hidden_posts.post_id = posts.id AND hidden_posts.user_id = ?)
+			AND NOT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE blocks.blocker_id = ? AND blocks.blocked_id = posts.user_id)`,
+			viewerID, viewerID)
+	}
+}
+
+// BlockedIDs returns the set of users the viewer has blocked
+func BlockedIDs(db *gorm.DB, viewerID int) map[int]bool {
+	blocked := make(map[int]bool)
+	if viewerID == 0 {
+		return blocked
+	}
+
+	var ids []int
+	db.Model(&models.Block{}).Where("blocker_id = ?", viewerID).Pluck("blocked_id", &ids)
+	for _, id := range ids {
+		blocked[id] = true
+	}
+	return blocked
+}
+
+// IsBlocked reports whether blockerID has blocked blockedID
+func IsBlocked(db *gorm.DB, blockerID, blockedID int) bool {
+	var count int64
+	db.Model(&models.Block{}).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// Audience is how a viewer relates to the owner of a profile
+type Audience struct {
+	Self bool
+	// Followed is set when the owner follows the viewer
+	Followed bool
+	// Follower is set when the viewer follows the owner
+	Follower bool
+}
+
+// LoadPrivacy returns a user's privacy settings, or the defaults if they
+// never changed them
+func LoadPrivacy(db *gorm.DB, userID int) models.PrivacySettings {
+	var settings models.PrivacySettings
+	if err := db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		return models.DefaultPrivacy(userID)
+	}
+	return settings
+}
+
+// AudienceOf works out how the viewer relates to the owner. viewerID is 0
+// for anonymous callers.
+func AudienceOf(db *gorm.DB, viewerID, ownerID int) Audience {
+	if viewerID == 0 {
+		return Audience{}
+	}
+	if viewerID == ownerID {
+		return Audience{Self: true}
+	}
+
+	var follows []models.Follow
+	db.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+		ownerID, viewerID, viewerID, ownerID).Find(&follows)
+
+	var a Audience
+	for _, f := range follows {
+		if f.FollowerID == ownerID {
+			a.Followed = true
+		} else {
+			a.Follower = true
+		}
+	}
+	return a
+}
+
+// LoadPrivacies is LoadPrivacy for several users in one query
+func LoadPrivacies(db *gorm.DB, userIDs []int) (map[int]models.PrivacySettings, error) {
+	var rows []models.PrivacySettings
+	if err := db.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int]models.PrivacySettings, len(userIDs))
+	for _, id := range userIDs {
+		out[id] = models.DefaultPrivacy(id)
+	}
+	for _, row := range rows {
+		out[row.UserID] = row
+	}
+	return out, nil
+}
+
+// AudiencesOf is AudienceOf for several owners in one query
+func AudiencesOf(db *gorm.DB, viewerID int, ownerIDs []int) (map[int]Audience, error) {
+	out := make(map[int]Audience, len(ownerIDs))
+	if viewerID == 0 {
+		return out, nil
+	}
+
+	var follows []models.Follow
+	err := db.Where("(follower_id IN ? AND following_id = ?) OR (follower_id = ? AND following_id IN ?)",
+		ownerIDs, viewerID, viewerID, ownerIDs).Find(&follows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, ownerID := range ownerIDs {
+		if ownerID == viewerID {
+			out[ownerID] = Audience{Self: true}
+		}
+	}
+	for _, f := range follows {
+		if f.FollowerID == f.FollowingID {
+			continue
+		}
+		if f.FollowingID == viewerID {
+			a := out[f.FollowerID]
+			a.Followed = true
+			out[f.FollowerID] = a
+		} else {
+			a := out[f.FollowingID]
+			a.Follower = true
+			out[f.FollowingID] = a
+		}
+	}
+	return out, nil
+}
+
+// CanViewProfile reports whether the viewer may see a user's posts, karma
+// and follower counts. Following a private profile isn't enough to see it;
+// its owner chooses their audience by following them.
+func CanViewProfile(s models.PrivacySettings, a Audience) bool {
+	return a.Self || !s.PrivateProfile || a.Followed
+}
+
+// CanViewFollowers reports whether the viewer may list who follows the user
+// and whom the user follows
+func CanViewFollowers(s models.PrivacySettings, a Audience) bool {
+	return a.Self || (!s.HideFollowers && CanViewProfile(s, a))
+}
+
+// CanViewVotes reports whether the viewer may list the posts the user voted on
+func CanViewVotes(s models.PrivacySettings, a Audience) bool {
+	return a.Self || (!s.HideVotes && CanViewProfile(s, a))
+}
@@ -0,0 +1,91 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/testdb"
+)
+
+func TestPrivacyRules(t *testing.T) {
+	var (
+		anon     = Audience{}
+		self     = Audience{Self: true}
+		follower = Audience{Follower: true}
+		followed = Audience{Followed: true}
+	)
+	defaults := models.DefaultPrivacy(1)
+	private := defaults
+	private.PrivateProfile = true
+	hidden := defaults
+	hidden.HideFollowers = true
+	showVotes := defaults
+	showVotes.HideVotes = false
+
+	tests := []struct {
+		name     string
+		rule     func(models.PrivacySettings, Audience) bool
+		settings models.PrivacySettings
+		audience Audience
+		want     bool
+	}{
+		{"public profile", CanViewProfile, defaults, anon, true},
+		{"private profile, stranger", CanViewProfile, private, anon, false},
+		{"private profile, follower", CanViewProfile, private, follower, false},
+		{"private profile, followed by owner", CanViewProfile, private, followed, true},
+		{"private profile, self", CanViewProfile, private, self, true},
+
+		{"public followers", CanViewFollowers, defaults, anon, true},
+		{"hidden followers", CanViewFollowers, hidden, followed, false},
+		{"hidden followers, self", CanViewFollowers, hidden, self, true},
+		{"private profile hides followers", CanViewFollowers, private, anon, false},
+
+		{"votes hidden by default", CanViewVotes, defaults, follower, false},
+		{"own votes", CanViewVotes, defaults, self, true},
+		{"shown votes", CanViewVotes, showVotes, anon, true},
+	}
+	for _, tt := range tests {
+		if got := tt.rule(tt.settings, tt.audience); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBatchedPrivacyMatchesSingle(t *testing.T) {
+	db := testdb.New(t)
+	viewer := createUser(t, db, "viewer", models.RoleUser)
+	private := createUser(t, db, "private", models.RoleUser)
+	mutual := createUser(t, db, "mutual", models.RoleUser)
+	stranger := createUser(t, db, "stranger", models.RoleUser)
+
+	settings := models.DefaultPrivacy(private.ID)
+	settings.PrivateProfile = true
+	db.Create(&settings)
+	db.Create(&models.Follow{FollowerID: private.ID, FollowingID: viewer.ID})
+	db.Create(&models.Follow{FollowerID: viewer.ID, FollowingID: mutual.ID})
+	db.Create(&models.Follow{FollowerID: mutual.ID, FollowingID: viewer.ID})
+
+	ids := []int{viewer.ID, private.ID, mutual.ID, stranger.ID}
+	privacies, err := LoadPrivacies(db, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, viewerID := range []int{0, viewer.ID, stranger.ID} {
+		audiences, err := AudiencesOf(db, viewerID, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			if got, want := audiences[id], AudienceOf(db, viewerID, id); got != want {
+				t.Errorf("viewer %d, owner %d: AudiencesOf = %+v, want %+v", viewerID, id, got, want)
+			}
+		}
+	}
+	for _, id := range ids {
+		got, want := privacies[id], LoadPrivacy(db, id)
+		if got.PrivateProfile != want.PrivateProfile || got.HideFollowers != want.HideFollowers ||
+			got.HideVotes != want.HideVotes {
+			t.Errorf("owner %d: LoadPrivacies = %+v, want %+v", id, got, want)
+		}
+	}
+}
@@ -4,24 +4,55 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apierr"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/apiversion"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/grpcapi"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/handlers"
 	"github.com/emilythestrangee/reddit-clone/backend/internal/middleware"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/openapi"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/storage"
 )
 
+// versions are served under /api/<name>. A new version starts as a copy of
+// the previous one and overrides the endpoints whose contract changes, e.g.
+//
+//	v2 := (&apiversion.Version{Name: "v2"}).Override(http.MethodGet, "/posts", handler)
+//
+// Deprecate a version by setting Deprecated, Successor and, once decided,
+// Sunset.
+var versions = []*apiversion.Version{
+	{Name: "v1"},
+}
+
+// unversioned is /api itself, kept for app builds released before
+// versioning; it serves v1 and points clients to it
+var unversioned = &apiversion.Version{
+	Deprecated: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+	Successor:  "/api/v1",
+}
+
 type Server struct {
 	db      *database.Database
 	handler *handlers.Handler
+
+	// orm backs route middleware such as the ban check
+	orm *gorm.DB
 }
 
-// NewServer creates and configures a new server
-func NewServer() *http.Server {
+// NewServer creates and configures the HTTP server, and the gRPC server for
+// internal services that shares its handlers' database and event bus
+func NewServer() (*http.Server, *grpc.Server) {
 	// Initialize database
 	db, err := database.NewDatabase()
 	if err != nil {
@@ -35,6 +66,7 @@ func NewServer() *http.Server {
 	newServer := &Server{
 		db:      db,
 		handler: handler,
+		orm:     database.New().GetDB(),
 	}
 
 	// Configure Gin router
@@ -54,80 +86,233 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	grpcOptions, err := grpcapi.OptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid gRPC TLS configuration: %v", err)
+	}
+	grpcServer := grpcapi.NewServer(newServer.orm, handler.Events, grpcOptions...)
+
 	log.Printf("🚀 Server starting on port %s\n", port)
 	fmt.Println("📝 Press Ctrl+C to stop the server")
 
-	return server
+	return server, grpcServer
+}
+
+// trustedProxies reads the comma-separated IPs and CIDRs of the reverse
+// proxies in front of the API from TRUSTED_PROXIES. With none, the client IP
+// is the address of the connection.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
 }
 
 // RegisterRoutes sets up all application routes
 func (s *Server) RegisterRoutes() *gin.Engine {
 	r := gin.Default()
+	// Client IPs feed vote abuse detection, so X-Forwarded-For is only
+	// believed when it comes from one of our own proxies
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	r.Use(middleware.RequestID())
 
 	// CORS configuration
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", middleware.RequestIDHeader, middleware.ClientVersionHeader, "X-Device-Fingerprint"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader, "Deprecation", "Sunset", "Link"},
 		AllowCredentials: true,
 		MaxAge:           12 * 3600,
 	}))
 
+	// Unknown routes get the same error envelope as everything else
+	r.NoRoute(func(c *gin.Context) {
+		apierr.NotFound(c, "Route not found")
+	})
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	// API routes
+	// Serve locally stored uploads
+	if local, ok := s.handler.Storage.(*storage.Local); ok {
+		if base, err := url.Parse(local.BaseURL); err == nil && base.Path != "" {
+			r.Static(base.Path, local.Dir)
+		}
+	}
+
+	// API routes. Clients pick a version with /api/v1; plain /api serves v1
+	// for app builds released before versioning.
 	api := r.Group("/api")
+	api.Use(middleware.MinClientVersion(os.Getenv("MIN_CLIENT_VERSION")))
 	{
-		// Auth routes (public)
-		api.POST("/register", s.handler.Auth.Register)
-		api.POST("/login", s.handler.Auth.Login)
+		// API reference, generated from handlers.Operations
+		api.GET("/openapi.json", s.serveOpenAPI())
+		api.GET("/docs", gin.WrapH(openapi.Docs("/api/openapi.json")))
+
+		s.registerAPI(apiversion.Mount(api, "", unversioned))
+		for _, v := range versions {
+			s.registerAPI(apiversion.Mount(api, "/"+v.Name, v))
+			if unused := v.Unused(); len(unused) > 0 {
+				log.Fatalf("API %s overrides unknown routes: %v", v.Name, unused)
+			}
+		}
+	}
+
+	return r
+}
 
-		// OAuth routes
-		api.POST("/auth/google", s.handler.Auth.GoogleLogin)
-		api.POST("/auth/apple", s.handler.Auth.AppleLogin)
+// registerAPI registers the API routes of one version
+func (s *Server) registerAPI(api *apiversion.Routes) {
+	// Banned users can still read, but not participate
+	notBanned := middleware.RequireNotBanned(s.orm)
 
+	// Auth routes (public)
+	api.POST("/register", s.handler.Auth.Register)
+	api.POST("/login", s.handler.Auth.Login)
+
+	// OAuth routes
+	api.POST("/auth/google", s.handler.Auth.GoogleLogin)
+	api.POST("/auth/apple", s.handler.Auth.AppleLogin)
+
+	// Public reads (optional auth so authors can see their own shadow-banned content)
+	public := api.Group("")
+	public.Use(middleware.OptionalAuthMiddleware())
+	{
 		// Post routes (public reads)
-		api.GET("/posts", s.handler.Post.GetPosts)
-		api.GET("/posts/:id", s.handler.Post.GetPost)
+		public.GET("/posts", s.handler.Post.GetPosts)
+		public.GET("/posts/:id", s.handler.Post.GetPost)
+
+		// Site-wide feeds
+		public.GET("/feed/popular", s.handler.Post.GetPopularFeed)
+		public.GET("/feed/all", s.handler.Post.GetAllFeed)
 
 		// Comment routes (public reads)
-		api.GET("/posts/:id/comments", s.handler.Comment.GetComments)
+		public.GET("/posts/:id/comments", s.handler.Comment.GetComments)
 
 		// User routes (public reads)
-		api.GET("/users/:id", s.handler.User.GetUserProfile)
-		api.GET("/users/:id/followers", s.handler.User.GetFollowers)
-		api.GET("/users/:id/following", s.handler.User.GetFollowing)
-
-		// Protected routes (authentication required)
-		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware())
-		{
-			// Auth protected routes
-			protected.GET("/me", s.handler.Auth.GetMe)
-
-			// Post protected routes
-			protected.POST("/posts", s.handler.Post.CreatePost)
-			protected.PUT("/posts/:id", s.handler.Post.UpdatePost)
-			protected.DELETE("/posts/:id", s.handler.Post.DeletePost)
-			protected.POST("/posts/:id/vote", s.handler.Post.VotePost)
-
-			// Comment protected routes
-			protected.POST("/posts/:id/comments", s.handler.Comment.CreateComment)
-			protected.POST("/comments/:commentId/upvote", s.handler.Comment.UpvoteComment)
-			protected.POST("/comments/:commentId/downvote", s.handler.Comment.DownvoteComment)
-			protected.PUT("/comments/:commentId", s.handler.Comment.UpdateComment)
-			protected.DELETE("/comments/:commentId", s.handler.Comment.DeleteComment)
-
-			// User protected routes
-			protected.PUT("/users/:id", s.handler.User.UpdateUserProfile)
-			protected.POST("/users/:id/follow", s.handler.User.FollowUser)
-			protected.DELETE("/users/:id/follow", s.handler.User.UnfollowUser)
-		}
+		public.GET("/users/:id", s.handler.User.GetUserProfile)
+		public.GET("/users/:id/followers", s.handler.User.GetFollowers)
+		public.GET("/users/:id/following", s.handler.User.GetFollowing)
+		public.GET("/users/:id/upvoted", s.handler.User.GetUpvotedPosts)
+
+		// Community routes (public reads)
+		public.GET("/communities", s.handler.Community.GetCommunities)
+		public.GET("/communities/trending", s.handler.Community.GetTrending)
+		public.GET("/communities/:name", s.handler.Community.GetCommunity)
+		public.GET("/communities/:name/flair", s.handler.Community.GetFlairTemplates)
+		public.GET("/communities/:name/rules", s.handler.Community.GetRules)
+		public.GET("/communities/:name/moderators", s.handler.Community.GetModerators)
+
+		// Edit history (authors and moderators, or everyone if public history is enabled)
+		public.GET("/posts/:id/revisions", s.handler.Revision.GetPostRevisions)
+		public.GET("/comments/:commentId/revisions", s.handler.Revision.GetCommentRevisions)
+
+		// GraphQL; mutations check for a token and bans themselves
+		public.POST("/graphql", s.handler.GraphQL.Serve)
 	}
 
-	return r
+	// Protected routes (authentication required)
+	protected := api.Group("")
+	protected.Use(middleware.AuthMiddleware())
+	{
+		// Auth protected routes
+		protected.GET("/me", s.handler.Auth.GetMe)
+		protected.GET("/me/mentions", s.handler.Mention.GetMyMentions)
+		protected.POST("/me/mentions/read", s.handler.Mention.MarkMentionsRead)
+		protected.GET("/me/saved", s.handler.Saved.GetSaved)
+		protected.GET("/me/saved/folders", s.handler.Saved.GetFolders)
+		protected.POST("/me/saved/folders", s.handler.Saved.CreateFolder)
+		protected.DELETE("/me/saved/folders/:folderId", s.handler.Saved.DeleteFolder)
+		protected.GET("/me/hidden", s.handler.Block.GetHiddenPosts)
+		protected.GET("/me/blocked", s.handler.Block.GetBlockedUsers)
+		protected.GET("/me/communities", s.handler.Community.GetMyCommunities)
+		protected.GET("/me/privacy", s.handler.User.GetPrivacy)
+		protected.PUT("/me/privacy", s.handler.User.UpdatePrivacy)
+
+		// Feed routes
+		protected.GET("/feed/home", s.handler.Post.GetHomeFeed)
+
+		// Community protected routes
+		protected.POST("/communities", notBanned, s.handler.Community.CreateCommunity)
+		protected.POST("/communities/:name/join", s.handler.Community.JoinCommunity)
+		protected.DELETE("/communities/:name/join", s.handler.Community.LeaveCommunity)
+		protected.POST("/communities/:name/flair", s.handler.Community.CreateFlairTemplate)
+		protected.DELETE("/communities/:name/flair/:flairId", s.handler.Community.DeleteFlairTemplate)
+		protected.PUT("/communities/:name/user-flair", s.handler.Community.SetUserFlair)
+		protected.PUT("/communities/:name/rules", s.handler.Community.UpdateRules)
+		protected.POST("/communities/:name/moderators", s.handler.Community.AddModerator)
+		protected.DELETE("/communities/:name/moderators/:userId", s.handler.Community.RemoveModerator)
+
+		// Post protected routes
+		protected.POST("/posts", notBanned, s.handler.Post.CreatePost)
+		protected.PUT("/posts/:id", notBanned, s.handler.Post.UpdatePost)
+		protected.DELETE("/posts/:id", s.handler.Post.DeletePost)
+		protected.POST("/posts/:id/vote", notBanned, s.handler.Post.VotePost)
+		protected.POST("/posts/:id/poll/vote", notBanned, s.handler.Post.VotePoll)
+		protected.POST("/posts/:id/crosspost", notBanned, s.handler.Post.Crosspost)
+		protected.POST("/posts/:id/save", s.handler.Saved.SavePost)
+		protected.DELETE("/posts/:id/save", s.handler.Saved.UnsavePost)
+		protected.POST("/posts/:id/report", notBanned, s.handler.Moderation.ReportPost)
+		protected.POST("/posts/:id/hide", s.handler.Block.HidePost)
+		protected.DELETE("/posts/:id/hide", s.handler.Block.UnhidePost)
+
+		// Comment protected routes
+		protected.POST("/posts/:id/comments", notBanned, s.handler.Comment.CreateComment)
+		protected.POST("/comments/:commentId/upvote", notBanned, s.handler.Comment.UpvoteComment)
+		protected.POST("/comments/:commentId/downvote", notBanned, s.handler.Comment.DownvoteComment)
+		protected.PUT("/comments/:commentId", notBanned, s.handler.Comment.UpdateComment)
+		protected.DELETE("/comments/:commentId", s.handler.Comment.DeleteComment)
+		protected.POST("/comments/:commentId/report", notBanned, s.handler.Moderation.ReportComment)
+		protected.POST("/comments/:commentId/save", s.handler.Saved.SaveComment)
+		protected.DELETE("/comments/:commentId/save", s.handler.Saved.UnsaveComment)
+
+		// Media upload routes
+		protected.POST("/media", notBanned, s.handler.Media.Upload)
+
+		// User protected routes
+		protected.PUT("/users/:id", s.handler.User.UpdateUserProfile)
+		protected.POST("/users/:id/follow", s.handler.User.FollowUser)
+		protected.DELETE("/users/:id/follow", s.handler.User.UnfollowUser)
+		protected.POST("/users/:id/block", s.handler.Block.BlockUser)
+		protected.DELETE("/users/:id/block", s.handler.Block.UnblockUser)
+
+		// Moderation routes (moderators and admins)
+		protected.GET("/users/:id/bans", s.handler.Moderation.GetUserBans)
+		protected.POST("/users/:id/bans", s.handler.Moderation.BanUser)
+		protected.DELETE("/users/:id/bans/:banId", s.handler.Moderation.UnbanUser)
+		protected.PUT("/posts/:id/mod-status", s.handler.Moderation.ModeratePost)
+		protected.PUT("/comments/:commentId/mod-status", s.handler.Moderation.ModerateComment)
+		protected.GET("/moderation/queue", s.handler.Moderation.GetModQueue)
+		protected.GET("/moderation/votes", s.handler.Moderation.GetVoteReport)
+		protected.POST("/moderation/votes/review", s.handler.Moderation.ReviewVotes)
+
+		// Webhooks (community moderators, or admins for app webhooks)
+		protected.GET("/communities/:name/webhooks", s.handler.Webhook.GetCommunityWebhooks)
+		protected.POST("/communities/:name/webhooks", s.handler.Webhook.CreateCommunityWebhook)
+		protected.GET("/webhooks", s.handler.Webhook.GetAppWebhooks)
+		protected.POST("/webhooks", s.handler.Webhook.CreateAppWebhook)
+		protected.PUT("/webhooks/:webhookId", s.handler.Webhook.UpdateWebhook)
+		protected.DELETE("/webhooks/:webhookId", s.handler.Webhook.DeleteWebhook)
+		protected.GET("/webhooks/:webhookId/deliveries", s.handler.Webhook.GetDeliveries)
+		protected.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", s.handler.Webhook.Redeliver)
+	}
+}
+
+// serveOpenAPI builds the OpenAPI document once and serves it as is
+func (s *Server) serveOpenAPI() gin.HandlerFunc {
+	spec, err := openapi.Build(handlers.APIInfo, handlers.Operations(), apierr.Body{})
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	}
 }
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/handlers"
+)
+
+// testRouter registers the real routes without connecting to a database;
+// handlers are never invoked except the ones serving documentation
+func testRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	s := &Server{handler: &handlers.Handler{}}
+	return s.RegisterRoutes()
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	r := testRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json = %d", w.Code)
+	}
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.1") {
+		t.Errorf("openapi = %q, want 3.1", doc.OpenAPI)
+	}
+
+	// Unversioned routes are aliases of v1, which is what is documented
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		path := openAPIPath(route.Path)
+		if alias, ok := strings.CutPrefix(path, "/api/"); ok && !strings.HasPrefix(alias, "v1/") && !metaRoutes[path] {
+			continue
+		}
+		key := strings.ToLower(route.Method) + " " + path
+		registered[key] = true
+		if _, ok := doc.Paths[path][strings.ToLower(route.Method)]; !ok {
+			t.Errorf("%s %s is not in the OpenAPI document; add it to handlers.Operations", route.Method, route.Path)
+		}
+	}
+	for path, methods := range doc.Paths {
+		for method := range methods {
+			if !registered[method+" "+path] {
+				t.Errorf("%s %s is documented but not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// metaRoutes live under /api but outside the versions
+var metaRoutes = map[string]bool{"/api/openapi.json": true, "/api/docs": true}
+
+func TestUnversionedRoutesAliasV1(t *testing.T) {
+	r := testRouter()
+
+	routes := make(map[string]bool)
+	for _, route := range r.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	for _, route := range r.Routes() {
+		rest, ok := strings.CutPrefix(route.Path, "/api/")
+		if !ok || strings.HasPrefix(rest, "v1/") || metaRoutes[route.Path] {
+			continue
+		}
+		if !routes[route.Method+" /api/v1/"+rest] {
+			t.Errorf("%s %s has no v1 equivalent", route.Method, route.Path)
+		}
+	}
+}
+
+func TestVersionHeaders(t *testing.T) {
+	r := testRouter()
+
+	// Rejected by the auth middleware before any handler runs
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/me = %d", w.Code)
+	}
+	if w.Header().Get("Deprecation") == "" || !strings.Contains(w.Header().Get("Link"), "/api/v1") {
+		t.Errorf("unversioned route is not marked deprecated: %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/me", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/v1/me = %d", w.Code)
+	}
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("current version is marked deprecated: %v", w.Header())
+	}
+}
+
+func TestDocsPage(t *testing.T) {
+	w := httptest.NewRecorder()
+	testRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /api/docs = %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), `specURL = "/api/openapi.json"`) {
+		t.Error("docs page does not load the OpenAPI document")
+	}
+}
+
+func TestClientIPIgnoresUntrustedForwarding(t *testing.T) {
+	tests := []struct {
+		proxies string
+		want    string
+	}{
+		{"", "192.0.2.1"},
+		{"10.0.0.0/8", "192.0.2.1"},
+		{"10.0.0.0/8, 192.0.2.1", "203.0.113.9"},
+	}
+	for _, tt := range tests {
+		t.Setenv("TRUSTED_PROXIES", tt.proxies)
+		r := testRouter()
+		r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("TRUSTED_PROXIES=%q: client IP %q, want %q", tt.proxies, w.Body.String(), tt.want)
+		}
+	}
+}
+
+// openAPIPath converts gin's ":id" segments to "{id}"
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
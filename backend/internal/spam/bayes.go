@@ -0,0 +1,150 @@
+package spam
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Classifier estimates how likely text is to be spam and learns from
+// moderator decisions
+type Classifier interface {
+	// SpamProbability returns a probability in [0, 1]; 0.5 means no opinion
+	SpamProbability(text string) float64
+	// Learn records that a moderator judged text to be spam or not
+	Learn(text string, spam bool)
+}
+
+// minDocs is how many examples of each class the classifier needs before
+// it offers an opinion
+const minDocs = 5
+
+var tokenPattern = regexp.MustCompile(`[a-z0-9][a-z0-9'$]{1,29}`)
+
+// tokens splits text into unique lower-cased words. Link hosts become
+// "domain:" tokens so spam domains are learned too.
+func tokens(text string) []string {
+	text = strings.ToLower(text)
+	seen := make(map[string]bool)
+	var out []string
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		host := strings.TrimPrefix(strings.TrimPrefix(link, "http://"), "https://")
+		host = strings.TrimPrefix(host, "www.")
+		if i := strings.IndexAny(host, "/?#"); i >= 0 {
+			host = host[:i]
+		}
+		if host != "" {
+			add("domain:" + host)
+		}
+	}
+	for _, word := range tokenPattern.FindAllString(linkPattern.ReplaceAllString(text, " "), -1) {
+		add(word)
+	}
+	return out
+}
+
+// NaiveBayes is a Bernoulli-style Naive Bayes classifier over the words of
+// a document. It is safe for concurrent use.
+type NaiveBayes struct {
+	mu       sync.RWMutex
+	spamDocs int
+	hamDocs  int
+	// counts maps a token to the number of spam and ham documents containing it
+	counts map[string]*[2]int
+}
+
+func NewNaiveBayes() *NaiveBayes {
+	return &NaiveBayes{counts: make(map[string]*[2]int)}
+}
+
+// Learn adds a labelled document
+func (nb *NaiveBayes) Learn(text string, spam bool) {
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+
+	class := 1
+	if spam {
+		class = 0
+		nb.spamDocs++
+	} else {
+		nb.hamDocs++
+	}
+	for _, t := range tokens(text) {
+		c, ok := nb.counts[t]
+		if !ok {
+			c = new([2]int)
+			nb.counts[t] = c
+		}
+		c[class]++
+	}
+}
+
+// SpamProbability combines the per-token likelihoods with Laplace
+// smoothing. Tokens never seen in training are ignored.
+func (nb *NaiveBayes) SpamProbability(text string) float64 {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+
+	if nb.spamDocs < minDocs || nb.hamDocs < minDocs {
+		return 0.5
+	}
+
+	total := float64(nb.spamDocs + nb.hamDocs)
+	logSpam := math.Log(float64(nb.spamDocs) / total)
+	logHam := math.Log(float64(nb.hamDocs) / total)
+	for _, t := range tokens(text) {
+		c, ok := nb.counts[t]
+		if !ok {
+			continue
+		}
+		logSpam += math.Log(float64(c[0]+1) / float64(nb.spamDocs+2))
+		logHam += math.Log(float64(c[1]+1) / float64(nb.hamDocs+2))
+	}
+
+	// p = spam / (spam + ham), computed in log space to avoid underflow
+	return 1 / (1 + math.Exp(logHam-logSpam))
+}
+
+type naiveBayesState struct {
+	SpamDocs int               `json:"spam_docs"`
+	HamDocs  int               `json:"ham_docs"`
+	Counts   map[string][2]int `json:"counts"`
+}
+
+// MarshalJSON saves the trained model
+func (nb *NaiveBayes) MarshalJSON() ([]byte, error) {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+
+	state := naiveBayesState{SpamDocs: nb.spamDocs, HamDocs: nb.hamDocs, Counts: make(map[string][2]int, len(nb.counts))}
+	for t, c := range nb.counts {
+		state.Counts[t] = *c
+	}
+	return json.Marshal(state)
+}
+
+// UnmarshalJSON restores a model saved with MarshalJSON
+func (nb *NaiveBayes) UnmarshalJSON(data []byte) error {
+	var state naiveBayesState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	nb.mu.Lock()
+	defer nb.mu.Unlock()
+	nb.spamDocs, nb.hamDocs = state.SpamDocs, state.HamDocs
+	nb.counts = make(map[string]*[2]int, len(state.Counts))
+	for t, c := range state.Counts {
+		nb.counts[t] = &c
+	}
+	return nil
+}
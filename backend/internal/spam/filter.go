@@ -0,0 +1,96 @@
+package spam
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+const (
+	// modelName is the saved classifier's row in spam_models
+	modelName = "naive_bayes"
+
+	// duplicateWindow is how far back identical content counts as a repost
+	duplicateWindow = 7 * 24 * time.Hour
+)
+
+// Filter gathers the features of new content from the database and scores it
+type Filter struct {
+	db         *gorm.DB
+	cfg        Config
+	classifier Classifier
+}
+
+func NewFilter(db *gorm.DB, cfg Config, classifier Classifier) *Filter {
+	return &Filter{db: db, cfg: cfg, classifier: classifier}
+}
+
+// LoadNaiveBayes restores the saved Naive Bayes model, or returns an
+// untrained one if none was saved yet
+func LoadNaiveBayes(db *gorm.DB) (*NaiveBayes, error) {
+	nb := NewNaiveBayes()
+	var saved models.SpamModel
+	if err := db.Where("name = ?", modelName).Limit(1).Find(&saved).Error; err != nil {
+		return nb, err
+	}
+	if saved.Data == "" {
+		return nb, nil
+	}
+	return nb, json.Unmarshal([]byte(saved.Data), nb)
+}
+
+// features looks up what the heuristics need to know about content
+func (f *Filter) features(content Content, hash string, now time.Time) Features {
+	features := Features{LinkDensity: LinkDensity(content.Text())}
+
+	var posts, comments int64
+	if checksDuplicates(content) {
+		since := now.Add(-duplicateWindow)
+		f.db.Model(&models.Post{}).Where("content_hash = ? AND created_at > ?", hash, since).Count(&posts)
+		f.db.Model(&models.Comment{}).Where("content_hash = ? AND created_at > ?", hash, since).Count(&comments)
+		features.Duplicates = int(posts + comments)
+	}
+
+	var author models.User
+	if f.db.Select("id", "created_at").First(&author, content.AuthorID).Error == nil {
+		features.AccountAge = now.Sub(author.CreatedAt)
+	}
+
+	hourAgo := now.Add(-time.Hour)
+	f.db.Model(&models.Post{}).Where("user_id = ? AND created_at > ?", content.AuthorID, hourAgo).Count(&posts)
+	f.db.Model(&models.Comment{}).Where("author_id = ? AND created_at > ?", content.AuthorID, hourAgo).Count(&comments)
+	features.RecentPosts = int(posts + comments)
+
+	return features
+}
+
+// Score rates content about to be created
+func (f *Filter) Score(content Content, now time.Time) Result {
+	hash := Hash(content)
+	result := Evaluate(f.features(content, hash, now), f.classifier.SpamProbability(content.Text()), f.cfg)
+	result.Hash = hash
+	return result
+}
+
+// Learn trains the classifier on a moderator's decision and saves it if it
+// can be serialized
+func (f *Filter) Learn(content Content, spam bool) error {
+	f.classifier.Learn(content.Text(), spam)
+
+	m, ok := f.classifier.(json.Marshaler)
+	if !ok {
+		return nil
+	}
+	data, err := m.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	saved := models.SpamModel{Name: modelName}
+	f.db.Where("name = ?", modelName).Limit(1).Find(&saved)
+	saved.Data = string(data)
+	return f.db.Save(&saved).Error
+}
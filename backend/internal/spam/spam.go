@@ -0,0 +1,177 @@
+// Package spam scores new posts and comments. Heuristics (link density,
+// repeated content, account age and posting velocity) are combined with a
+// pluggable classifier; content scoring at or above the threshold is held
+// for moderator review.
+package spam
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Content is a post or comment about to be created
+type Content struct {
+	Title    string
+	Body     string
+	URL      string
+	AuthorID int
+}
+
+// Text is everything a classifier sees of the content
+func (c Content) Text() string {
+	return strings.TrimSpace(c.Title + "\n" + c.Body + "\n" + c.URL)
+}
+
+// Features are the signals the heuristics score
+type Features struct {
+	// LinkDensity is links per word of text
+	LinkDensity float64
+	// Duplicates is how much identical content was posted recently
+	Duplicates int
+	// AccountAge is how old the author's account is
+	AccountAge time.Duration
+	// RecentPosts is how many posts and comments the author created in the
+	// last hour
+	RecentPosts int
+}
+
+// Config holds the scoring thresholds
+type Config struct {
+	// Threshold is the score at or above which content is held for review
+	Threshold float64
+	// MaxPerHour is the posting rate above which an author looks automated
+	MaxPerHour int
+}
+
+// DefaultConfig returns the thresholds used when none are configured
+func DefaultConfig() Config {
+	return Config{Threshold: 0.7, MaxPerHour: 10}
+}
+
+// ConfigFromEnv overrides the defaults with SPAM_THRESHOLD and
+// SPAM_MAX_PER_HOUR
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if v := os.Getenv("SPAM_THRESHOLD"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return cfg, fmt.Errorf("SPAM_THRESHOLD must be between 0 and 1")
+		}
+		cfg.Threshold = f
+	}
+	if v := os.Getenv("SPAM_MAX_PER_HOUR"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("SPAM_MAX_PER_HOUR must be a positive integer")
+		}
+		cfg.MaxPerHour = n
+	}
+	return cfg, nil
+}
+
+// Result is the outcome of scoring content
+type Result struct {
+	// Hash is the content hash used to find duplicates
+	Hash  string
+	Score float64
+	// Spam reports whether the score reached the threshold
+	Spam bool
+	// Reasons lists the signals that contributed to the score
+	Reasons []string
+}
+
+// Reason summarizes the result for moderators
+func (r Result) Reason() string {
+	return fmt.Sprintf("spam score %.2f: %s", r.Score, strings.Join(r.Reasons, ", "))
+}
+
+var (
+	linkPattern = regexp.MustCompile(`https?://\S+|www\.\S+`)
+	wordPattern = regexp.MustCompile(`\S+`)
+	spaces      = regexp.MustCompile(`\s+`)
+)
+
+// LinkDensity is the number of links per word in text
+func LinkDensity(text string) float64 {
+	words := len(wordPattern.FindAllString(text, -1))
+	if words == 0 {
+		return 0
+	}
+	return float64(len(linkPattern.FindAllString(text, -1))) / float64(words)
+}
+
+// normalize lowercases text and collapses its whitespace
+func normalize(c Content) string {
+	return strings.ToLower(spaces.ReplaceAllString(c.Text(), " "))
+}
+
+// Hash identifies content regardless of case and whitespace, so reposts of
+// the same text collide
+func Hash(c Content) string {
+	sum := sha256.Sum256([]byte(normalize(c)))
+	return hex.EncodeToString(sum[:])
+}
+
+// minDuplicateLength is the shortest normalized text checked for
+// duplicates. Short replies such as "thanks" or "+1" are repeated by many
+// people in good faith.
+const minDuplicateLength = 40
+
+// checksDuplicates reports whether repeats of c count against it
+func checksDuplicates(c Content) bool {
+	return utf8.RuneCountInString(normalize(c)) >= minDuplicateLength
+}
+
+// Evaluate scores content from its features and the classifier's spam
+// probability. Heuristic weights add up to a score that the classifier can
+// only raise: a probability of 0.5 or less carries no weight.
+func Evaluate(f Features, probability float64, cfg Config) Result {
+	var r Result
+	add := func(weight float64, reason string) {
+		r.Score += weight
+		r.Reasons = append(r.Reasons, reason)
+	}
+
+	switch {
+	case f.LinkDensity >= 0.5:
+		add(0.4, "mostly links")
+	case f.LinkDensity >= 0.2:
+		add(0.2, "link heavy")
+	}
+
+	switch {
+	case f.Duplicates >= 3:
+		add(0.5, "repeated content")
+	case f.Duplicates >= 1:
+		add(0.25, "duplicate content")
+	}
+
+	switch {
+	case f.AccountAge < 24*time.Hour:
+		add(0.2, "new account")
+	case f.AccountAge < 7*24*time.Hour:
+		add(0.05, "recent account")
+	}
+
+	if f.RecentPosts >= cfg.MaxPerHour {
+		add(0.3, "posting too fast")
+	}
+
+	if r.Score > 1 {
+		r.Score = 1
+	}
+	if evidence := 2*probability - 1; evidence > 0 {
+		r.Score = 1 - (1-r.Score)*(1-evidence)
+		r.Reasons = append(r.Reasons, fmt.Sprintf("classifier %.2f", probability))
+	}
+
+	r.Spam = r.Score >= cfg.Threshold
+	return r
+}
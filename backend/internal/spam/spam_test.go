@@ -0,0 +1,182 @@
+package spam
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+const established = 365 * 24 * time.Hour
+
+func TestLinkDensity(t *testing.T) {
+	cases := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"just some words here", 0},
+		{"see https://a.example and https://b.example", 0.5},
+		{"www.cheap.example", 1},
+	}
+	for _, tc := range cases {
+		if got := LinkDensity(tc.text); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("LinkDensity(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestHashIgnoresCaseAndWhitespace(t *testing.T) {
+	a := Hash(Content{Title: "Buy Now", Body: "great   deals\n\ntoday"})
+	b := Hash(Content{Title: "buy now", Body: "Great deals today"})
+	c := Hash(Content{Title: "buy now", Body: "great deals tomorrow"})
+
+	if a != b {
+		t.Error("hashes should match after normalizing case and whitespace")
+	}
+	if a == c {
+		t.Error("different content should hash differently")
+	}
+}
+
+func TestEvaluateHeuristics(t *testing.T) {
+	cfg := DefaultConfig()
+
+	normal := Evaluate(Features{LinkDensity: 0.05, AccountAge: established}, 0.5, cfg)
+	if normal.Spam || normal.Score != 0 {
+		t.Errorf("ordinary post scored %+v", normal)
+	}
+
+	spammy := Evaluate(Features{LinkDensity: 0.6, Duplicates: 4, AccountAge: time.Hour, RecentPosts: 20}, 0.5, cfg)
+	if !spammy.Spam || spammy.Score != 1 {
+		t.Errorf("link-dumping new account scored %+v", spammy)
+	}
+	if len(spammy.Reasons) != 4 {
+		t.Errorf("expected every heuristic to be reported, got %v", spammy.Reasons)
+	}
+
+	// One signal alone stays under the threshold
+	for _, f := range []Features{
+		{LinkDensity: 0.6, AccountAge: established},
+		{Duplicates: 5, AccountAge: established},
+		{AccountAge: time.Hour},
+		{RecentPosts: 50, AccountAge: established},
+	} {
+		if r := Evaluate(f, 0.5, cfg); r.Spam {
+			t.Errorf("%+v alone should not be spam, scored %v", f, r.Score)
+		}
+	}
+}
+
+func TestShortRepliesAreNotDuplicates(t *testing.T) {
+	for _, text := range []string{"thanks", "+1", "This!", "Thank you so much for sharing"} {
+		if checksDuplicates(Content{Body: text}) {
+			t.Errorf("%q is checked for duplicates", text)
+		}
+	}
+	if !checksDuplicates(Content{Body: "Claim your free crypto airdrop at https://pills.example"}) {
+		t.Error("a full sentence with a link is not checked for duplicates")
+	}
+
+	// A new user's first "thanks", which many others posted this week, must
+	// not be held: repeats of short text are never counted
+	f := Features{AccountAge: time.Hour}
+	if checksDuplicates(Content{Body: "thanks"}) {
+		f.Duplicates = 50
+	}
+	if r := Evaluate(f, 0.5, DefaultConfig()); r.Spam {
+		t.Errorf("new user's short reply scored %+v", r)
+	}
+}
+
+func TestEvaluateClassifierOnlyRaisesScore(t *testing.T) {
+	cfg := DefaultConfig()
+	f := Features{Duplicates: 1, AccountAge: established}
+
+	base := Evaluate(f, 0.5, cfg).Score
+	if low := Evaluate(f, 0.1, cfg).Score; low != base {
+		t.Errorf("a confident ham verdict should not lower the score: %v vs %v", low, base)
+	}
+	high := Evaluate(f, 0.95, cfg)
+	if !(high.Score > base) || !high.Spam {
+		t.Errorf("a confident spam verdict should push the score over the threshold, got %+v", high)
+	}
+}
+
+// train teaches a classifier a few obvious spam and ham documents
+func train(nb *NaiveBayes) {
+	spam := []string{
+		"cheap pills online buy now https://pills.example",
+		"buy cheap watches now limited offer https://deals.example",
+		"earn money fast crypto airdrop claim now",
+		"free crypto giveaway claim your airdrop https://pills.example",
+		"limited offer cheap pills discount buy",
+		"click here earn money from home fast",
+	}
+	ham := []string{
+		"what is everyone reading this week",
+		"my garden tomatoes finally ripened, photos inside",
+		"does anyone know a good hiking trail near the lake",
+		"the new season of the show was better than expected",
+		"tips for learning to play guitar as an adult",
+		"our cat learned to open the fridge door",
+	}
+	for _, text := range spam {
+		nb.Learn(text, true)
+	}
+	for _, text := range ham {
+		nb.Learn(text, false)
+	}
+}
+
+func TestNaiveBayes(t *testing.T) {
+	nb := NewNaiveBayes()
+	if p := nb.SpamProbability("cheap pills"); p != 0.5 {
+		t.Errorf("untrained classifier should have no opinion, got %v", p)
+	}
+
+	train(nb)
+
+	if p := nb.SpamProbability("buy cheap pills now at https://pills.example/offer"); p < 0.9 {
+		t.Errorf("spam probability too low: %v", p)
+	}
+	if p := nb.SpamProbability("any good hiking trail photos from the lake"); p > 0.1 {
+		t.Errorf("ham probability too high: %v", p)
+	}
+	if p := nb.SpamProbability("zzz qqq"); p != 0.5 {
+		t.Errorf("unknown words should leave the prior, got %v", p)
+	}
+}
+
+func TestNaiveBayesRoundTrip(t *testing.T) {
+	nb := NewNaiveBayes()
+	train(nb)
+
+	data, err := json.Marshal(nb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewNaiveBayes()
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, text := range []string{"cheap pills now", "our cat and the guitar"} {
+		if a, b := nb.SpamProbability(text), restored.SpamProbability(text); a != b {
+			t.Errorf("%q: %v before saving, %v after", text, a, b)
+		}
+	}
+}
+
+func TestTokensIncludeDomains(t *testing.T) {
+	got := tokens("Visit https://www.Pills.example/buy?x=1 NOW now")
+	want := map[string]bool{"domain:pills.example": true, "visit": true, "now": true}
+	if len(got) != len(want) {
+		t.Fatalf("tokens = %v", got)
+	}
+	for _, tok := range got {
+		if !want[tok] {
+			t.Errorf("unexpected token %q", tok)
+		}
+	}
+}
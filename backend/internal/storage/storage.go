@@ -0,0 +1,49 @@
+// Package storage abstracts where uploaded files are kept.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Storage persists uploaded files under backend-generated keys
+type Storage interface {
+	// Put stores the contents of r under key
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+
+	// Delete removes the file stored under key
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL of the file stored under key
+	URL(key string) string
+}
+
+// NewFromEnv builds the storage backend selected by STORAGE_DRIVER: "local"
+// (the default) or "s3" for any S3-compatible service
+func NewFromEnv() (Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("MEDIA_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		baseURL := os.Getenv("MEDIA_BASE_URL")
+		if baseURL == "" {
+			baseURL = "/media"
+		}
+		return NewLocal(dir, baseURL)
+	case "s3":
+		return NewS3(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_ACCESS_KEY_ID"),
+			os.Getenv("S3_SECRET_ACCESS_KEY"),
+			os.Getenv("MEDIA_BASE_URL"),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %q", driver)
+	}
+}
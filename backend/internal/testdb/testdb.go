@@ -0,0 +1,96 @@
+// Package testdb gives tests a migrated Postgres database: the one at
+// TEST_DATABASE_URL if set, or otherwise one running in a container. Tests
+// using it are skipped when neither is available. Every test empties the
+// tables, so run packages one at a time (go test -p 1) against a shared
+// TEST_DATABASE_URL.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/database"
+)
+
+var (
+	once    sync.Once
+	shared  *gorm.DB
+	initErr error
+)
+
+// start connects to TEST_DATABASE_URL, or runs one container for the whole
+// test binary; the testcontainers reaper removes it when the binary exits
+func start() (*gorm.DB, error) {
+	if dsn := os.Getenv("TEST_DATABASE_URL"); dsn != "" {
+		return open(dsn)
+	}
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase("test"),
+		postgres.WithUsername("test"),
+		postgres.WithPassword("test"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dsn)
+}
+
+func open(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(gormpostgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	return db, database.Migrate(db)
+}
+
+// New returns the test database with every table emptied
+func New(t *testing.T) *gorm.DB {
+	t.Helper()
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		testcontainers.SkipIfProviderIsNotHealthy(t)
+	}
+
+	once.Do(func() { shared, initErr = start() })
+	if initErr != nil {
+		t.Fatalf("could not start postgres: %v", initErr)
+	}
+
+	var tables []string
+	if err := shared.Raw("SELECT tablename FROM pg_tables WHERE schemaname = 'public'").Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) > 0 {
+		quoted := make([]string, len(tables))
+		for i, table := range tables {
+			quoted[i] = fmt.Sprintf("%q", table)
+		}
+		if err := shared.Exec("TRUNCATE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return shared
+}
@@ -0,0 +1,188 @@
+// Package trending aggregates community activity into summary rows so that
+// trending lists never scan posts and comments on request.
+package trending
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+
+	// maxGrowth caps growth so a community going from 1 to 40 posts doesn't
+	// outrank one that is steadily busy
+	maxGrowth = 5.0
+)
+
+// Growth is the relative change from previous to current activity. A
+// community with no previous activity counts its current activity as growth.
+func Growth(current, previous int) float64 {
+	if previous == 0 {
+		return math.Min(float64(current), maxGrowth)
+	}
+	return math.Min(float64(current-previous)/float64(previous), maxGrowth)
+}
+
+// Score ranks trending communities: recent activity on a log scale, boosted
+// by short- and long-term growth. Shrinking communities score lower but
+// never below zero.
+func Score(activity24h int, growth24h, growth7d float64) float64 {
+	if activity24h == 0 {
+		return 0
+	}
+	boost := 1 + 0.7*growth24h + 0.3*growth7d
+	return math.Max(0, math.Log10(1+float64(activity24h))*boost)
+}
+
+// Aggregator periodically recomputes models.CommunityStats
+type Aggregator struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+func NewAggregator(db *gorm.DB, interval time.Duration) *Aggregator {
+	return &Aggregator{db: db, interval: interval}
+}
+
+// Start aggregates immediately and then on every interval until ctx is
+// cancelled
+func (a *Aggregator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			if err := a.Run(time.Now()); err != nil {
+				log.Printf("trending aggregation failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+type count struct {
+	CommunityID int
+	Count       int
+}
+
+func (a *Aggregator) postCounts(from, to time.Time) (map[int]int, error) {
+	var rows []count
+	err := a.db.Model(&models.Post{}).
+		Select("community_id, COUNT(*) AS count").
+		Where("community_id <> 0 AND created_at > ? AND created_at <= ?", from, to).
+		Group("community_id").Scan(&rows).Error
+	return toMap(rows), err
+}
+
+func (a *Aggregator) commentCounts(from, to time.Time) (map[int]int, error) {
+	var rows []count
+	err := a.db.Model(&models.Comment{}).
+		Select("posts.community_id AS community_id, COUNT(*) AS count").
+		Joins("JOIN posts ON posts.id = comments.post_id").
+		Where("posts.community_id <> 0 AND comments.created_at > ? AND comments.created_at <= ?", from, to).
+		Group("posts.community_id").Scan(&rows).Error
+	return toMap(rows), err
+}
+
+func toMap(rows []count) map[int]int {
+	m := make(map[int]int, len(rows))
+	for _, r := range rows {
+		m[r.CommunityID] = r.Count
+	}
+	return m
+}
+
+// activity counts posts and comments per community in (from, to], and
+// their sum
+func (a *Aggregator) activity(from, to time.Time) (posts, comments, total map[int]int, err error) {
+	if posts, err = a.postCounts(from, to); err != nil {
+		return nil, nil, nil, err
+	}
+	if comments, err = a.commentCounts(from, to); err != nil {
+		return nil, nil, nil, err
+	}
+	total = make(map[int]int, len(posts)+len(comments))
+	for id, n := range posts {
+		total[id] += n
+	}
+	for id, n := range comments {
+		total[id] += n
+	}
+	return posts, comments, total, nil
+}
+
+// Run computes the stats of every community as of now
+func (a *Aggregator) Run(now time.Time) error {
+	var communityIDs []int
+	if err := a.db.Model(&models.Community{}).Pluck("id", &communityIDs).Error; err != nil {
+		return err
+	}
+	if len(communityIDs) == 0 {
+		return nil
+	}
+
+	posts24h, comments24h, last24h, err := a.activity(now.Add(-day), now)
+	if err != nil {
+		return err
+	}
+	_, _, prev24h, err := a.activity(now.Add(-2*day), now.Add(-day))
+	if err != nil {
+		return err
+	}
+	_, _, last7d, err := a.activity(now.Add(-week), now)
+	if err != nil {
+		return err
+	}
+	_, _, prev7d, err := a.activity(now.Add(-2*week), now.Add(-week))
+	if err != nil {
+		return err
+	}
+
+	var memberRows []count
+	err = a.db.Model(&models.CommunityMember{}).
+		Select("community_id, COUNT(*) AS count").
+		Group("community_id").Scan(&memberRows).Error
+	if err != nil {
+		return err
+	}
+	members := toMap(memberRows)
+
+	stats := make([]models.CommunityStats, 0, len(communityIDs))
+	for _, id := range communityIDs {
+		s := models.CommunityStats{
+			CommunityID:     id,
+			Members:         members[id],
+			Posts24h:        posts24h[id],
+			Comments24h:     comments24h[id],
+			Activity24h:     last24h[id],
+			ActivityPrev24h: prev24h[id],
+			Activity7d:      last7d[id],
+			ActivityPrev7d:  prev7d[id],
+			ComputedAt:      now,
+		}
+		s.Growth24h = Growth(s.Activity24h, s.ActivityPrev24h)
+		s.Growth7d = Growth(s.Activity7d, s.ActivityPrev7d)
+		s.TrendScore = Score(s.Activity24h, s.Growth24h, s.Growth7d)
+		stats = append(stats, s)
+	}
+
+	return a.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "community_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"members", "posts24h", "comments24h", "activity24h", "activity_prev24h",
+			"activity7d", "activity_prev7d", "growth24h", "growth7d", "trend_score", "computed_at",
+		}),
+	}).CreateInBatches(stats, 200).Error
+}
@@ -0,0 +1,39 @@
+package trending
+
+import "testing"
+
+func TestGrowth(t *testing.T) {
+	cases := []struct {
+		current, previous int
+		want              float64
+	}{
+		{10, 10, 0},
+		{20, 10, 1},
+		{5, 10, -0.5},
+		{3, 0, 3},
+		{100, 0, maxGrowth},
+		{1000, 1, maxGrowth},
+		{0, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := Growth(tc.current, tc.previous); got != tc.want {
+			t.Errorf("Growth(%d, %d) = %v, want %v", tc.current, tc.previous, got, tc.want)
+		}
+	}
+}
+
+func TestScoreFavorsGrowth(t *testing.T) {
+	steady := Score(100, 0, 0)
+	growing := Score(100, 1, 0.5)
+	shrinking := Score(100, -0.5, -0.2)
+
+	if !(growing > steady && steady > shrinking) {
+		t.Fatalf("expected growing > steady > shrinking, got %v, %v, %v", growing, steady, shrinking)
+	}
+	if Score(0, maxGrowth, maxGrowth) != 0 {
+		t.Error("inactive community should not trend")
+	}
+	if Score(10, -1, -1) < 0 {
+		t.Error("score should never be negative")
+	}
+}
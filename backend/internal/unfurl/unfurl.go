@@ -0,0 +1,303 @@
+// Package unfurl fetches web pages and extracts Open Graph and Twitter card
+// metadata for link previews.
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultTimeout   = 5 * time.Second
+	defaultMaxBytes  = 1 << 20 // 1 MB of HTML is plenty for <head>
+	maxRedirects     = 5
+	maxPreviewLength = 500
+)
+
+var ErrForbiddenAddress = errors.New("refusing to connect to a private address")
+
+// Preview is the metadata extracted from a page
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	SiteName    string `json:"site_name"`
+}
+
+// Fetcher downloads pages with SSRF protection, a timeout and a size cap
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// Option configures a Fetcher
+type Option func(*fetcherConfig)
+
+type fetcherConfig struct {
+	timeout      time.Duration
+	maxBytes     int64
+	allowPrivate bool
+}
+
+// WithTimeout sets the overall request timeout
+func WithTimeout(d time.Duration) Option {
+	return func(c *fetcherConfig) { c.timeout = d }
+}
+
+// WithMaxBytes caps how much of the response body is read
+func WithMaxBytes(n int64) Option {
+	return func(c *fetcherConfig) { c.maxBytes = n }
+}
+
+// AllowPrivateAddresses disables SSRF protection. Only for tests against a
+// local server.
+func AllowPrivateAddresses() Option {
+	return func(c *fetcherConfig) { c.allowPrivate = true }
+}
+
+func NewFetcher(opts ...Option) *Fetcher {
+	cfg := fetcherConfig{timeout: defaultTimeout, maxBytes: defaultMaxBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.timeout}
+	if !cfg.allowPrivate {
+		dialer = PublicDialer(cfg.timeout)
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.timeout,
+		ResponseHeaderTimeout: cfg.timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &Fetcher{
+		maxBytes: cfg.maxBytes,
+		client: &http.Client{
+			Timeout:   cfg.timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("unsupported redirect scheme: %s", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// PublicDialer returns a dialer that refuses loopback, private and other
+// internal addresses, for requests to user-supplied URLs
+func PublicDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		// Checked after DNS resolution, so rebinding tricks cannot bypass it
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+}
+
+// specialNetworks are the IANA special-purpose ranges that net.IP has no
+// method for: shared, benchmarking, documentation and reserved space, and
+// IPv6 prefixes that embed or translate to IPv4 addresses
+var specialNetworks = mustParseCIDRs(
+	"0.0.0.0/8",       // this network
+	"100.64.0.0/10",   // carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved, and broadcast
+	"64:ff9b::/96",    // NAT64
+	"64:ff9b:1::/48",  // local NAT64
+	"100::/64",        // discard only
+	"2001::/23",       // IETF protocol assignments, including Teredo
+	"2001:db8::/32",   // documentation
+	"2002::/16",       // 6to4
+	"fec0::/10",       // site local
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range specialNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetch downloads rawURL and extracts its preview metadata
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL: %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "RedditCloneBot/1.0 (+link preview)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type: %q", mediaType)
+	}
+
+	preview, err := Parse(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	preview.URL = rawURL
+	return preview, nil
+}
+
+// Parse extracts preview metadata from an HTML document. Relative image
+// URLs are resolved against base. Open Graph tags win over Twitter cards,
+// which win over <title> and the description meta tag.
+func Parse(r io.Reader, base *url.URL) (*Preview, error) {
+	meta := make(map[string]string)
+	var title string
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) || len(meta) > 0 || title != "" {
+				return buildPreview(meta, title, base), nil
+			}
+			return nil, z.Err()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "meta":
+				var key, content string
+				for _, attr := range tok.Attr {
+					switch strings.ToLower(attr.Key) {
+					case "property", "name":
+						key = strings.ToLower(attr.Val)
+					case "content":
+						content = attr.Val
+					}
+				}
+				if key != "" && content != "" {
+					if _, seen := meta[key]; !seen {
+						meta[key] = content
+					}
+				}
+			case "title":
+				inTitle = title == ""
+			case "body":
+				// Metadata lives in <head>; stop before reading the page body
+				return buildPreview(meta, title, base), nil
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title = string(z.Text())
+				inTitle = false
+			}
+
+		case html.EndTagToken:
+			if tok := z.Token(); tok.Data == "head" {
+				return buildPreview(meta, title, base), nil
+			}
+		}
+	}
+}
+
+func buildPreview(meta map[string]string, title string, base *url.URL) *Preview {
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := strings.TrimSpace(meta[k]); v != "" {
+				return truncate(v)
+			}
+		}
+		return ""
+	}
+
+	p := &Preview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		Image:       first("og:image", "og:image:url", "twitter:image", "twitter:image:src"),
+		SiteName:    first("og:site_name", "application-name"),
+	}
+	if p.Title == "" {
+		p.Title = truncate(strings.TrimSpace(title))
+	}
+	if p.Image != "" && base != nil {
+		if ref, err := url.Parse(p.Image); err == nil {
+			p.Image = base.ResolveReference(ref).String()
+		}
+	}
+	if p.SiteName == "" && base != nil {
+		p.SiteName = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+	return p
+}
+
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxPreviewLength {
+		return string(r[:maxPreviewLength])
+	}
+	return s
+}
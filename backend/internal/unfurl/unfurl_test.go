@@ -0,0 +1,175 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const page = `<!doctype html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Open Graph title">
+<meta name="twitter:title" content="Twitter title">
+<meta name="twitter:description" content="A twitter description">
+<meta property="og:image" content="/images/cover.png">
+<meta property="og:site_name" content="Example Site">
+</head><body><p>ignored</p></body></html>`
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func htmlHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}
+}
+
+func TestFetchExtractsMetadata(t *testing.T) {
+	srv := newServer(t, htmlHandler(page))
+
+	preview, err := NewFetcher(AllowPrivateAddresses()).Fetch(context.Background(), srv.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+
+	if preview.Title != "Open Graph title" {
+		t.Errorf("expected og:title to win, got %q", preview.Title)
+	}
+	if preview.Description != "A twitter description" {
+		t.Errorf("expected twitter description fallback, got %q", preview.Description)
+	}
+	if preview.Image != srv.URL+"/images/cover.png" {
+		t.Errorf("expected resolved image URL, got %q", preview.Image)
+	}
+	if preview.SiteName != "Example Site" {
+		t.Errorf("expected site name, got %q", preview.SiteName)
+	}
+}
+
+func TestFetchFallsBackToTitleTag(t *testing.T) {
+	srv := newServer(t, htmlHandler(`<html><head><title>Plain page</title></head></html>`))
+
+	preview, err := NewFetcher(AllowPrivateAddresses()).Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if preview.Title != "Plain page" {
+		t.Fatalf("expected <title> fallback, got %q", preview.Title)
+	}
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	srv := newServer(t, htmlHandler(page))
+
+	_, err := NewFetcher().Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress, got %v", err)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"192.0.0.8", false},
+		{"192.0.2.1", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"198.20.0.1", true},
+		{"198.51.100.7", false},
+		{"203.0.113.9", false},
+		{"240.0.0.1", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"::", false},
+		{"::ffff:10.0.0.1", false},
+		{"::ffff:100.64.0.1", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"fec0::1", false},
+		{"64:ff9b::a00:1", false},
+		{"2001::1", false},
+		{"2001:db8::1", false},
+		{"2002:a00:1::", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("binary"))
+	})
+
+	if _, err := NewFetcher(AllowPrivateAddresses()).Fetch(context.Background(), srv.URL); err == nil {
+		t.Fatal("expected error for non-HTML content")
+	}
+}
+
+func TestFetchCapsBodySize(t *testing.T) {
+	// The og:title sits beyond the size cap and must not be read
+	body := "<html><head><title>Early</title>" + strings.Repeat(" ", 4096) +
+		`<meta property="og:title" content="Too late"></head></html>`
+	srv := newServer(t, htmlHandler(body))
+
+	preview, err := NewFetcher(AllowPrivateAddresses(), WithMaxBytes(1024)).Fetch(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch returned error: %v", err)
+	}
+	if preview.Title != "Early" {
+		t.Fatalf("expected only the capped prefix to be parsed, got %q", preview.Title)
+	}
+}
+
+func TestFetchTimesOut(t *testing.T) {
+	srv := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	_, err := NewFetcher(AllowPrivateAddresses(), WithTimeout(50*time.Millisecond)).Fetch(context.Background(), srv.URL)
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
+
+func TestFirstURL(t *testing.T) {
+	got := FirstURL("check this out: https://example.com/a?b=c. neat")
+	if got != "https://example.com/a?b=c" {
+		t.Fatalf("unexpected URL: %q", got)
+	}
+	if FirstURL("no links here") != "" {
+		t.Fatal("expected no URL")
+	}
+}
@@ -0,0 +1,98 @@
+package unfurl
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+const (
+	queueSize = 256
+	// cacheTTL is how long a fetched preview is reused before refetching
+	cacheTTL = 24 * time.Hour
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+
+// FirstURL returns the first http(s) URL in text, or "" if there is none
+func FirstURL(text string) string {
+	return strings.TrimRight(urlPattern.FindString(text), ".,;:!?")
+}
+
+// Worker fetches previews in the background so that creating a post never
+// waits on a remote site
+type Worker struct {
+	db      *gorm.DB
+	fetcher *Fetcher
+	queue   chan string
+}
+
+// NewWorker starts n background goroutines that fetch queued URLs until ctx
+// is cancelled
+func NewWorker(ctx context.Context, db *gorm.DB, fetcher *Fetcher, n int) *Worker {
+	w := &Worker{db: db, fetcher: fetcher, queue: make(chan string, queueSize)}
+	for i := 0; i < n; i++ {
+		go w.run(ctx)
+	}
+	return w
+}
+
+// Enqueue schedules a URL for unfurling. It never blocks; when the queue is
+// full the URL is dropped and will be retried the next time it is posted.
+func (w *Worker) Enqueue(rawURL string) {
+	if w == nil || rawURL == "" {
+		return
+	}
+	select {
+	case w.queue <- rawURL:
+	default:
+		log.Printf("link preview queue full, dropping %s", rawURL)
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rawURL := <-w.queue:
+			w.process(ctx, rawURL)
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, rawURL string) {
+	var cached models.LinkPreview
+	err := w.db.Where("url = ? AND status <> ? AND fetched_at > ?", rawURL, models.PreviewPending, time.Now().Add(-cacheTTL)).
+		Limit(1).Find(&cached).Error
+	if err == nil && cached.ID != 0 {
+		return
+	}
+
+	record := models.LinkPreview{URL: rawURL, Status: models.PreviewOK, FetchedAt: time.Now()}
+	preview, err := w.fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		record.Status = models.PreviewFailed
+		record.Error = err.Error()
+	} else {
+		record.Title = preview.Title
+		record.Description = preview.Description
+		record.Image = preview.Image
+		record.SiteName = preview.SiteName
+	}
+
+	err = w.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "description", "image", "site_name", "status", "error", "fetched_at"}),
+	}).Create(&record).Error
+	if err != nil {
+		log.Printf("failed to save link preview for %s: %v", rawURL, err)
+	}
+}
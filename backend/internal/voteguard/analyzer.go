@@ -0,0 +1,219 @@
+package voteguard
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/karma"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+)
+
+// Analyzer periodically flags manipulated votes
+type Analyzer struct {
+	db  *gorm.DB
+	cfg Config
+}
+
+func NewAnalyzer(db *gorm.DB, cfg Config) *Analyzer {
+	return &Analyzer{db: db, cfg: cfg}
+}
+
+// Start analyzes immediately and then on every interval until ctx is
+// cancelled
+func (a *Analyzer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			if n, err := a.Run(time.Now()); err != nil {
+				log.Printf("vote analysis failed: %v", err)
+			} else if n > 0 {
+				log.Printf("vote analysis flagged %d votes", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// voteRow is a vote joined with its voter and the content it was cast on
+type voteRow struct {
+	ID             int
+	VoterID        int
+	PostID         int
+	CommentID      int
+	VoteType       int
+	IP             string
+	Fingerprint    string
+	CreatedAt      time.Time
+	FlaggedAt      *time.Time
+	VoterCreatedAt time.Time
+	AuthorID       int
+	CommunityID    int
+}
+
+func (r voteRow) target() karma.Target {
+	t := karma.Target{Kind: karma.Post, AuthorID: r.AuthorID, CommunityID: r.CommunityID}
+	if r.CommentID != 0 {
+		t.Kind = karma.Comment
+	}
+	return t
+}
+
+func (r voteRow) vote() Vote {
+	target := fmt.Sprintf("post:%d", r.PostID)
+	if r.CommentID != 0 {
+		target = fmt.Sprintf("comment:%d", r.CommentID)
+	}
+	return Vote{
+		ID:             r.ID,
+		VoterID:        r.VoterID,
+		AuthorID:       r.AuthorID,
+		Target:         target,
+		Type:           r.VoteType,
+		IP:             r.IP,
+		Fingerprint:    r.Fingerprint,
+		CreatedAt:      r.CreatedAt,
+		VoterCreatedAt: r.VoterCreatedAt,
+	}
+}
+
+// loadVotes fetches votes with their voter's age and the author and
+// community of the voted content
+func loadVotes(db *gorm.DB) *gorm.DB {
+	return db.Table("votes").
+		Select(`votes.id, votes.user_id AS voter_id, votes.post_id, votes.comment_id, votes.vote_type,
+			votes.ip, votes.fingerprint, votes.created_at, votes.flagged_at, voters.created_at AS voter_created_at,
+			COALESCE(posts.user_id, comments.author_id) AS author_id,
+			COALESCE(posts.community_id, comment_posts.community_id, 0) AS community_id`).
+		Joins("JOIN users AS voters ON voters.id = votes.user_id").
+		Joins("LEFT JOIN posts ON votes.post_id <> 0 AND posts.id = votes.post_id").
+		Joins("LEFT JOIN comments ON votes.comment_id <> 0 AND comments.id = votes.comment_id").
+		Joins("LEFT JOIN posts AS comment_posts ON comment_posts.id = comments.post_id")
+}
+
+// Run analyzes the votes cast within the lookback window and flags new
+// offenders, removing their votes from the authors' karma. Already flagged
+// votes still count as evidence. It returns the number of votes flagged.
+func (a *Analyzer) Run(now time.Time) (int, error) {
+	var rows []voteRow
+	err := loadVotes(a.db).
+		Where("votes.created_at > ? AND votes.flag_reviewed = ?", now.Add(-a.cfg.Lookback), false).
+		Scan(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	byID := make(map[int]voteRow, len(rows))
+	votes := make([]Vote, 0, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+		votes = append(votes, r.vote())
+	}
+
+	flagged := 0
+	for _, f := range Analyze(votes, a.cfg) {
+		row := byID[f.VoteID]
+		if row.FlaggedAt != nil {
+			continue
+		}
+		err := a.db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&models.Vote{}).
+				Where("id = ? AND flagged_at IS NULL AND flag_reviewed = ?", row.ID, false).
+				Updates(map[string]interface{}{"flagged_at": now, "flag_reason": string(f.Reason)})
+			if res.Error != nil || res.RowsAffected == 0 {
+				return res.Error
+			}
+			t := row.target()
+			return karma.Apply(tx, t, karma.Delta(t, row.VoterID, row.VoteType, 0))
+		})
+		if err != nil {
+			return flagged, err
+		}
+		flagged++
+	}
+	return flagged, nil
+}
+
+// ReportEntry summarizes one account's unreviewed flagged votes for a reason
+type ReportEntry struct {
+	VoterID          int       `json:"voter_id"`
+	Username         string    `json:"username"`
+	AccountCreatedAt time.Time `json:"account_created_at"`
+	Reason           Reason    `json:"reason"`
+	Votes            int       `json:"votes"`
+	Authors          int       `json:"authors"`
+	FirstVote        time.Time `json:"first_vote"`
+	LastVote         time.Time `json:"last_vote"`
+}
+
+// Report lists accounts with flagged votes awaiting review, most flagged first
+func Report(db *gorm.DB, limit, offset int) ([]ReportEntry, error) {
+	entries := []ReportEntry{}
+	err := db.Table("votes").
+		Select(`votes.user_id AS voter_id, users.username, users.created_at AS account_created_at,
+			votes.flag_reason AS reason, COUNT(*) AS votes,
+			COUNT(DISTINCT COALESCE(posts.user_id, comments.author_id)) AS authors,
+			MIN(votes.created_at) AS first_vote, MAX(votes.created_at) AS last_vote`).
+		Joins("JOIN users ON users.id = votes.user_id").
+		Joins("LEFT JOIN posts ON votes.post_id <> 0 AND posts.id = votes.post_id").
+		Joins("LEFT JOIN comments ON votes.comment_id <> 0 AND comments.id = votes.comment_id").
+		Where("votes.flagged_at IS NOT NULL AND votes.flag_reviewed = ?", false).
+		Group("votes.user_id, users.username, users.created_at, votes.flag_reason").
+		Order("votes DESC, voter_id ASC").
+		Limit(limit).Offset(offset).
+		Scan(&entries).Error
+	return entries, err
+}
+
+// Review settles an account's flagged votes. Restoring them counts them in
+// scores and karma again; otherwise they stay discounted. Either way they
+// leave the report and are never flagged again. It returns the number of
+// votes reviewed.
+func Review(db *gorm.DB, voterID int, restore bool) (int, error) {
+	reviewed := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rows []voteRow
+		err := loadVotes(tx).
+			Where("votes.user_id = ? AND votes.flagged_at IS NOT NULL AND votes.flag_reviewed = ?", voterID, false).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]int, len(rows))
+		for i, r := range rows {
+			ids[i] = r.ID
+		}
+		updates := map[string]interface{}{"flag_reviewed": true}
+		if restore {
+			updates["flagged_at"] = nil
+			updates["flag_reason"] = ""
+		}
+		if err := tx.Model(&models.Vote{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if restore {
+			for _, r := range rows {
+				t := r.target()
+				if err := karma.Apply(tx, t, karma.Delta(t, r.VoterID, 0, r.VoteType)); err != nil {
+					return err
+				}
+			}
+		}
+		reviewed = len(rows)
+		return nil
+	})
+	return reviewed, err
+}
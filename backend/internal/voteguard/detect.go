@@ -0,0 +1,284 @@
+// Package voteguard looks for vote manipulation: rings of accounts that vote
+// together, new accounts brigading one author, and bursts of accounts voting
+// from the same network or device. Flagged votes stay in place but are left
+// out of scores and karma until a moderator reviews them.
+package voteguard
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Reason explains why a vote was flagged
+type Reason string
+
+const (
+	ReasonRing        Reason = "vote_ring"
+	ReasonNewAccount  Reason = "new_account_brigade"
+	ReasonIPBurst     Reason = "shared_ip_burst"
+	ReasonDeviceBurst Reason = "shared_device_burst"
+)
+
+// Vote is one vote as seen by the detectors
+type Vote struct {
+	ID      int
+	VoterID int
+	// AuthorID is the author of the voted post or comment
+	AuthorID int
+	// Target identifies the voted post or comment, e.g. "post:12"
+	Target         string
+	Type           int
+	IP             string
+	Fingerprint    string
+	CreatedAt      time.Time
+	VoterCreatedAt time.Time
+}
+
+// Flag marks a vote as manipulated
+type Flag struct {
+	VoteID int
+	Reason Reason
+}
+
+// Config holds the detection thresholds
+type Config struct {
+	// Two accounts form a ring when they cast the same vote on at least
+	// RingMinShared targets and those make up at least RingMinOverlap of
+	// everything either of them voted on
+	RingMinShared  int
+	RingMinOverlap float64
+
+	// An account younger than NewAccountAge is brigading when it votes on
+	// more than NewAccountMaxVotes of one author's posts and comments
+	NewAccountAge      time.Duration
+	NewAccountMaxVotes int
+
+	// A burst is at least BurstMinAccounts accounts voting on one author
+	// from the same IP or device within BurstWindow
+	BurstWindow      time.Duration
+	BurstMinAccounts int
+
+	// Interval is how often the analyzer runs and Lookback how far back it
+	// looks
+	Interval time.Duration
+	Lookback time.Duration
+}
+
+// DefaultConfig returns the thresholds used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		RingMinShared:      5,
+		RingMinOverlap:     0.8,
+		NewAccountAge:      72 * time.Hour,
+		NewAccountMaxVotes: 5,
+		BurstWindow:        10 * time.Minute,
+		BurstMinAccounts:   3,
+		Interval:           15 * time.Minute,
+		Lookback:           7 * 24 * time.Hour,
+	}
+}
+
+// ConfigFromEnv overrides the defaults with VOTEGUARD_* environment
+// variables
+func ConfigFromEnv() (Config, error) {
+	return configFrom(os.Getenv)
+}
+
+func configFrom(getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+
+	ints := map[string]*int{
+		"VOTEGUARD_RING_MIN_SHARED":       &cfg.RingMinShared,
+		"VOTEGUARD_NEW_ACCOUNT_MAX_VOTES": &cfg.NewAccountMaxVotes,
+		"VOTEGUARD_BURST_MIN_ACCOUNTS":    &cfg.BurstMinAccounts,
+	}
+	for name, field := range ints {
+		if v := getenv(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return cfg, fmt.Errorf("%s must be a positive integer", name)
+			}
+			*field = n
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"VOTEGUARD_NEW_ACCOUNT_AGE": &cfg.NewAccountAge,
+		"VOTEGUARD_BURST_WINDOW":    &cfg.BurstWindow,
+		"VOTEGUARD_INTERVAL":        &cfg.Interval,
+		"VOTEGUARD_LOOKBACK":        &cfg.Lookback,
+	}
+	for name, field := range durations {
+		if v := getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return cfg, fmt.Errorf("%s must be a positive duration", name)
+			}
+			*field = d
+		}
+	}
+
+	if v := getenv("VOTEGUARD_RING_MIN_OVERLAP"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return cfg, fmt.Errorf("VOTEGUARD_RING_MIN_OVERLAP must be between 0 and 1")
+		}
+		cfg.RingMinOverlap = f
+	}
+	return cfg, nil
+}
+
+// Analyze runs every detector over the votes. Each flagged vote appears once,
+// with the first reason found in the order ring, new account, IP, device.
+// Flags are sorted by vote ID.
+func Analyze(votes []Vote, cfg Config) []Flag {
+	reasons := make(map[int]Reason)
+	mark := func(ids []int, reason Reason) {
+		for _, id := range ids {
+			if _, ok := reasons[id]; !ok {
+				reasons[id] = reason
+			}
+		}
+	}
+
+	mark(rings(votes, cfg), ReasonRing)
+	mark(newAccounts(votes, cfg), ReasonNewAccount)
+	mark(bursts(votes, cfg, func(v Vote) string { return v.IP }), ReasonIPBurst)
+	mark(bursts(votes, cfg, func(v Vote) string { return v.Fingerprint }), ReasonDeviceBurst)
+
+	flags := make([]Flag, 0, len(reasons))
+	for id, reason := range reasons {
+		flags = append(flags, Flag{VoteID: id, Reason: reason})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].VoteID < flags[j].VoteID })
+	return flags
+}
+
+type pair struct{ a, b int }
+
+func orderedPair(a, b int) pair {
+	if a > b {
+		a, b = b, a
+	}
+	return pair{a, b}
+}
+
+// rings finds pairs of accounts whose votes overlap almost entirely and
+// returns the votes they agreed on
+func rings(votes []Vote, cfg Config) []int {
+	// key is a target and direction, so opposing votes never count as agreement
+	type key struct {
+		target string
+		typ    int
+	}
+	voters := make(map[key][]Vote)
+	total := make(map[int]int)
+	for _, v := range votes {
+		k := key{v.Target, v.Type}
+		voters[k] = append(voters[k], v)
+		total[v.VoterID]++
+	}
+
+	shared := make(map[pair]int)
+	for _, vs := range voters {
+		for i := range vs {
+			for j := i + 1; j < len(vs); j++ {
+				if vs[i].VoterID != vs[j].VoterID {
+					shared[orderedPair(vs[i].VoterID, vs[j].VoterID)]++
+				}
+			}
+		}
+	}
+
+	ring := make(map[pair]bool)
+	for p, n := range shared {
+		overlap := float64(n) / float64(total[p.a]+total[p.b]-n)
+		if n >= cfg.RingMinShared && overlap >= cfg.RingMinOverlap {
+			ring[p] = true
+		}
+	}
+	if len(ring) == 0 {
+		return nil
+	}
+
+	var flagged []int
+	for _, vs := range voters {
+		for i := range vs {
+			for j := range vs {
+				if i != j && ring[orderedPair(vs[i].VoterID, vs[j].VoterID)] {
+					flagged = append(flagged, vs[i].ID)
+					break
+				}
+			}
+		}
+	}
+	return flagged
+}
+
+// newAccounts finds young accounts voting heavily on a single author
+func newAccounts(votes []Vote, cfg Config) []int {
+	type key struct{ voter, author int }
+	byAuthor := make(map[key][]int)
+	for _, v := range votes {
+		if v.CreatedAt.Sub(v.VoterCreatedAt) < cfg.NewAccountAge {
+			k := key{v.VoterID, v.AuthorID}
+			byAuthor[k] = append(byAuthor[k], v.ID)
+		}
+	}
+
+	var flagged []int
+	for _, ids := range byAuthor {
+		if len(ids) > cfg.NewAccountMaxVotes {
+			flagged = append(flagged, ids...)
+		}
+	}
+	return flagged
+}
+
+// bursts finds windows in which several accounts sharing a source (an IP or
+// device fingerprint) voted on the same author
+func bursts(votes []Vote, cfg Config, source func(Vote) string) []int {
+	type key struct {
+		source string
+		author int
+	}
+	groups := make(map[key][]Vote)
+	for _, v := range votes {
+		if s := source(v); s != "" {
+			k := key{s, v.AuthorID}
+			groups[k] = append(groups[k], v)
+		}
+	}
+
+	var flagged []int
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool { return group[i].CreatedAt.Before(group[j].CreatedAt) })
+
+		inBurst := make([]bool, len(group))
+		accounts := make(map[int]int)
+		start := 0
+		for end, v := range group {
+			accounts[v.VoterID]++
+			for v.CreatedAt.Sub(group[start].CreatedAt) > cfg.BurstWindow {
+				if accounts[group[start].VoterID]--; accounts[group[start].VoterID] == 0 {
+					delete(accounts, group[start].VoterID)
+				}
+				start++
+			}
+			if len(accounts) >= cfg.BurstMinAccounts {
+				for i := start; i <= end; i++ {
+					inBurst[i] = true
+				}
+			}
+		}
+		for i, v := range group {
+			if inBurst[i] {
+				flagged = append(flagged, v.ID)
+			}
+		}
+	}
+	return flagged
+}
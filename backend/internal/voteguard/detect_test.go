@@ -0,0 +1,274 @@
+package voteguard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var (
+	epoch = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	old   = epoch.Add(-365 * 24 * time.Hour)
+)
+
+// votes builds synthetic votes with sequential IDs
+type votes struct {
+	list []Vote
+}
+
+func (vs *votes) add(v Vote) int {
+	v.ID = len(vs.list) + 1
+	if v.Type == 0 {
+		v.Type = 1
+	}
+	if v.VoterCreatedAt.IsZero() {
+		v.VoterCreatedAt = old
+	}
+	vs.list = append(vs.list, v)
+	return v.ID
+}
+
+func target(n int) string { return fmt.Sprintf("post:%d", n) }
+
+func reasons(flags []Flag) map[int]Reason {
+	m := make(map[int]Reason, len(flags))
+	for _, f := range flags {
+		m[f.VoteID] = f.Reason
+	}
+	return m
+}
+
+// organic adds votes from accounts with unrelated histories, which no
+// detector should flag
+func organic(vs *votes) {
+	for voter := 100; voter < 120; voter++ {
+		for i := 0; i < 4; i++ {
+			vs.add(Vote{
+				VoterID:   voter,
+				AuthorID:  50 + (voter+i)%7,
+				Target:    target(1000 + voter*10 + i),
+				IP:        fmt.Sprintf("10.0.%d.%d", voter, i),
+				CreatedAt: epoch.Add(time.Duration(voter*i) * time.Minute),
+			})
+		}
+	}
+}
+
+func TestOrganicVotesAreNotFlagged(t *testing.T) {
+	var vs votes
+	organic(&vs)
+
+	if flags := Analyze(vs.list, DefaultConfig()); len(flags) != 0 {
+		t.Fatalf("expected no flags, got %v", flags)
+	}
+}
+
+func TestRingDetection(t *testing.T) {
+	cfg := DefaultConfig()
+	var vs votes
+	organic(&vs)
+
+	// Accounts 1 and 2 upvote the same six posts
+	var ring []int
+	for i := 0; i < 6; i++ {
+		for _, voter := range []int{1, 2} {
+			ring = append(ring, vs.add(Vote{
+				VoterID:   voter,
+				AuthorID:  9,
+				Target:    target(i),
+				CreatedAt: epoch.Add(time.Duration(i) * time.Hour),
+			}))
+		}
+	}
+	// Account 3 agrees on four of them but mostly votes elsewhere
+	for i := 0; i < 4; i++ {
+		vs.add(Vote{VoterID: 3, AuthorID: 9, Target: target(i), CreatedAt: epoch})
+	}
+	for i := 0; i < 10; i++ {
+		vs.add(Vote{VoterID: 3, AuthorID: 60 + i, Target: target(500 + i), CreatedAt: epoch})
+	}
+	// Account 4 votes on the same posts, but the other way
+	for i := 0; i < 6; i++ {
+		vs.add(Vote{VoterID: 4, AuthorID: 9, Target: target(i), Type: -1, CreatedAt: epoch})
+	}
+
+	got := reasons(Analyze(vs.list, cfg))
+	for _, id := range ring {
+		if got[id] != ReasonRing {
+			t.Errorf("vote %d: got %q, want %q", id, got[id], ReasonRing)
+		}
+	}
+	if len(got) != len(ring) {
+		t.Errorf("expected only the %d ring votes to be flagged, got %d", len(ring), len(got))
+	}
+
+	// Below the shared threshold nothing is a ring
+	cfg.RingMinShared = 7
+	if flags := Analyze(vs.list, cfg); len(flags) != 0 {
+		t.Errorf("expected no flags with RingMinShared=7, got %d", len(flags))
+	}
+}
+
+func TestNewAccountBrigade(t *testing.T) {
+	cfg := DefaultConfig()
+	var vs votes
+	organic(&vs)
+
+	// A day-old account downvotes six of author 7's posts
+	created := epoch.Add(-24 * time.Hour)
+	var brigade []int
+	for i := 0; i < 6; i++ {
+		brigade = append(brigade, vs.add(Vote{
+			VoterID:        5,
+			AuthorID:       7,
+			Target:         target(200 + i),
+			Type:           -1,
+			CreatedAt:      epoch.Add(time.Duration(i) * time.Minute),
+			VoterCreatedAt: created,
+		}))
+	}
+	// The same account voting on five other authors is fine
+	for i := 0; i < 5; i++ {
+		vs.add(Vote{VoterID: 5, AuthorID: 70 + i, Target: target(300 + i), CreatedAt: epoch, VoterCreatedAt: created})
+	}
+	// An established account voting on author 7 just as often is fine
+	for i := 0; i < 6; i++ {
+		vs.add(Vote{VoterID: 6, AuthorID: 7, Target: target(400 + i), CreatedAt: epoch})
+	}
+
+	got := reasons(Analyze(vs.list, cfg))
+	if len(got) != len(brigade) {
+		t.Fatalf("expected %d flags, got %d: %v", len(brigade), len(got), got)
+	}
+	for _, id := range brigade {
+		if got[id] != ReasonNewAccount {
+			t.Errorf("vote %d: got %q, want %q", id, got[id], ReasonNewAccount)
+		}
+	}
+
+	// Once the account is old enough the votes stand
+	cfg.NewAccountAge = time.Hour
+	if flags := Analyze(vs.list, cfg); len(flags) != 0 {
+		t.Errorf("expected no flags with NewAccountAge=1h, got %d", len(flags))
+	}
+}
+
+func TestIPAndDeviceBursts(t *testing.T) {
+	cfg := DefaultConfig()
+	var vs votes
+	organic(&vs)
+
+	// Three accounts on one IP upvote author 8 within five minutes
+	var ipBurst []int
+	for i, voter := range []int{20, 21, 22} {
+		ipBurst = append(ipBurst, vs.add(Vote{
+			VoterID:   voter,
+			AuthorID:  8,
+			Target:    target(600),
+			IP:        "203.0.113.7",
+			CreatedAt: epoch.Add(time.Duration(i*2) * time.Minute),
+		}))
+	}
+	// Three accounts on one IP, but spread over hours, are a shared network
+	for i, voter := range []int{23, 24, 25} {
+		vs.add(Vote{
+			VoterID:   voter,
+			AuthorID:  8,
+			Target:    target(601),
+			IP:        "198.51.100.1",
+			CreatedAt: epoch.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	// Three accounts on different IPs share a device
+	var deviceBurst []int
+	for i, voter := range []int{30, 31, 32} {
+		deviceBurst = append(deviceBurst, vs.add(Vote{
+			VoterID:     voter,
+			AuthorID:    8,
+			Target:      target(602),
+			IP:          fmt.Sprintf("192.0.2.%d", i),
+			Fingerprint: "device-abc",
+			CreatedAt:   epoch.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	// One account voting repeatedly from one IP is not a burst of accounts
+	for i := 0; i < 5; i++ {
+		vs.add(Vote{VoterID: 40, AuthorID: 8, Target: target(700 + i), IP: "203.0.113.99", CreatedAt: epoch})
+	}
+
+	got := reasons(Analyze(vs.list, cfg))
+	for _, id := range ipBurst {
+		if got[id] != ReasonIPBurst {
+			t.Errorf("vote %d: got %q, want %q", id, got[id], ReasonIPBurst)
+		}
+	}
+	for _, id := range deviceBurst {
+		if got[id] != ReasonDeviceBurst {
+			t.Errorf("vote %d: got %q, want %q", id, got[id], ReasonDeviceBurst)
+		}
+	}
+	if want := len(ipBurst) + len(deviceBurst); len(got) != want {
+		t.Errorf("expected %d flags, got %d: %v", want, len(got), got)
+	}
+
+	// A wider window catches the slow shared-network votes too
+	cfg.BurstWindow = 3 * time.Hour
+	if got := Analyze(vs.list, cfg); len(got) != len(ipBurst)+len(deviceBurst)+3 {
+		t.Errorf("expected slow burst to be flagged with a 3h window, got %d flags", len(got))
+	}
+}
+
+func TestAnalyzeIsDeterministic(t *testing.T) {
+	var vs votes
+	organic(&vs)
+	for i := 0; i < 6; i++ {
+		vs.add(Vote{VoterID: 1, AuthorID: 9, Target: target(i), CreatedAt: epoch})
+		vs.add(Vote{VoterID: 2, AuthorID: 9, Target: target(i), CreatedAt: epoch})
+	}
+
+	first := Analyze(vs.list, DefaultConfig())
+	for run := 0; run < 20; run++ {
+		again := Analyze(vs.list, DefaultConfig())
+		if fmt.Sprint(again) != fmt.Sprint(first) {
+			t.Fatalf("run %d differs:\n%v\n%v", run, again, first)
+		}
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i-1].VoteID >= first[i].VoteID {
+			t.Fatal("flags should be sorted by vote ID")
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"VOTEGUARD_RING_MIN_SHARED":  "8",
+		"VOTEGUARD_RING_MIN_OVERLAP": "0.5",
+		"VOTEGUARD_BURST_WINDOW":     "2m",
+	}
+	cfg, err := configFrom(func(k string) string { return env[k] })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RingMinShared != 8 || cfg.RingMinOverlap != 0.5 || cfg.BurstWindow != 2*time.Minute {
+		t.Errorf("overrides not applied: %+v", cfg)
+	}
+	if cfg.NewAccountMaxVotes != DefaultConfig().NewAccountMaxVotes {
+		t.Error("unset values should keep their defaults")
+	}
+
+	for name, value := range map[string]string{
+		"VOTEGUARD_RING_MIN_SHARED":  "0",
+		"VOTEGUARD_RING_MIN_OVERLAP": "1.5",
+		"VOTEGUARD_LOOKBACK":         "soon",
+	} {
+		if _, err := configFrom(func(k string) string {
+			if k == name {
+				return value
+			}
+			return ""
+		}); err == nil {
+			t.Errorf("%s=%s should be rejected", name, value)
+		}
+	}
+}
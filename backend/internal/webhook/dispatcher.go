@@ -0,0 +1,298 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/unfurl"
+)
+
+const (
+	// eventBuffer absorbs bursts of events while deliveries are queued
+	eventBuffer = 256
+	// pollInterval is how often the queue is checked for due retries
+	pollInterval = 5 * time.Second
+	// batchSize deliveries are claimed at once and sent concurrently
+	batchSize = 20
+	// maxErrorLength caps the error kept with a failed delivery
+	maxErrorLength = 500
+)
+
+// DataFunc loads the data of an event's payload. It returns nil for events
+// that should not be delivered, such as a post held for review.
+type DataFunc func(events.Event) (any, error)
+
+// Dispatcher queues deliveries for events and sends them
+type Dispatcher struct {
+	db     *gorm.DB
+	cfg    Config
+	data   DataFunc
+	client *http.Client
+	wake   chan struct{}
+}
+
+// Option configures a Dispatcher
+type Option func(*dispatcherOptions)
+
+type dispatcherOptions struct {
+	allowPrivate bool
+}
+
+// AllowPrivateAddresses lets webhooks point at internal addresses. Only for
+// tests against a local receiver.
+func AllowPrivateAddresses() Option {
+	return func(o *dispatcherOptions) { o.allowPrivate = true }
+}
+
+func NewDispatcher(db *gorm.DB, cfg Config, data DataFunc, opts ...Option) *Dispatcher {
+	var o dispatcherOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !o.allowPrivate {
+		dialer = unfurl.PublicDialer(cfg.Timeout)
+	}
+	client := &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, MaxIdleConnsPerHost: 4, IdleConnTimeout: time.Minute},
+		// A redirect is a failed delivery; receivers must be configured with
+		// their final URL
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &Dispatcher{db: db, cfg: cfg, data: data, client: client, wake: make(chan struct{}, 1)}
+}
+
+// Start queues deliveries for the events published on bus and sends due
+// deliveries until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context, bus *events.Bus) {
+	feed, unsubscribe := bus.Subscribe(eventBuffer)
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-feed:
+				if err := d.Enqueue(e); err != nil {
+					log.Printf("failed to queue webhooks for %s: %v", EventID(e), err)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			d.deliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Notify makes the dispatcher check for due deliveries now, e.g. after one
+// was scheduled for redelivery
+func (d *Dispatcher) Notify() {
+	if d == nil {
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Enqueue queues e for every active webhook subscribed to it: the webhooks of
+// its community and all app webhooks. An event already queued for a webhook
+// is not queued again.
+func (d *Dispatcher) Enqueue(e events.Event) error {
+	query := d.db.Where("active = ?", true)
+	if e.CommunityID > 0 {
+		query = query.Where("community_id IS NULL OR community_id = ?", e.CommunityID)
+	} else {
+		query = query.Where("community_id IS NULL")
+	}
+	var hooks []models.Webhook
+	if err := query.Find(&hooks).Error; err != nil {
+		return err
+	}
+	subscribed := hooks[:0]
+	for _, hook := range hooks {
+		if hook.Subscribes(e.Type) {
+			subscribed = append(subscribed, hook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	data, err := d.data(e)
+	if err != nil || data == nil {
+		return err
+	}
+	id := EventID(e)
+	body, err := json.Marshal(Payload{ID: id, Type: e.Type, CreatedAt: e.At, CommunityID: e.CommunityID, Data: data})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(subscribed))
+	for i, hook := range subscribed {
+		deliveries[i] = models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       id,
+			Event:         e.Type,
+			Payload:       string(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+	if err := d.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error; err != nil {
+		return err
+	}
+	d.Notify()
+	return nil
+}
+
+// deliverDue sends due deliveries, a batch at a time, until none are left
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := d.claim()
+		if err != nil {
+			log.Printf("failed to load webhook deliveries: %v", err)
+			return
+		}
+		if len(batch) == 0 {
+			return
+		}
+		var wg sync.WaitGroup
+		for _, delivery := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.process(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+// claim picks due deliveries and pushes their next attempt back, so that
+// other instances don't send them at the same time
+func (d *Dispatcher) claim() ([]models.WebhookDelivery, error) {
+	var batch []models.WebhookDelivery
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at").Limit(batchSize).Find(&batch).Error
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+		ids := make([]int, len(batch))
+		for i, delivery := range batch {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(2*d.cfg.Timeout)).Error
+	})
+	return batch, err
+}
+
+// process makes one attempt at a delivery and records the outcome
+func (d *Dispatcher) process(ctx context.Context, delivery models.WebhookDelivery) {
+	var hook models.Webhook
+	if d.db.First(&hook, delivery.WebhookID).Error != nil || !hook.Active {
+		// Deliveries queued before the webhook was disabled go straight to
+		// the dead-letter log
+		delivery.Status = models.DeliveryDead
+		delivery.LastError = "webhook is disabled"
+	} else {
+		code, err := d.send(ctx, hook, delivery)
+		if ctx.Err() != nil {
+			// Shutting down; the claim expires and the attempt is repeated
+			return
+		}
+		delivery = advance(d.cfg, delivery, code, err, time.Now())
+	}
+
+	d.db.Model(&delivery).Select("status", "attempts", "next_attempt_at", "response_code", "last_error", "delivered_at").Updates(&delivery)
+	if delivery.Status == models.DeliveryDead {
+		log.Printf("webhook %d: giving up on %s after %d attempts: %s", delivery.WebhookID, delivery.EventID, delivery.Attempts, delivery.LastError)
+	}
+}
+
+// send POSTs a delivery to its webhook and returns the response status. Any
+// status other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, hook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "reddit-clone-webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(IDHeader, delivery.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// advance records the outcome of an attempt: delivered, scheduled for a
+// retry after the backoff, or dead once the attempts are used up
+func advance(cfg Config, delivery models.WebhookDelivery, code int, err error, now time.Time) models.WebhookDelivery {
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	if err == nil {
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return delivery
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxErrorLength {
+		delivery.LastError = delivery.LastError[:maxErrorLength]
+	}
+	if delivery.Attempts >= cfg.MaxAttempts {
+		delivery.Status = models.DeliveryDead
+		return delivery
+	}
+	delivery.NextAttemptAt = now.Add(cfg.Backoff(delivery.Attempts))
+	return delivery
+}
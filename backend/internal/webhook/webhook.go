@@ -0,0 +1,175 @@
+// Package webhook notifies external URLs, such as chat relays and bots, of
+// events. Deliveries are queued in the database, signed with the webhook's
+// secret and retried with exponential backoff until the receiver accepts
+// them or they run out of attempts.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+)
+
+// Headers sent with every delivery
+const (
+	EventHeader = "X-Webhook-Event"
+	// IDHeader carries the event ID, the same for every retry
+	IDHeader        = "X-Webhook-ID"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Events lists the event types webhooks can subscribe to
+var Events = []string{
+	events.PostCreated,
+	events.CommentCreated,
+	events.VoteMilestone,
+	events.ReportFiled,
+	events.UserBanned,
+}
+
+// Known reports whether webhooks can subscribe to the event type
+func Known(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the JSON body of every delivery
+type Payload struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	CreatedAt   time.Time `json:"created_at"`
+	CommunityID int       `json:"community_id,omitempty"`
+	Data        any       `json:"data"`
+}
+
+// EventID identifies an event. It is derived from what the event is about,
+// so the same milestone reached twice is still one event.
+func EventID(e events.Event) string {
+	switch e.Type {
+	case events.PostCreated:
+		return fmt.Sprintf("%s:%d", e.Type, e.PostID)
+	case events.CommentCreated:
+		return fmt.Sprintf("%s:%d", e.Type, e.CommentID)
+	case events.VoteMilestone:
+		if e.CommentID != 0 {
+			return fmt.Sprintf("%s:comment:%d:%d", e.Type, e.CommentID, e.Milestone)
+		}
+		return fmt.Sprintf("%s:post:%d:%d", e.Type, e.PostID, e.Milestone)
+	case events.ReportFiled:
+		return fmt.Sprintf("%s:%d", e.Type, e.ReportID)
+	case events.UserBanned:
+		return fmt.Sprintf("%s:%d", e.Type, e.BanID)
+	}
+	return fmt.Sprintf("%s:%d", e.Type, e.At.UnixNano())
+}
+
+// NewSecret generates a signing secret for a new webhook
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of a body sent at timestamp (Unix
+// seconds): "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signature errors
+var (
+	ErrBadSignature = errors.New("webhook signature does not match")
+	ErrStale        = errors.New("webhook timestamp is too old")
+)
+
+// Verify checks the signature and timestamp headers of a delivery as a
+// receiver would, rejecting deliveries sent more than tolerance before now
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body))) {
+		return ErrBadSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrStale
+	}
+	return nil
+}
+
+// Config controls delivery
+type Config struct {
+	// MaxAttempts is how many times a delivery is tried before it is dead
+	MaxAttempts int
+	// RetryBase is the delay after the first failed attempt; it doubles
+	// after every further one, up to RetryMax
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// Timeout bounds each attempt
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 8,
+		RetryBase:   30 * time.Second,
+		RetryMax:    6 * time.Hour,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// ConfigFromEnv reads WEBHOOK_MAX_ATTEMPTS, WEBHOOK_RETRY_BASE and
+// WEBHOOK_TIMEOUT over the defaults
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be a positive integer")
+		}
+		cfg.MaxAttempts = n
+	}
+	if v := os.Getenv("WEBHOOK_RETRY_BASE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("WEBHOOK_RETRY_BASE must be a positive duration")
+		}
+		cfg.RetryBase = d
+	}
+	if v := os.Getenv("WEBHOOK_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("WEBHOOK_TIMEOUT must be a positive duration")
+		}
+		cfg.Timeout = d
+	}
+	return cfg, nil
+}
+
+// Backoff is the delay before retrying a delivery that failed attempts times
+func (c Config) Backoff(attempts int) time.Duration {
+	delay := c.RetryBase
+	for i := 1; i < attempts && delay < c.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.RetryMax)
+}
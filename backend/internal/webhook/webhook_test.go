@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emilythestrangee/reddit-clone/backend/internal/events"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/models"
+	"github.com/emilythestrangee/reddit-clone/backend/internal/unfurl"
+)
+
+const secret = "whsec_test"
+
+// receiver is a local webhook endpoint that checks signatures and answers
+// with the given statuses in turn, repeating the last one
+type receiver struct {
+	t        *testing.T
+	statuses []int
+
+	mu       sync.Mutex
+	received []*http.Request
+	bodies   []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	if err := Verify(secret, req.Header.Get(SignatureHeader), req.Header.Get(TimestampHeader), body, 5*time.Minute, time.Now()); err != nil {
+		r.t.Errorf("receiver: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, req)
+	r.bodies = append(r.bodies, string(body))
+	status := r.statuses[min(len(r.received), len(r.statuses))-1]
+	w.WriteHeader(status)
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, models.Webhook) {
+	r := &receiver{t: t, statuses: statuses}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, models.Webhook{ID: 1, URL: srv.URL, Secret: secret, Active: true}
+}
+
+func testDelivery() models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:        1,
+		WebhookID: 1,
+		EventID:   "post.created:7",
+		Event:     events.PostCreated,
+		Payload:   `{"id":"post.created:7","type":"post.created","data":{}}`,
+		Status:    models.DeliveryPending,
+	}
+}
+
+func TestRetriesUntilAccepted(t *testing.T) {
+	r, hook := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusNoContent)
+	cfg := DefaultConfig()
+	d := NewDispatcher(nil, cfg, nil, AllowPrivateAddresses())
+
+	delivery := testDelivery()
+	now := time.Now()
+	for attempt := 1; attempt <= 2; attempt++ {
+		code, err := d.send(context.Background(), hook, delivery)
+		delivery = advance(cfg, delivery, code, err, now)
+		if delivery.Status != models.DeliveryPending || delivery.Attempts != attempt || delivery.LastError == "" {
+			t.Fatalf("attempt %d: %+v", attempt, delivery)
+		}
+		if want := now.Add(cfg.Backoff(attempt)); !delivery.NextAttemptAt.Equal(want) {
+			t.Errorf("attempt %d: next attempt at %v, want %v", attempt, delivery.NextAttemptAt, want)
+		}
+	}
+
+	code, err := d.send(context.Background(), hook, delivery)
+	delivery = advance(cfg, delivery, code, err, now)
+	if delivery.Status != models.DeliveryDelivered || delivery.ResponseCode != http.StatusNoContent || delivery.DeliveredAt == nil || delivery.LastError != "" {
+		t.Fatalf("final attempt: %+v", delivery)
+	}
+
+	if len(r.received) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(r.received))
+	}
+	for i, req := range r.received {
+		if req.Header.Get(EventHeader) != events.PostCreated || req.Header.Get(IDHeader) != "post.created:7" {
+			t.Errorf("request %d headers: %v", i, req.Header)
+		}
+		if r.bodies[i] != delivery.Payload {
+			t.Errorf("request %d body %q", i, r.bodies[i])
+		}
+	}
+}
+
+func TestDeadAfterMaxAttempts(t *testing.T) {
+	r, hook := newReceiver(t, http.StatusServiceUnavailable)
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 3
+	d := NewDispatcher(nil, cfg, nil, AllowPrivateAddresses())
+
+	delivery := testDelivery()
+	for delivery.Status == models.DeliveryPending {
+		code, err := d.send(context.Background(), hook, delivery)
+		delivery = advance(cfg, delivery, code, err, time.Now())
+	}
+	if delivery.Status != models.DeliveryDead || delivery.Attempts != 3 || delivery.ResponseCode != http.StatusServiceUnavailable {
+		t.Errorf("got %+v", delivery)
+	}
+	if len(r.received) != 3 {
+		t.Errorf("receiver got %d requests, want 3", len(r.received))
+	}
+}
+
+func TestRedirectIsFailure(t *testing.T) {
+	_, target := newReceiver(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer redirect.Close()
+
+	d := NewDispatcher(nil, DefaultConfig(), nil, AllowPrivateAddresses())
+	code, err := d.send(context.Background(), models.Webhook{URL: redirect.URL, Secret: secret}, testDelivery())
+	if err == nil || code != http.StatusFound {
+		t.Errorf("got %d %v, want a failed 302", code, err)
+	}
+}
+
+func TestPrivateAddressesRefused(t *testing.T) {
+	r, hook := newReceiver(t, http.StatusOK)
+	d := NewDispatcher(nil, DefaultConfig(), nil)
+
+	_, err := d.send(context.Background(), hook, testDelivery())
+	if !errors.Is(err, unfurl.ErrForbiddenAddress) {
+		t.Errorf("got %v, want a forbidden address error", err)
+	}
+	if len(r.received) != 0 {
+		t.Error("receiver was reached")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"x"}`)
+	now := time.Unix(1_700_000_000, 0)
+	sig := Sign(secret, now.Unix(), body)
+	ts := "1700000000"
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      string
+		at        time.Time
+		want      error
+	}{
+		{"valid", secret, sig, ts, string(body), now, nil},
+		{"wrong secret", "other", sig, ts, string(body), now, ErrBadSignature},
+		{"tampered body", secret, sig, ts, `{"id":"y"}`, now, ErrBadSignature},
+		{"tampered timestamp", secret, sig, "1700000001", string(body), now, ErrBadSignature},
+		{"stale", secret, sig, ts, string(body), now.Add(10 * time.Minute), ErrStale},
+	}
+	for _, tt := range tests {
+		if err := Verify(tt.secret, tt.signature, tt.timestamp, []byte(tt.body), 5*time.Minute, tt.at); err != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{RetryBase: 30 * time.Second, RetryMax: 10 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, w := range want {
+		if got := cfg.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestEventID(t *testing.T) {
+	tests := []struct {
+		event events.Event
+		want  string
+	}{
+		{events.Event{Type: events.PostCreated, PostID: 3}, "post.created:3"},
+		{events.Event{Type: events.CommentCreated, PostID: 3, CommentID: 9}, "comment.created:9"},
+		{events.Event{Type: events.VoteMilestone, PostID: 3, Milestone: 100}, "vote.milestone:post:3:100"},
+		{events.Event{Type: events.VoteMilestone, PostID: 3, CommentID: 9, Milestone: 10}, "vote.milestone:comment:9:10"},
+		{events.Event{Type: events.ReportFiled, PostID: 3, ReportID: 4}, "report.filed:4"},
+		{events.Event{Type: events.UserBanned, UserID: 5, BanID: 6}, "user.banned:6"},
+	}
+	for _, tt := range tests {
+		if got := EventID(tt.event); got != tt.want {
+			t.Errorf("EventID(%+v) = %q, want %q", tt.event, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 // services/api.ts - Complete API service with interceptors and persistence
 import axios from 'axios';
 import AsyncStorage from '@react-native-async-storage/async-storage';
+import Constants from 'expo-constants';
 
-const API_URL = process.env.EXPO_PUBLIC_API_URL || 'https://backend-green-fog-6124-production.up.railway.app/api';
+const API_URL = process.env.EXPO_PUBLIC_API_URL || 'https://backend-green-fog-6124-production.up.railway.app/api/v1';
+
+// Sent with every request so the backend can ask stale builds to update
+const CLIENT_VERSION = Constants.expoConfig?.version ?? '0.0.0';
 
 class ApiService {
   private api: any;
@@ -13,6 +17,7 @@ class ApiService {
       timeout: 15000,
       headers: {
         'Content-Type': 'application/json',
+        'X-Client-Version': CLIENT_VERSION,
       },
     });
 
@@ -104,6 +109,32 @@ class ApiService {
 
 const apiService = new ApiService();
 
+// Shape of every error response from the backend
+export type ApiErrorCode =
+  | 'bad_request'
+  | 'validation_failed'
+  | 'unauthorized'
+  | 'forbidden'
+  | 'banned'
+  | 'not_found'
+  | 'conflict'
+  | 'rule_violation'
+  | 'payload_too_large'
+  | 'unsupported_media_type'
+  | 'upgrade_required'
+  | 'internal_error';
+
+export interface ApiErrorBody {
+  error: string;
+  code: ApiErrorCode;
+  details?: { field: string; message: string }[];
+  request_id?: string;
+}
+
+// Returns the error code of a failed request, if the backend sent one
+export const apiErrorCode = (error: any): ApiErrorCode | undefined =>
+  (error?.response?.data as ApiErrorBody | undefined)?.code;
+
 // Export user API helper
 export const userAPI = {
   getProfile: (userId: number) => apiService.get(`/users/${userId}`),
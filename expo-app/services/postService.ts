@@ -27,6 +27,15 @@ export interface CreatePostData {
   image?: string;
 }
 
+export interface UploadedMedia {
+  id: number;
+  url: string;
+  thumbnail_url: string;
+  content_type: string;
+  width: number;
+  height: number;
+}
+
 export interface UpdatePostData {
   title?: string;
   content?: string;
@@ -71,22 +80,23 @@ class PostService {
     try {
       console.log('📝 Creating post:', data.title);
       
-      let imageUrl = data.image;
-      
-      // Upload image to Cloudinary if provided
-      if (data.image && data.image.startsWith('file://')) {
-        console.log('📸 Uploading image to Cloudinary...');
-        imageUrl = await this.uploadImageToCloudinary(data.image);
-        console.log('✅ Image uploaded:', imageUrl);
-      }
-      
-      const postData = {
+      const postData: Record<string, unknown> = {
         title: data.title,
         content: data.content || data.body || '',
         body: data.content || data.body || '',
-        image: imageUrl || '', // Use Cloudinary URL
       };
 
+      // Upload local images through the backend and reference the media ID
+      if (data.image && data.image.startsWith('file://')) {
+        console.log('📸 Uploading image...');
+        const media = await this.uploadMedia(data.image);
+        console.log('✅ Image uploaded:', media.url);
+        postData.kind = 'gallery';
+        postData.media = [{ media_id: media.id }];
+      } else if (data.image) {
+        postData.image = data.image;
+      }
+
       const response = await api.post<Post>('/posts', postData);
       console.log('✅ Post created successfully:', response.data.id);
       return response.data;
@@ -96,31 +106,23 @@ class PostService {
     }
   }
 
-  private async uploadImageToCloudinary(imageUri: string): Promise<string> {
-    try {
-      const formData = new FormData();
-      formData.append('file', {
-        uri: imageUri,
-        type: 'image/gif', // or detect type
-        name: 'post-image.gif',
-      } as any);
-      formData.append('upload_preset', 'reddit_clone');
-      formData.append('cloud_name', 'dougqcma4');
-
-      const response = await fetch(
-        'https://api.cloudinary.com/v1_1/dougqcma4/image/upload',
-        {
-          method: 'POST',
-          body: formData,
-        }
-      );
-
-      const data = await response.json();
-      return data.secure_url;
-    } catch (error) {
-      console.error('Image upload failed, using local path:', error);
-      return imageUri; // Fallback to local
-    }
+  /**
+   * Upload an image to the backend media endpoint
+   */
+  async uploadMedia(imageUri: string): Promise<UploadedMedia> {
+    const name = imageUri.split('/').pop() || 'upload.jpg';
+    const formData = new FormData();
+    formData.append('file', {
+      uri: imageUri,
+      // The backend sniffs the real type, this only labels the part
+      type: 'application/octet-stream',
+      name,
+    } as any);
+
+    const response = await api.post<UploadedMedia>('/media', formData, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    });
+    return response.data;
   }
 
   /**